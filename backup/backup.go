@@ -0,0 +1,202 @@
+// Package backup takes and restores snapshots of the connectors defined on a
+// Kafka Connect cluster, for disaster recovery.
+//
+// A snapshot records each connector's name, config and state. It can be stored
+// as a directory of JSON files, one per connector plus a manifest, or as a
+// gzipped tarball containing the same files.
+package backup
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-kafka/connect"
+)
+
+// Connector states that are preserved in a snapshot. Other states reported by
+// the API, like FAILED or UNASSIGNED, are recorded as running since that is
+// what the connector would be restored to.
+const (
+	StateRunning = "RUNNING"
+	StatePaused  = "PAUSED"
+	StateStopped = "STOPPED"
+)
+
+// A Snapshot is a point-in-time copy of the connectors on a cluster.
+type Snapshot struct {
+	Manifest   Manifest
+	Connectors []Connector
+}
+
+// A Manifest describes the origin of a Snapshot.
+type Manifest struct {
+	ClusterID      string    `json:"kafka_cluster_id"`
+	ConnectVersion string    `json:"connect_version"`
+	Timestamp      time.Time `json:"timestamp"`
+	Connectors     []string  `json:"connectors"`
+}
+
+// A Connector is the saved definition of a single connector.
+type Connector struct {
+	Name   string                  `json:"name"`
+	Config connect.ConnectorConfig `json:"config"`
+	State  string                  `json:"state"`
+}
+
+// Take snapshots every connector on the cluster that client talks to.
+// Connectors deleted while the snapshot is being taken are omitted.
+func Take(client *connect.Client) (*Snapshot, error) {
+	info, _, err := client.GetServerInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	names, _, err := client.ListConnectors()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &Snapshot{
+		Manifest: Manifest{
+			ClusterID:      info.KafkaClusterID,
+			ConnectVersion: info.Version,
+			Timestamp:      time.Now().UTC(),
+			Connectors:     make([]string, 0, len(names)),
+		},
+	}
+
+	for _, name := range names {
+		config, response, err := client.GetConnectorConfig(name)
+		if isNotFound(response) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading config of %v: %v", name, err)
+		}
+		status, response, err := client.GetConnectorStatus(name)
+		if isNotFound(response) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading status of %v: %v", name, err)
+		}
+
+		snapshot.Manifest.Connectors = append(snapshot.Manifest.Connectors, name)
+		snapshot.Connectors = append(snapshot.Connectors, Connector{
+			Name:   name,
+			Config: config,
+			State:  savedState(status.Connector.State),
+		})
+	}
+
+	return snapshot, nil
+}
+
+func isNotFound(response *http.Response) bool {
+	return response != nil && response.StatusCode == http.StatusNotFound
+}
+
+func savedState(state string) string {
+	switch state {
+	case StatePaused, StateStopped:
+		return state
+	default:
+		return StateRunning
+	}
+}
+
+// A ConflictPolicy determines what Restore does with connectors in a snapshot
+// that already exist on the target cluster.
+type ConflictPolicy string
+
+// Supported conflict policies.
+const (
+	// FailOnConflict aborts the restore before making any changes.
+	FailOnConflict ConflictPolicy = "fail"
+	// SkipExisting leaves existing connectors as they are.
+	SkipExisting ConflictPolicy = "skip"
+	// OverwriteExisting replaces config and state of existing connectors.
+	OverwriteExisting ConflictPolicy = "overwrite"
+)
+
+// ParseConflictPolicy returns the ConflictPolicy with the given name.
+func ParseConflictPolicy(name string) (ConflictPolicy, error) {
+	switch policy := ConflictPolicy(strings.ToLower(name)); policy {
+	case FailOnConflict, SkipExisting, OverwriteExisting:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown conflict policy %q", name)
+}
+
+// Actions reported for each connector by Restore.
+const (
+	Created     = "created"
+	Overwritten = "overwritten"
+	Skipped     = "skipped"
+)
+
+// A Result reports what Restore did with one connector.
+type Result struct {
+	Name   string
+	Action string
+}
+
+// Restore recreates the connectors of snapshot on the cluster that client
+// talks to, handling those that already exist according to policy. Paused and
+// stopped connectors are returned to that state.
+//
+// Results are returned for the connectors restored before any error occurred.
+func Restore(client *connect.Client, snapshot *Snapshot, policy ConflictPolicy) ([]Result, error) {
+	names, _, err := client.ListConnectors()
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool, len(names))
+	for _, name := range names {
+		existing[name] = true
+	}
+
+	if policy == FailOnConflict {
+		var conflicts []string
+		for _, conn := range snapshot.Connectors {
+			if existing[conn.Name] {
+				conflicts = append(conflicts, conn.Name)
+			}
+		}
+		if len(conflicts) > 0 {
+			return nil, fmt.Errorf("connectors already exist: %v", strings.Join(conflicts, ", "))
+		}
+	}
+
+	var results []Result
+	for _, conn := range snapshot.Connectors {
+		if existing[conn.Name] && policy == SkipExisting {
+			results = append(results, Result{conn.Name, Skipped})
+			continue
+		}
+
+		target := connect.Connector{Name: conn.Name, Config: conn.Config}
+		created, _, err := client.UpsertConnector(&target)
+		if err != nil {
+			return results, fmt.Errorf("restoring %v: %v", conn.Name, err)
+		}
+
+		// A newly created connector starts running, but one we overwrote may
+		// have been paused or stopped and needs to be put right.
+		if !created || conn.State != StateRunning {
+			if _, err := client.SetConnectorState(conn.Name, conn.State); err != nil {
+				return results, fmt.Errorf("restoring state of %v: %v", conn.Name, err)
+			}
+		}
+
+		action := Overwritten
+		if created {
+			action = Created
+		}
+		results = append(results, Result{conn.Name, action})
+	}
+
+	return results, nil
+}
@@ -0,0 +1,183 @@
+package backup_test
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	"github.com/go-kafka/connect"
+	. "github.com/go-kafka/connect/backup"
+)
+
+var _ = Describe("Backup", func() {
+	var client *connect.Client
+	var server *ghttp.Server
+
+	sourceConfig := connect.ConnectorConfig{
+		"connector.class": "FileStreamSource",
+		"name":            "source",
+	}
+	sinkConfig := connect.ConnectorConfig{
+		"connector.class": "FileStreamSink",
+		"name":            "sink",
+	}
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		client = connect.NewClient(server.URL())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("Take", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/"),
+					ghttp.RespondWith(http.StatusOK, `{"version":"2.1.0","kafka_cluster_id":"cluster-1"}`),
+				),
+				ghttp.RespondWith(http.StatusOK, `["source","sink"]`),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/connectors/source/config"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, sourceConfig),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/connectors/source/status"),
+					ghttp.RespondWith(http.StatusOK, `{"name":"source","connector":{"state":"FAILED"}}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/connectors/sink/config"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, sinkConfig),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/connectors/sink/status"),
+					ghttp.RespondWith(http.StatusOK, `{"name":"sink","connector":{"state":"PAUSED"}}`),
+				),
+			)
+		})
+
+		It("records every connector with its config and state", func() {
+			snapshot, err := Take(client)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(snapshot.Manifest.ClusterID).To(Equal("cluster-1"))
+			Expect(snapshot.Manifest.Connectors).To(Equal([]string{"source", "sink"}))
+			Expect(snapshot.Connectors).To(Equal([]Connector{
+				{Name: "source", Config: sourceConfig, State: StateRunning},
+				{Name: "sink", Config: sinkConfig, State: StatePaused},
+			}))
+		})
+	})
+
+	Describe("Take while a connector is deleted", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `{"version":"2.1.0","kafka_cluster_id":"cluster-1"}`),
+				ghttp.RespondWith(http.StatusOK, `["source","gone","sink"]`),
+				ghttp.RespondWithJSONEncoded(http.StatusOK, sourceConfig),
+				ghttp.RespondWith(http.StatusOK, `{"name":"source","connector":{"state":"RUNNING"}}`),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/connectors/gone/config"),
+					ghttp.RespondWith(http.StatusNotFound, `{"error_code":404,"message":"Connector gone not found"}`),
+				),
+				ghttp.RespondWithJSONEncoded(http.StatusOK, sinkConfig),
+				ghttp.RespondWith(http.StatusOK, `{"name":"sink","connector":{"state":"RUNNING"}}`),
+			)
+		})
+
+		It("leaves it out", func() {
+			snapshot, err := Take(client)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(snapshot.Manifest.Connectors).To(Equal([]string{"source", "sink"}))
+			Expect(snapshot.Connectors).To(HaveLen(2))
+		})
+	})
+
+	Describe("Restore", func() {
+		var snapshot *Snapshot
+
+		BeforeEach(func() {
+			snapshot = &Snapshot{
+				Connectors: []Connector{
+					{Name: "source", Config: sourceConfig, State: StateRunning},
+					{Name: "sink", Config: sinkConfig, State: StatePaused},
+				},
+			}
+			server.AppendHandlers(ghttp.RespondWith(http.StatusOK, `["source"]`))
+		})
+
+		Context("with the fail policy", func() {
+			It("refuses to change anything", func() {
+				_, err := Restore(client, snapshot, FailOnConflict)
+				Expect(err).To(MatchError("connectors already exist: source"))
+				Expect(server.ReceivedRequests()).To(HaveLen(1))
+			})
+		})
+
+		Context("with the skip policy", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/connectors/sink/config"),
+						ghttp.VerifyJSONRepresenting(sinkConfig),
+						ghttp.RespondWith(http.StatusCreated, `{"name":"sink"}`),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/connectors/sink/pause"),
+						ghttp.RespondWith(http.StatusAccepted, nil),
+					),
+				)
+			})
+
+			It("creates missing connectors and restores paused state", func() {
+				results, err := Restore(client, snapshot, SkipExisting)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(results).To(Equal([]Result{{"source", Skipped}, {"sink", Created}}))
+				Expect(server.ReceivedRequests()).To(HaveLen(3))
+			})
+		})
+
+		Context("with the overwrite policy", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/connectors/source/config"),
+						ghttp.RespondWith(http.StatusOK, `{"name":"source"}`),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/connectors/source/resume"),
+						ghttp.RespondWith(http.StatusAccepted, nil),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/connectors/sink/config"),
+						ghttp.RespondWith(http.StatusCreated, `{"name":"sink"}`),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/connectors/sink/pause"),
+						ghttp.RespondWith(http.StatusAccepted, nil),
+					),
+				)
+			})
+
+			It("replaces existing connectors", func() {
+				results, err := Restore(client, snapshot, OverwriteExisting)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(results).To(Equal([]Result{{"source", Overwritten}, {"sink", Created}}))
+			})
+		})
+	})
+
+	Describe("ParseConflictPolicy", func() {
+		It("accepts known policies", func() {
+			Expect(ParseConflictPolicy("Overwrite")).To(Equal(OverwriteExisting))
+		})
+
+		It("rejects unknown policies", func() {
+			_, err := ParseConflictPolicy("merge")
+			Expect(err).To(MatchError(`unknown conflict policy "merge"`))
+		})
+	})
+})
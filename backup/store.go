@@ -0,0 +1,210 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	manifestFile  = "manifest.json"
+	connectorsDir = "connectors"
+)
+
+// IsArchive reports whether path names a gzipped tarball, judging by its
+// extension, rather than a directory.
+func IsArchive(path string) bool {
+	return strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz")
+}
+
+// Save writes snapshot to path, as an archive if IsArchive(path) or otherwise
+// as a directory.
+func Save(snapshot *Snapshot, path string) error {
+	if !IsArchive(path) {
+		return snapshot.WriteDir(path)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := snapshot.WriteArchive(file); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Load reads a snapshot from path, as an archive if IsArchive(path) or
+// otherwise as a directory.
+func Load(path string) (*Snapshot, error) {
+	if !IsArchive(path) {
+		return ReadDir(path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadArchive(file)
+}
+
+// Connector names may contain characters that aren't safe in file names.
+func connectorFile(name string) string {
+	return path.Join(connectorsDir, url.PathEscape(name)+".json")
+}
+
+// files returns the contents of snapshot as a mapping of relative paths to
+// JSON documents.
+func (s *Snapshot) files() (map[string][]byte, error) {
+	files := make(map[string][]byte, len(s.Connectors)+1)
+
+	manifest, err := json.MarshalIndent(s.Manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	files[manifestFile] = manifest
+
+	for _, conn := range s.Connectors {
+		data, err := json.MarshalIndent(conn, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		files[connectorFile(conn.Name)] = data
+	}
+
+	return files, nil
+}
+
+// fromFiles is the inverse of files, reading connectors in manifest order.
+func fromFiles(read func(name string) ([]byte, error)) (*Snapshot, error) {
+	snapshot := new(Snapshot)
+
+	data, err := read(manifestFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &snapshot.Manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %v", err)
+	}
+
+	for _, name := range snapshot.Manifest.Connectors {
+		data, err := read(connectorFile(name))
+		if err != nil {
+			return nil, err
+		}
+		var conn Connector
+		if err := json.Unmarshal(data, &conn); err != nil {
+			return nil, fmt.Errorf("invalid backup of connector %v: %v", name, err)
+		}
+		snapshot.Connectors = append(snapshot.Connectors, conn)
+	}
+
+	return snapshot, nil
+}
+
+// WriteDir writes snapshot as JSON files into dir, creating it if necessary.
+func (s *Snapshot) WriteDir(dir string) error {
+	files, err := s.files()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, connectorsDir), 0755); err != nil {
+		return err
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadDir reads a snapshot previously written with WriteDir.
+func ReadDir(dir string) (*Snapshot, error) {
+	return fromFiles(func(name string) ([]byte, error) {
+		return ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	})
+}
+
+// WriteArchive writes snapshot to w as a gzipped tarball.
+func (s *Snapshot) WriteArchive(w io.Writer) error {
+	files, err := s.files()
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(w)
+	archive := tar.NewWriter(gz)
+
+	// Manifest first, so it's cheap to inspect with `tar -xOzf ... manifest.json`
+	names := []string{manifestFile}
+	for _, conn := range s.Connectors {
+		names = append(names, connectorFile(conn.Name))
+	}
+
+	for _, name := range names {
+		header := &tar.Header{
+			Name:    name,
+			Mode:    0644,
+			Size:    int64(len(files[name])),
+			ModTime: s.Manifest.Timestamp,
+		}
+		if err := archive.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := archive.Write(files[name]); err != nil {
+			return err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// ReadArchive reads a snapshot previously written with WriteArchive.
+func ReadArchive(r io.Reader) (*Snapshot, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	files := make(map[string][]byte)
+	archive := tar.NewReader(gz)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if _, err := io.Copy(&buf, archive); err != nil {
+			return nil, err
+		}
+		files[path.Clean(header.Name)] = buf.Bytes()
+	}
+
+	return fromFiles(func(name string) ([]byte, error) {
+		data, ok := files[name]
+		if !ok {
+			return nil, fmt.Errorf("archive is missing %v", name)
+		}
+		return data, nil
+	})
+}
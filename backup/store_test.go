@@ -0,0 +1,60 @@
+package backup_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-kafka/connect"
+	. "github.com/go-kafka/connect/backup"
+)
+
+var _ = Describe("Snapshot storage", func() {
+	var snapshot *Snapshot
+	var tmpdir string
+
+	BeforeEach(func() {
+		snapshot = &Snapshot{
+			Manifest: Manifest{
+				ClusterID:  "cluster-1",
+				Timestamp:  time.Date(2016, 8, 11, 0, 0, 0, 0, time.UTC),
+				Connectors: []string{"source", "weird/name"},
+			},
+			Connectors: []Connector{
+				{Name: "source", Config: connect.ConnectorConfig{"tasks.max": "1"}, State: StateRunning},
+				{Name: "weird/name", Config: connect.ConnectorConfig{"tasks.max": "2"}, State: StateStopped},
+			},
+		}
+
+		var err error
+		tmpdir, err = ioutil.TempDir("", "connect-backup")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		_ = os.RemoveAll(tmpdir)
+	})
+
+	It("round-trips through a directory", func() {
+		dir := filepath.Join(tmpdir, "snapshot")
+		Expect(Save(snapshot, dir)).To(Succeed())
+		Expect(filepath.Join(dir, "manifest.json")).To(BeAnExistingFile())
+
+		loaded, err := Load(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded).To(Equal(snapshot))
+	})
+
+	It("round-trips through an archive", func() {
+		archive := filepath.Join(tmpdir, "snapshot.tar.gz")
+		Expect(Save(snapshot, archive)).To(Succeed())
+
+		loaded, err := Load(archive)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded).To(Equal(snapshot))
+	})
+})
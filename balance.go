@@ -0,0 +1,126 @@
+package connect
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// DefaultMaxSkew is the ratio of a worker's task count to the mean beyond which
+// Balance flags it, when no ratio is given.
+const DefaultMaxSkew = 1.5
+
+// WorkerBalance describes the load on one worker, including a breakdown of its
+// tasks by the simple class name of their connectors.
+type WorkerBalance struct {
+	WorkerLoad
+	TasksByClass map[string]int `json:"tasks_by_class"`
+
+	// Whether the worker has more than MaxSkew times the mean number of tasks,
+	// or less than the mean divided by MaxSkew.
+	Overloaded  bool `json:"overloaded"`
+	Underloaded bool `json:"underloaded"`
+}
+
+// A BalanceReport describes how evenly tasks are spread across workers.
+type BalanceReport struct {
+	Workers   []WorkerBalance `json:"workers"`
+	MeanTasks float64         `json:"mean_tasks"`
+	MaxSkew   float64         `json:"max_skew"`
+}
+
+// IsSkewed reports whether any worker is overloaded or underloaded.
+func (r *BalanceReport) IsSkewed() bool {
+	for _, w := range r.Workers {
+		if w.Overloaded || w.Underloaded {
+			return true
+		}
+	}
+	return false
+}
+
+// SuggestRestarts picks tasks on overloaded workers whose restart may let them
+// be assigned to a less loaded worker: enough to bring each worker down to the
+// mean, taken first from the connectors with the most tasks on that worker.
+// Restarts are not guaranteed to move tasks, so check the balance afterwards.
+func (r *BalanceReport) SuggestRestarts() []TaskID {
+	target := int(math.Ceil(r.MeanTasks))
+
+	var suggested []TaskID
+	for _, w := range r.Workers {
+		excess := len(w.Tasks) - target
+		if !w.Overloaded || excess <= 0 {
+			continue
+		}
+
+		perConnector := make(map[string]int)
+		for _, task := range w.Tasks {
+			perConnector[task.ConnectorName]++
+		}
+		tasks := append([]TaskID(nil), w.Tasks...)
+		sort.SliceStable(tasks, func(i, j int) bool {
+			return perConnector[tasks[i].ConnectorName] > perConnector[tasks[j].ConnectorName]
+		})
+		suggested = append(suggested, tasks[:excess]...)
+	}
+	return suggested
+}
+
+// Balance computes a BalanceReport from connector statuses and the connector
+// class of each connector by name. Workers that have nothing assigned don't
+// appear in statuses, so cannot be taken into account. If maxSkew is zero,
+// DefaultMaxSkew is used.
+func Balance(statuses []ConnectorStatus, classes map[string]string, maxSkew float64) *BalanceReport {
+	if maxSkew == 0 {
+		maxSkew = DefaultMaxSkew
+	}
+
+	report := &BalanceReport{MaxSkew: maxSkew}
+	loads := WorkerLoads(statuses)
+	total := 0
+	for _, load := range loads {
+		byClass := make(map[string]int)
+		for _, task := range load.Tasks {
+			class := classes[task.ConnectorName]
+			byClass[class[strings.LastIndex(class, ".")+1:]]++
+		}
+		report.Workers = append(report.Workers, WorkerBalance{WorkerLoad: load, TasksByClass: byClass})
+		total += len(load.Tasks)
+	}
+	if len(loads) == 0 {
+		return report
+	}
+
+	report.MeanTasks = float64(total) / float64(len(loads))
+	for i := range report.Workers {
+		w := &report.Workers[i]
+		n := float64(len(w.Tasks))
+		w.Overloaded = n > report.MeanTasks*maxSkew
+		w.Underloaded = n < report.MeanTasks/maxSkew
+	}
+	return report
+}
+
+// GetBalanceReport fetches the status and class of every connector and
+// computes a BalanceReport, as Balance does.
+func (c *Client) GetBalanceReport(maxSkew float64) (*BalanceReport, error) {
+	statuses, err := c.ListConnectorStatuses()
+	if err != nil {
+		return nil, err
+	}
+
+	classes := make(map[string]string, len(statuses))
+	for _, status := range statuses {
+		config, response, err := c.GetConnectorConfig(status.Name)
+		if response != nil && response.StatusCode == http.StatusNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		classes[status.Name] = config["connector.class"]
+	}
+
+	return Balance(statuses, classes, maxSkew), nil
+}
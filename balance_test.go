@@ -0,0 +1,57 @@
+package connect_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/go-kafka/connect"
+)
+
+var _ = Describe("Balance", func() {
+	tasks := func(worker string, n int) []TaskState {
+		var states []TaskState
+		for i := 0; i < n; i++ {
+			states = append(states, TaskState{ID: i, State: "RUNNING", WorkerID: worker})
+		}
+		return states
+	}
+
+	var report *BalanceReport
+
+	BeforeEach(func() {
+		statuses := []ConnectorStatus{
+			{Name: "orders", Connector: ConnectorState{WorkerID: "w1"}, Tasks: tasks("w1", 4)},
+			{Name: "payments", Connector: ConnectorState{WorkerID: "w2"}, Tasks: append(tasks("w1", 2), TaskState{ID: 2, WorkerID: "w2"})},
+			{Name: "refunds", Connector: ConnectorState{WorkerID: "w3"}, Tasks: tasks("w3", 2)},
+		}
+		classes := map[string]string{
+			"orders":   "io.confluent.connect.jdbc.JdbcSinkConnector",
+			"payments": "io.confluent.connect.s3.S3SinkConnector",
+			"refunds":  "io.confluent.connect.jdbc.JdbcSinkConnector",
+		}
+		report = Balance(statuses, classes, 0)
+	})
+
+	It("counts tasks per worker and class", func() {
+		Expect(report.MaxSkew).To(Equal(DefaultMaxSkew))
+		Expect(report.MeanTasks).To(Equal(3.0))
+		Expect(report.Workers).To(HaveLen(3))
+		Expect(report.Workers[0].WorkerID).To(Equal("w1"))
+		Expect(report.Workers[0].TasksByClass).To(Equal(map[string]int{"JdbcSinkConnector": 4, "S3SinkConnector": 2}))
+	})
+
+	It("flags workers beyond the skew ratio", func() {
+		Expect(report.IsSkewed()).To(BeTrue())
+		Expect(report.Workers[0].Overloaded).To(BeTrue())
+		Expect(report.Workers[1].Underloaded).To(BeTrue())
+		Expect(report.Workers[2].Overloaded || report.Workers[2].Underloaded).To(BeFalse())
+	})
+
+	It("suggests restarting excess tasks of the busiest connectors", func() {
+		Expect(report.SuggestRestarts()).To(Equal([]TaskID{
+			{ConnectorName: "orders", ID: 0},
+			{ConnectorName: "orders", ID: 1},
+			{ConnectorName: "orders", ID: 2},
+		}))
+	})
+})
@@ -68,6 +68,23 @@ func (c *Client) Host() string {
 	return c.host.String()
 }
 
+// ServerInfo holds version information about the Kafka Connect worker serving
+// API requests, and the Kafka cluster it is connected to.
+type ServerInfo struct {
+	Version        string `json:"version"`
+	Commit         string `json:"commit"`
+	KafkaClusterID string `json:"kafka_cluster_id"`
+}
+
+// GetServerInfo retrieves version information from the root of the API.
+//
+// See: https://docs.confluent.io/current/connect/references/restapi.html#get--
+func (c *Client) GetServerInfo() (*ServerInfo, *http.Response, error) {
+	info := new(ServerInfo)
+	response, err := c.get("", info)
+	return info, response, err
+}
+
 // NewRequest creates an API request. A relative URL can be provided in path,
 // in which case it is resolved relative to the BaseURL of the Client.
 // Relative URLs should always be specified without a preceding slash. If
@@ -1,8 +1,11 @@
 package connect_test
 
 import (
+	"net/http"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
 
 	. "github.com/go-kafka/connect"
 )
@@ -39,3 +42,33 @@ var _ = Describe("NewClient", func() {
 		})
 	})
 })
+
+var _ = Describe("GetServerInfo", func() {
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		client = NewClient(server.URL())
+
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/"),
+				ghttp.VerifyHeader(jsonAcceptHeader),
+				ghttp.RespondWith(http.StatusOK,
+					`{"version":"2.1.0","commit":"809be928f1ae004e","kafka_cluster_id":"kTvSAFzDRk2Lx6Cm-LCl5g"}`),
+			),
+		)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("returns worker and cluster info", func() {
+		info, _, err := client.GetServerInfo()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*info).To(Equal(ServerInfo{
+			Version:        "2.1.0",
+			Commit:         "809be928f1ae004e",
+			KafkaClusterID: "kTvSAFzDRk2Lx6Cm-LCl5g",
+		}))
+	})
+})
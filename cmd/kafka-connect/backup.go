@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/go-kafka/connect"
+	"github.com/go-kafka/connect/backup"
+)
+
+func backupConnectors(path string, client *connect.Client) error {
+	snapshot, err := backup.Take(client)
+	if err != nil {
+		return err
+	}
+	if err := backup.Save(snapshot, path); err != nil {
+		return err
+	}
+
+	fmt.Printf("Backed up %d connectors to %v.\n", len(snapshot.Connectors), path)
+	return nil
+}
+
+func restoreConnectors(path string, policy backup.ConflictPolicy, client *connect.Client) error {
+	snapshot, err := backup.Load(path)
+	if err != nil {
+		return err
+	}
+
+	results, err := backup.Restore(client, snapshot, policy)
+	for _, result := range results {
+		fmt.Printf("%v: %v\n", result.Name, result.Action)
+	}
+
+	return err
+}
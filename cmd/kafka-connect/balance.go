@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/go-kafka/connect"
+)
+
+var (
+	balanceCmd *kingpin.CmdClause
+
+	maxSkew          float64
+	rebalanceRestart bool
+)
+
+func configureBalanceCommand(app *kingpin.Application) {
+	balanceCmd = app.Command("balance", "Reports how evenly tasks are spread across workers.")
+	balanceCmd.Flag("max-skew", "Flag workers with more than this many times the mean number of tasks, or less than the mean divided by it.").
+		Default(strconv.FormatFloat(connect.DefaultMaxSkew, 'f', -1, 64)).
+		Float64Var(&maxSkew)
+	balanceCmd.Flag("restart", "Restart excess tasks on overloaded workers, so that they may be assigned elsewhere.").
+		BoolVar(&rebalanceRestart)
+	balanceCmd.Flag("dry-run", "With --restart, show which tasks would be restarted without restarting them.").
+		BoolVar(&dryRun)
+
+	// Reset state for in-process tests
+	maxSkew, rebalanceRestart = 0, false
+}
+
+func showBalance(client *connect.Client) error {
+	report, err := client.GetBalanceReport(maxSkew)
+	if err != nil {
+		return err
+	}
+	suggested := report.SuggestRestarts()
+
+	if outputSpec != "" {
+		if err := printOutput(report); err != nil {
+			return err
+		}
+	} else {
+		if err := writeTable(os.Stdout, balanceRows(report)); err != nil {
+			return err
+		}
+		fmt.Printf("\nMean %.1f tasks per worker", report.MeanTasks)
+		if !report.IsSkewed() {
+			fmt.Println(", within the allowed skew.")
+		} else {
+			fmt.Printf(", some workers are beyond %v times that.\n", report.MaxSkew)
+		}
+	}
+
+	if len(suggested) == 0 {
+		return nil
+	}
+	if !rebalanceRestart || dryRun {
+		fmt.Fprintln(os.Stderr, "Restarting these tasks may even out the load, try --restart:")
+		for _, task := range suggested {
+			fmt.Fprintf(os.Stderr, "  %v/%d\n", task.ConnectorName, task.ID)
+		}
+		return nil
+	}
+
+	if err := confirmDestructive(fmt.Sprintf("restart %d tasks", len(suggested))); err != nil {
+		return err
+	}
+	var failed int
+	for _, task := range suggested {
+		if _, err := client.RestartTask(task.ConnectorName, task.ID); err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "Failed to restart task %v/%d: %v\n", task.ConnectorName, task.ID, err)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "Restarted task %v/%d.\n", task.ConnectorName, task.ID)
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to restart %d of %d tasks", failed, len(suggested))
+	}
+	return nil
+}
+
+func balanceRows(report *connect.BalanceReport) [][]string {
+	rows := [][]string{{"WORKER", "CONNECTORS", "TASKS", "BY CLASS", "SKEW"}}
+	for _, w := range report.Workers {
+		classes := make([]string, 0, len(w.TasksByClass))
+		for class := range w.TasksByClass {
+			classes = append(classes, class)
+		}
+		sort.Strings(classes)
+		for i, class := range classes {
+			classes[i] = fmt.Sprintf("%v=%d", class, w.TasksByClass[class])
+		}
+
+		skew := "-"
+		if w.Overloaded {
+			skew = "overloaded"
+		} else if w.Underloaded {
+			skew = "underloaded"
+		}
+
+		rows = append(rows, []string{
+			w.WorkerID,
+			strconv.Itoa(len(w.Connectors)),
+			strconv.Itoa(len(w.Tasks)),
+			strings.Join(classes, ", "),
+			skew,
+		})
+	}
+	return rows
+}
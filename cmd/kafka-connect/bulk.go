@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/go-kafka/connect"
+)
+
+var (
+	selectPatterns  []string
+	selectRegexp    *regexp.Regexp
+	selectClass     string
+	selectConfig    map[string]string
+	selectStates    []string
+	bulkConcurrency int
+	bulkConfirmOver int
+)
+
+// Adds connector selection to commands that act on connectors by name, so that
+// they can act on many at once.
+func configureSelectors(commands ...*kingpin.CmdClause) {
+	for _, command := range commands {
+		addSelectorArgs(command)
+		command.Flag("concurrency", "How many connectors to act on at once.").
+			Default("4").
+			IntVar(&bulkConcurrency)
+		command.Flag("confirm-over", "Ask for confirmation when more than this many connectors are selected, unless given --yes.").
+			Default("5").
+			IntVar(&bulkConfirmOver)
+		command.Flag("dry-run", "List the selected connectors without acting on them.").
+			BoolVar(&dryRun)
+	}
+
+	// Reset state for in-process tests
+	selectPatterns, selectRegexp, selectClass = nil, nil, ""
+	selectConfig, selectStates = map[string]string{}, nil
+	bulkConcurrency, bulkConfirmOver = 0, 0
+}
+
+func addSelectorArgs(command *kingpin.CmdClause) {
+	command.Arg("name", fmt.Sprintf("Names or glob patterns of connectors to %v.", command.FullCommand())).
+		StringsVar(&selectPatterns)
+	command.Flag("regex", "Select connectors whose name matches a regular expression.").
+		PlaceHolder("REGEX").
+		RegexpVar(&selectRegexp)
+	command.Flag("class", "Select connectors by connector.class, fully qualified or just the class name.").
+		StringVar(&selectClass)
+	command.Flag("where", "Select connectors with a config value. Repeatable.").
+		PlaceHolder("KEY=VALUE").
+		StringMapVar(&selectConfig)
+	command.Flag("state", "Select connectors that are, or have a task, in a state such as FAILED. Repeatable.").
+		StringsVar(&selectStates)
+}
+
+func connectorSelector() connect.Selector {
+	return connect.Selector{
+		Names:  selectPatterns,
+		Regexp: selectRegexp,
+		Class:  selectClass,
+		Config: selectConfig,
+		States: selectStates,
+	}
+}
+
+// Returns the connector name if exactly one was given with no other selection
+// criteria, in which case the command acts on it directly as it always has.
+func singleConnector() (string, bool) {
+	sel := connectorSelector()
+	if len(sel.Names) != 1 || strings.ContainsAny(sel.Names[0], `*?[\`) {
+		return "", false
+	}
+	sel.Names = nil
+	return selectPatterns[0], sel.IsEmpty()
+}
+
+// Performs an action on the selected connectors, e.g. verb "pause" with desc
+// "Paused", printing a line per connector and a summary.
+func bulkAction(verb, desc string, action connectorAction, client *connect.Client) error {
+	if name, ok := singleConnector(); ok {
+		if dryRun {
+			fmt.Fprintf(os.Stderr, "Would %v connector %v.\n", verb, name)
+			return nil
+		}
+		if verb == "delete" {
+			if err := confirmDestructive("delete connector " + name); err != nil {
+				return err
+			}
+		}
+		return affectConnector(name, action, desc)
+	}
+
+	names, err := client.SelectConnectors(connectorSelector())
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		fmt.Fprintln(os.Stderr, "No connectors selected.")
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "Selected %d connectors to %v:\n", len(names), verb)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %v\n", name)
+	}
+	if dryRun {
+		return nil
+	}
+
+	if len(names) > bulkConfirmOver {
+		if err := askConfirmation(fmt.Sprintf("Really %v %d connectors?", verb, len(names))); err != nil {
+			return err
+		}
+	} else if verb == "delete" {
+		if err := confirmDestructive(fmt.Sprintf("delete %d connectors", len(names))); err != nil {
+			return err
+		}
+	}
+
+	failed := 0
+	for _, result := range connect.ForEachConnector(names, bulkConcurrency, action) {
+		if result.Err != nil {
+			failed++
+			fmt.Printf("%v: failed: %v\n", result.Name, result.Err)
+		} else {
+			fmt.Printf("%v: %v\n", result.Name, strings.ToLower(desc))
+		}
+	}
+	fmt.Printf("%d %v, %d failed.\n", len(names)-failed, strings.ToLower(desc), failed)
+
+	if failed > 0 {
+		return fmt.Errorf("failed to %v %d of %d connectors", verb, failed, len(names))
+	}
+	return nil
+}
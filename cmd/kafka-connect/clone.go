@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/go-kafka/connect"
+)
+
+var (
+	cloneCmd, renameCmd *kingpin.CmdClause
+
+	newConnName      string
+	overrides        map[string]string
+	skipOffsets      bool
+	stateWaitTimeout time.Duration
+)
+
+func configureCloneCommands(app *kingpin.Application) {
+	cloneCmd = app.Command("clone", "Creates a copy of a connector under a new name, without its offsets.")
+	cloneCmd.Arg("name", "Name of the connector to copy.").Required().StringVar(&connName)
+	cloneCmd.Arg("new-name", "Name of the new connector.").Required().StringVar(&newConnName)
+
+	renameCmd = app.Command("rename", "Moves a connector to a new name, keeping its offsets where the worker allows.")
+	renameCmd.Arg("name", "Name of the connector to rename.").Required().StringVar(&connName)
+	renameCmd.Arg("new-name", "New name of the connector.").Required().StringVar(&newConnName)
+	renameCmd.Flag("no-offsets", "Don't copy offsets, for workers before Kafka 3.7 that can't create stopped connectors.").
+		BoolVar(&skipOffsets)
+	renameCmd.Flag("timeout", "How long to wait for each state change before rolling back.").
+		Default(connect.DefaultRenameTimeout.String()).
+		DurationVar(&stateWaitTimeout)
+
+	for _, command := range []*kingpin.CmdClause{cloneCmd, renameCmd} {
+		command.Flag("set", "Set a config value on the new connector. Repeatable.").
+			PlaceHolder("KEY=VALUE").
+			StringMapVar(&overrides)
+	}
+
+	// Reset state for in-process tests
+	newConnName, overrides, skipOffsets, stateWaitTimeout = "", map[string]string{}, false, 0
+}
+
+func cloneConnector(client *connect.Client) error {
+	connector, _, err := client.CloneConnector(connName, newConnName, overrides)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Cloned connector %v to %v.\n", connName, newConnName)
+	return printOutput(connector)
+}
+
+func renameConnector(client *connect.Client) error {
+	if err := confirmDestructive(fmt.Sprintf("rename connector %v to %v", connName, newConnName)); err != nil {
+		return err
+	}
+
+	result, err := client.RenameConnector(connName, newConnName, connect.RenameOptions{
+		Config:      overrides,
+		SkipOffsets: skipOffsets,
+		Timeout:     stateWaitTimeout,
+	})
+	if err != nil {
+		return fmt.Errorf("renaming %v to %v failed: %v", connName, newConnName, err)
+	}
+
+	if !result.OffsetsCopied {
+		fmt.Fprintf(os.Stderr, "Offsets were not copied, %v starts from scratch.\n", newConnName)
+	}
+	fmt.Printf("Renamed connector %v to %v.\n", connName, newConnName)
+	return nil
+}
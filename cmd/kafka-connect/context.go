@@ -0,0 +1,455 @@
+package main
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+	"gopkg.in/yaml.v2"
+
+	"github.com/go-kafka/connect"
+)
+
+const (
+	configenv  = "KAFKA_CONNECT_CLI_CONFIG"
+	contextenv = "KAFKA_CONNECT_CLI_CONTEXT"
+)
+
+// cliConfig is the contents of the CLI config file, which defines named
+// contexts for the Connect clusters a user works with.
+type cliConfig struct {
+	CurrentContext string                     `yaml:"current-context,omitempty"`
+	Contexts       map[string]*clusterContext `yaml:"contexts"`
+}
+
+// A clusterContext holds everything needed to talk to one Connect cluster.
+// Credentials may reference environment variables as $VAR or ${VAR}.
+type clusterContext struct {
+	Hosts    []string      `yaml:"hosts"`
+	Username string        `yaml:"username,omitempty"`
+	Password string        `yaml:"password,omitempty"`
+	Token    string        `yaml:"token,omitempty"`
+	TLS      tlsConfig     `yaml:"tls,omitempty"`
+	Timeout  time.Duration `yaml:"timeout,omitempty"`
+	Output   string        `yaml:"output,omitempty"`
+
+	// Ask before running commands that delete or overwrite things.
+	ConfirmDestructive bool `yaml:"confirm-destructive,omitempty"`
+}
+
+type tlsConfig struct {
+	CAFile             string `yaml:"ca-file,omitempty"`
+	CertFile           string `yaml:"cert-file,omitempty"`
+	KeyFile            string `yaml:"key-file,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecure-skip-verify,omitempty"`
+}
+
+var (
+	contextCmd, contextListCmd, contextUseCmd, contextShowCmd *kingpin.CmdClause
+
+	hostFlag, contextFlag, configFilePath string
+	contextArg                            string
+	assumeYes                             bool
+
+	// Resolved by ValidateArgs
+	cliConf           *cliConfig
+	activeContextName string
+	activeContext     *clusterContext
+	hosts             []*url.URL
+)
+
+func configureContextCommands(app *kingpin.Application) {
+	app.Flag("context", "Named cluster context from the config file to use.").
+		Short('C').
+		Envar(contextenv).
+		StringVar(&contextFlag)
+	app.Flag("config-file", "CLI config file defining contexts.").
+		Default(defaultConfigPath()).
+		Envar(configenv).
+		StringVar(&configFilePath)
+	app.Flag("yes", "Don't ask for confirmation of destructive commands.").
+		Short('y').
+		BoolVar(&assumeYes)
+
+	contextCmd = app.Command("context", "Manages named cluster contexts.")
+	contextListCmd = contextCmd.Command("list", "Lists contexts, marking the current one.").Default()
+	contextUseCmd = contextCmd.Command("use", "Sets the current context.")
+	contextUseCmd.Arg("name", "Name of the context to use.").Required().StringVar(&contextArg)
+	contextShowCmd = contextCmd.Command("show", "Shows settings of a context, by default the active one.")
+	contextShowCmd.Arg("name", "Name of the context to show.").StringVar(&contextArg)
+
+	hostFlag, contextFlag, configFilePath, contextArg = "", "", "", ""
+	assumeYes = false
+	cliConf, activeContextName, activeContext, hosts = nil, "", nil, nil
+}
+
+func defaultConfigPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "kafka-connect", "config.yaml")
+}
+
+// Reads the config file, which need not exist.
+func loadCLIConfig(path string) (*cliConfig, error) {
+	conf := &cliConfig{Contexts: make(map[string]*clusterContext)}
+	if path == "" {
+		return conf, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return conf, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.UnmarshalStrict(data, conf); err != nil {
+		return nil, fmt.Errorf("invalid config file %v: %v", path, err)
+	}
+	if conf.Contexts == nil {
+		conf.Contexts = make(map[string]*clusterContext)
+	}
+	return conf, nil
+}
+
+func saveCLIConfig(path string, conf *cliConfig) error {
+	data, err := yaml.Marshal(conf)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	// May contain credentials
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// Resolves which cluster to talk to. In order of precedence:
+//
+//  1. --host
+//  2. --context (or KAFKA_CONNECT_CLI_CONTEXT)
+//  3. KAFKA_CONNECT_CLI_HOST
+//  4. current-context from the config file
+//  5. DefaultHostURL
+//
+// A context also supplies credentials, TLS and other settings, which still
+// apply when the host is overridden by --host.
+func resolveTarget() (err error) {
+	if cliConf, err = loadCLIConfig(configFilePath); err != nil {
+		return
+	}
+
+	activeContextName = contextFlag
+	if activeContextName == "" && hostFlag == "" && os.Getenv(hostenv) == "" {
+		activeContextName = cliConf.CurrentContext
+	}
+	if activeContextName != "" {
+		var ok bool
+		if activeContext, ok = cliConf.Contexts[activeContextName]; !ok {
+			return fmt.Errorf("context %q is not defined in %v", activeContextName, configFilePath)
+		}
+	}
+
+	var hostList []string
+	var source string
+	switch {
+	case hostFlag != "":
+		hostList = []string{hostFlag}
+	case contextFlag != "" && len(activeContext.Hosts) > 0:
+		hostList, source = activeContext.Hosts, fmt.Sprintf("context %v", activeContextName)
+	case os.Getenv(hostenv) != "":
+		hostList, source = []string{os.Getenv(hostenv)}, hostenv
+	case activeContext != nil && len(activeContext.Hosts) > 0:
+		hostList, source = activeContext.Hosts, fmt.Sprintf("context %v", activeContextName)
+	default:
+		hostList = []string{connect.DefaultHostURL}
+	}
+
+	hosts = nil
+	for _, h := range hostList {
+		u, err := url.Parse(h)
+		if err != nil || !u.IsAbs() {
+			msg := fmt.Sprintf("host %v is not a valid absolute URL", h)
+			if source != "" {
+				msg += fmt.Sprintf(" (set by %v)", source)
+			}
+			return errors.New(msg)
+		}
+		hosts = append(hosts, u)
+	}
+	host = hosts[0]
+
+	if outputSpec == "" && activeContext != nil {
+		outputSpec = activeContext.Output
+	}
+	return nil
+}
+
+// newClient returns a client for the resolved target cluster, with the
+// active context's credentials, TLS settings and timeouts applied.
+func newClient() (*connect.Client, error) {
+	client := connect.NewClient(host.String())
+	if activeContext == nil && len(hosts) < 2 {
+		return client, nil
+	}
+
+	httpClient, err := newHTTPClient(len(hosts) > 1)
+	if err != nil {
+		return nil, err
+	}
+	client.HTTPClient = httpClient
+	return client, nil
+}
+
+// workerClients returns a client for each host of the target cluster, without
+// failing over to the others, for checking on workers individually.
+func workerClients() ([]*connect.Client, error) {
+	httpClient, err := newHTTPClient(false)
+	if err != nil {
+		return nil, err
+	}
+
+	clients := make([]*connect.Client, len(hosts))
+	for i, h := range hosts {
+		clients[i] = connect.NewClient(h.String())
+		clients[i].HTTPClient = httpClient
+	}
+	return clients, nil
+}
+
+func newHTTPClient(failover bool) (*http.Client, error) {
+	return contextHTTPClient(activeContext, hosts, failover)
+}
+
+// contextClient returns a client for a context's cluster, failing over between
+// its hosts, regardless of which context is active.
+func contextClient(name string, ctx *clusterContext) (*connect.Client, error) {
+	if len(ctx.Hosts) == 0 {
+		return nil, fmt.Errorf("context %v has no hosts", name)
+	}
+	var ctxHosts []*url.URL
+	for _, h := range ctx.Hosts {
+		u, err := url.Parse(h)
+		if err != nil || !u.IsAbs() {
+			return nil, fmt.Errorf("host %v is not a valid absolute URL (set by context %v)", h, name)
+		}
+		ctxHosts = append(ctxHosts, u)
+	}
+
+	httpClient, err := contextHTTPClient(ctx, ctxHosts, len(ctxHosts) > 1)
+	if err != nil {
+		return nil, err
+	}
+	client := connect.NewClient(ctxHosts[0].String())
+	client.HTTPClient = httpClient
+	return client, nil
+}
+
+func contextHTTPClient(ctx *clusterContext, hosts []*url.URL, failover bool) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	var rt http.RoundTripper = transport
+	httpClient := &http.Client{}
+
+	if ctx != nil {
+		tlsConf, err := ctx.TLS.build()
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConf
+		httpClient.Timeout = ctx.Timeout
+
+		if ctx.Token != "" || ctx.Username != "" {
+			rt = &authTransport{
+				base:     rt,
+				username: os.ExpandEnv(ctx.Username),
+				password: os.ExpandEnv(ctx.Password),
+				token:    os.ExpandEnv(ctx.Token),
+			}
+		}
+	}
+	if failover {
+		rt = &failoverTransport{base: rt, hosts: hosts}
+	}
+
+	httpClient.Transport = rt
+	return httpClient, nil
+}
+
+func (t tlsConfig) build() (*tls.Config, error) {
+	if t == (tlsConfig{}) {
+		return nil, nil
+	}
+
+	conf := &tls.Config{InsecureSkipVerify: t.InsecureSkipVerify}
+	if t.CAFile != "" {
+		pem, err := ioutil.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		conf.RootCAs = x509.NewCertPool()
+		if !conf.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %v", t.CAFile)
+		}
+	}
+	if t.CertFile != "" || t.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	}
+	return conf, nil
+}
+
+// authTransport adds bearer token or basic auth credentials to requests.
+type authTransport struct {
+	base                      http.RoundTripper
+	username, password, token string
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if t.token != "" {
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	} else {
+		req.SetBasicAuth(t.username, t.password)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// failoverTransport retries requests against the next host in a list when a
+// host cannot be reached. Any worker in a Connect cluster can serve any
+// request, so this is safe as long as one is up.
+type failoverTransport struct {
+	base  http.RoundTripper
+	hosts []*url.URL
+}
+
+func (t *failoverTransport) RoundTrip(req *http.Request) (resp *http.Response, err error) {
+	for _, h := range t.hosts {
+		attempt := req.Clone(req.Context())
+		attempt.URL.Scheme, attempt.URL.Host = h.Scheme, h.Host
+		attempt.Host = ""
+		if req.GetBody != nil {
+			if attempt.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+
+		if resp, err = t.base.RoundTrip(attempt); err == nil {
+			return resp, nil
+		}
+	}
+	return nil, err
+}
+
+// confirmDestructive asks the user to confirm an action if the active context
+// requires it, returning an error if they decline or can't be asked.
+func confirmDestructive(action string) error {
+	return confirmDestructiveIn(activeContextName, activeContext, action)
+}
+
+// confirmDestructiveIn is confirmDestructive for a context other than the
+// active one, such as the target of a migration.
+func confirmDestructiveIn(name string, ctx *clusterContext, action string) error {
+	if ctx == nil || !ctx.ConfirmDestructive {
+		return nil
+	}
+	return askConfirmation(fmt.Sprintf("Context %v: really %v?", name, action))
+}
+
+// askConfirmation prompts on the terminal for a yes or no answer, returning an
+// error unless the answer is yes or --yes was given.
+func askConfirmation(prompt string) error {
+	if assumeYes {
+		return nil
+	}
+	if pipedinput {
+		return fmt.Errorf("confirmation required but input is not a terminal, use --yes: %v", prompt)
+	}
+
+	fmt.Fprintf(os.Stderr, "%v [y/N] ", prompt)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	}
+	return errors.New("aborted")
+}
+
+func runContextCommand(subcommand string) error {
+	switch subcommand {
+	case contextListCmd.FullCommand():
+		names := make([]string, 0, len(cliConf.Contexts))
+		for name := range cliConf.Contexts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		rows := [][]string{{"CURRENT", "NAME", "HOSTS"}}
+		for _, name := range names {
+			current := ""
+			if name == cliConf.CurrentContext {
+				current = "*"
+			}
+			rows = append(rows, []string{current, name, strings.Join(cliConf.Contexts[name].Hosts, ",")})
+		}
+		return writeTable(os.Stdout, rows)
+
+	case contextUseCmd.FullCommand():
+		if _, ok := cliConf.Contexts[contextArg]; !ok {
+			return fmt.Errorf("context %q is not defined in %v", contextArg, configFilePath)
+		}
+		cliConf.CurrentContext = contextArg
+		if err := saveCLIConfig(configFilePath, cliConf); err != nil {
+			return err
+		}
+		fmt.Printf("Switched to context %v.\n", contextArg)
+		return nil
+
+	case contextShowCmd.FullCommand():
+		name := contextArg
+		if name == "" {
+			name = activeContextName
+		}
+		if name == "" {
+			return errors.New("no context is active, give a name")
+		}
+		ctx, ok := cliConf.Contexts[name]
+		if !ok {
+			return fmt.Errorf("context %q is not defined in %v", name, configFilePath)
+		}
+
+		shown := *ctx
+		for _, secret := range []*string{&shown.Password, &shown.Token} {
+			if *secret != "" && !strings.HasPrefix(*secret, "$") {
+				*secret = "********"
+			}
+		}
+		data, err := yaml.Marshal(map[string]*clusterContext{name: &shown})
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+
+	return fmt.Errorf("command `%v` is missing implementation", subcommand)
+}
@@ -35,12 +35,261 @@ tool:
 
 	kafka-connect show connector-name | jq 'del(.tasks)'
 
+The config command also prints a hash of the configuration on standard error.
+Passing it back to update guards against overwriting someone else's changes made
+in the meantime:
+
+	kafka-connect config connector-name > config.json
+	Config hash: 3b1f...
+	kafka-connect update connector-name --config config.json --expect-hash 3b1f...
+
+Connector input can also be given as YAML, or as a Java properties file like
+those used for connectors in Kafka Connect standalone mode, so these can be moved
+to distributed mode as is. The format is detected from the file extension, or
+can be given with --format, which is necessary for standard input:
+
+	kafka-connect create --from-file connector.properties
+	cat config.yaml | kafka-connect update connector-name --format yaml
+
+For quick changes by hand, edit opens a connector's config in $EDITOR, as JSON
+by default or with --format yaml or properties. The result is validated by the
+connector plugin before a diff is shown for confirmation; if it's invalid, the
+editor is reopened with the problems noted at the top:
+
+	kafka-connect edit connector-name --format yaml
+
+Individual values can be changed with set and unset, which show what changed.
+With --dry-run, nothing is changed:
+
+	kafka-connect set connector-name tasks.max=4 errors.tolerance=all --dry-run
+	kafka-connect unset connector-name transforms.foo.type
+
+To change how many tasks a connector runs, scale sets tasks.max, waits for the
+new tasks to appear and shows how they are spread across workers. It refuses to
+give a sink connector more tasks than the partitions of its active topics,
+unless given --force. Those are counted from its committed offsets, which miss
+partitions without commits yet, so give the real count with --partitions when
+it is known:
+
+	kafka-connect scale orders-sink --tasks 8
+
 If you have configurations, you can also create new connector instances by
 specifying names for them on the command line:
 
 	kafka-connect create new-connector --config config.json
 	cat config.json | kafka-connect create new-connector
 
+When provisioning, you may not know or care whether a connector exists yet.
+The upsert command takes the same input as create, but updates the connector's
+config if it already exists, reporting which happened on standard error:
+
+	kafka-connect upsert --from-file connector.json
+	kafka-connect upsert connector-name --config config.json
+
+Connect has no way to rename a connector. Clone creates a copy under a new name,
+with optional --set overrides, starting afresh without the original's offsets.
+Rename stops the original, carries its offsets over where the worker supports
+the offsets API (Kafka 3.7 or later), creates the new connector in the
+original's state and then deletes the original. If any step fails, it is rolled
+back:
+
+	kafka-connect clone orders-sink orders-sink-test --set topics=orders-test
+	kafka-connect rename orders-sink orders-jdbc-sink
+
+Linting Configs
+
+The lint command checks connector configs offline for common mistakes: a
+missing tasks.max, both topics and topics.regex set, errors.tolerance=all on a
+sink without a dead letter queue, converter settings that don't suit the
+converter, secrets in plain text rather than config provider references, and
+transforms or predicates that aren't defined. Files may be in any format create
+accepts. Findings are printed with their severity, and lint fails if any are at
+--fail-on or above, errors by default. For CI, -o json prints findings as JSON
+and --sarif as SARIF for code scanning:
+
+	kafka-connect lint -f orders-sink.json -f payments-sink.yaml
+	kafka-connect lint -f orders-sink.json --sarif > lint.sarif
+
+Create, update and upsert lint the config before sending it, reporting any
+findings and refusing to send configs with errors unless given --no-lint.
+
+Acting on Many Connectors
+
+Pause, resume, restart and delete take any number of connector names or glob
+patterns, and can select connectors by --regex on the name, --class, config
+values with --where, or --state of the connector or any of its tasks. The
+selected connectors are listed first, and acted on a few at a time with a
+summary of results. More than --confirm-over connectors, 5 by default, need
+confirmation or --yes. Use --dry-run to only see what would be selected.
+Restart only restarts connector instances, unless given --include-tasks, and
+--only-failed to restart just what has failed:
+
+	kafka-connect pause --class JdbcSinkConnector --where connection.url=jdbc:postgresql://orders-db/orders
+	kafka-connect restart --state FAILED --include-tasks --only-failed
+	kafka-connect delete 'tmp-*' --dry-run
+
+Rolling Restarts
+
+After a worker upgrade, rolling-restart restarts connectors and their tasks a
+batch at a time, waiting for each batch to be RUNNING before moving on. It takes
+the same selection arguments as restart, or restarts all connectors by default,
+skipping those that are paused or stopped. The rollout stops if more than --max-failure-rate of the connectors fail to
+recover. Progress is recorded in the --progress file, so running the command
+again resumes an interrupted rollout, and retries connectors that failed:
+
+	kafka-connect rolling-restart --class JdbcSinkConnector --batch-size 3 --timeout 5m
+
+Worker Maintenance
+
+The workers command lists each worker that has connectors or tasks assigned,
+with how many of each. Before patching a worker's host, drain pauses the
+connectors with work on it, or with --mode restart restarts that work in place.
+Neither moves work off the worker: paused tasks stay assigned to it, and Connect
+reassigns work only when the worker leaves the group. The prior state of each
+connector is recorded in --state-file, and undrain puts them all back exactly as
+they were:
+
+	kafka-connect workers
+	kafka-connect drain connect-2.prod:8083
+	kafka-connect undrain
+
+Rebalancing can leave some workers with far more tasks than others. The balance
+command shows task counts per worker, broken down by connector class, and flags
+workers with more than --max-skew times the mean, or less than the mean divided
+by it. It suggests tasks on overloaded workers to restart so they may be
+assigned elsewhere, and with --restart restarts them:
+
+	kafka-connect balance --max-skew 2
+	kafka-connect balance --restart --dry-run
+
+Cluster Status
+
+Given no connector name, status shows an overview of every connector on the
+cluster with counts of its task states. Add --failed to see only unhealthy
+connectors, with the root cause of each failed task, and --watch to redraw
+periodically with changes highlighted:
+
+	kafka-connect status --failed --watch --interval 5s
+
+The status of a single connector is printed as JSON, or with -o table as a
+table followed by its failures. Failures are summarized by the innermost
+"Caused by" exception of their Java stack trace, rather than the wrapping
+ConnectException. Add --trace to see the full traces instead:
+
+	kafka-connect -o table status connector-name --trace
+
+Failures that match a known error, such as a converter mismatch, missing ACLs
+or a missing sink table, are followed by a hint of what to try first, with a
+link to documentation where there is one. Add your own rules, for instance for
+your own systems, in a YAML file given by --hints or KAFKA_CONNECT_CLI_HINTS.
+They are tried before the built-in rules:
+
+	rules:
+	- name: orders-db-down
+	  class: java\.net\.ConnectException
+	  message: orders-db
+	  hint: The orders database is down, see the runbook.
+	  link: https://wiki.example.com/runbooks/orders-db
+
+Class patterns must match the whole class name, qualified or not, while message
+patterns may match anywhere in the message.
+
+When a shared dependency such as a database fails, many tasks fail alike. The
+failures command groups failed connectors and tasks by root cause, with hosts,
+IDs and numbers such as offsets masked so that causes differing only in those
+are counted together:
+
+	kafka-connect failures
+
+Cluster Health
+
+The doctor command checks the whole cluster: that every worker in the host list
+responds, that every connector and task is RUNNING and none are UNASSIGNED, that
+tasks are spread evenly across workers as balance judges it, and that configs
+pass lint, counting errors unless given --lint-severity. It prints a summary
+with hints for known failures and exits with an error if any check fails. For
+pipelines, -o json prints the report as JSON and --junit as JUnit XML:
+
+	kafka-connect doctor
+	kafka-connect -C production doctor --junit > doctor.xml
+
+Auto-Healing
+
+The heal command watches for failed tasks and restarts them, waiting longer
+after each attempt from --backoff up to --max-backoff. After --max-attempts
+restarts that didn't stick it gives up on the task and runs the --on-escalate
+command, if any, with the event as JSON on standard input. A task that stays
+running for --reset-after is forgiven its attempts. Connectors can be left
+alone with --exclude name patterns, and failures with --exclude-cause patterns
+on their root cause. Every action is logged, as JSON lines with -o json, and
+--dry-run only logs what would be done:
+
+	kafka-connect heal --max-attempts 3 --exclude 'tmp-*' \
+		--exclude-cause 'SQLSyntaxErrorException' --on-escalate 'page-oncall'
+
+Notifications
+
+status --watch and heal can tell other systems about what they see, such as a
+relay to a chat or paging service. status --watch notifies of every change of
+state of a connector or task after its first refresh, with the new state in
+lower case as the event type, e.g. failed or running. heal notifies of its
+actions, such as restart or give-up. --notify-webhook POSTs each event as JSON,
+retrying network errors, 429 and 5xx responses, or as rendered by the Go
+template in --notify-template, where json quotes a value:
+
+	{"text": {{json .Message}}}
+
+--notify-command runs a shell command with the event as JSON on standard input
+and its fields in KAFKA_CONNECT_EVENT_TYPE, _CONNECTOR, _TASK, _FROM, _TO,
+_WORKER, _CAUSE, _MESSAGE, _TIME and _SUPPRESSED environment variables.
+
+Events can be limited to some types with --notify-on, and to some connectors
+with --notify-connector glob patterns. So that a flapping task doesn't flood a
+channel, the same event is sent once per --notify-dedupe, 10 minutes by
+default, and --notify-rate-limit caps the events per connector each
+--notify-rate-period. The next event sent counts those held back:
+
+	kafka-connect status --watch --interval 30s --notify-on failed \
+		--notify-webhook https://relay.example.com/connect --notify-template slack.tmpl
+	kafka-connect heal --notify-on give-up --notify-command 'page-oncall "$KAFKA_CONNECT_EVENT_MESSAGE"'
+
+Prometheus Metrics
+
+The exporter command polls the REST API in the background and serves the
+latest results as Prometheus metrics on /metrics, so scrapes never reach the
+workers themselves. Metrics include a state gauge for each connector and task,
+labeled by name, type and worker, the connectors and tasks on each worker, and
+the duration and errors of polling:
+
+	kafka-connect exporter --listen :9400 --interval 30s
+
+Output Formats
+
+Results are printed as JSON by default. The global --output (or -o) flag selects
+another format: yaml, table (a readable summary, e.g. of list or status),
+properties (for config), or name to print just connector names. For scripting
+without jq, go-template= and jsonpath= take a template, much like kubectl:
+
+	kafka-connect -o table list
+	kafka-connect -o properties config connector-name > connector.properties
+	kafka-connect -o 'jsonpath={.connector.state}' status connector-name
+	kafka-connect -o 'go-template={{range .tasks}}{{.id}} {{.state}}{{"\n"}}{{end}}' status connector-name
+
+The jsonpath support is a subset: {expressions} are paths of .field, ['field'],
+[index] and [*] steps, and multiple matches are separated by spaces.
+
+Backups
+
+All connector definitions on a cluster, including whether each is running,
+paused or stopped, can be saved to a directory of JSON files or a gzipped
+tarball, and restored later:
+
+	kafka-connect backup --out connectors-backup.tar.gz
+	kafka-connect restore --from connectors-backup.tar.gz --on-conflict skip
+
+Restore refuses to touch a cluster where any of the connectors already exist,
+unless told to skip or overwrite them.
+
 API Host
 
 By default kafka-connect will attempt to make requests to a Kafka Connect API
@@ -48,12 +297,90 @@ instance running on localhost and the default API port of 8083. This can be
 changed by giving a full URL with the --host (or -H) flag, or with the
 environment variable KAFKA_CONNECT_CLI_HOST.
 
-Putting it all together, you might migrate connectors from one cluster to
-another:
+Contexts
+
+When working with several clusters, define named contexts in a config file at
+~/.config/kafka-connect/config.yaml (or as given by --config-file or
+KAFKA_CONNECT_CLI_CONFIG). Each has a list of worker hosts, tried in order
+until one responds, and optionally credentials, TLS settings, a request timeout
+and a default output format:
+
+	current-context: dev
+	contexts:
+	  dev:
+	    hosts: [http://localhost:8083/]
+	  prod:
+	    hosts: [https://connect-1.prod:8083/, https://connect-2.prod:8083/]
+	    username: ops
+	    password: ${CONNECT_PASSWORD}
+	    tls:
+	      ca-file: /etc/ssl/prod-ca.pem
+	    timeout: 30s
+	    output: table
+	    confirm-destructive: true
+
+Switch between them with context use, or pick one for a single command with
+--context (or KAFKA_CONNECT_CLI_CONTEXT). The cluster to talk to is chosen by
+the first of these that is set: --host, --context, KAFKA_CONNECT_CLI_HOST, and
+the current context. Contexts with confirm-destructive ask before deleting or
+overwriting anything, unless given --yes.
+
+	kafka-connect context list
+	kafka-connect context use prod
+	kafka-connect --context dev status
+
+REST Gateway
+
+The serve command fronts the clusters of several contexts with one REST API,
+for tools such as an internal portal. Each cluster is served under
+/clusters/{context}/ with Connect's own endpoints and JSON shapes, e.g.
+/clusters/prod/connectors/orders/status. Cluster-wide, /clusters lists the
+clusters, /connectors lists connector statuses across them, selected by name
+and state query parameters, and /summary counts connectors and tasks in each
+state by cluster. All contexts are served unless some are given by --cluster:
+
+	kafka-connect serve --listen :8080 --auth-file gateway-users.yaml \
+		--audit-log /var/log/kafka-connect/audit.log
+
+Every request needs an API key, as an X-API-Key header or a bearer token. Users
+and their keys are defined in the --auth-file, with a read or write role for
+each cluster, or for all others as "*". Keys may reference environment
+variables:
+
+	users:
+	- name: portal
+	  key: ${PORTAL_API_KEY}
+	  roles:
+	    prod: read
+	    "*": write
+
+Every call other than a GET, allowed or not, is appended to the --audit-log as
+a JSON line with the user, cluster, method, path and response status. Serve
+HTTPS with --tls-cert and --tls-key.
+
+Migrating Between Clusters
+
+The migrate command copies connectors from the --host cluster to another,
+optionally renaming them and rewriting config values on the way. With
+--delete-source, each connector is removed from the original cluster once it is
+running on the new one:
+
+	kafka-connect migrate 'orders-*' --to http://newcluster:8083 \
+		--rename '^orders-(.*)=orders-v2-$1' \
+		--rewrite 'topics:^prod\.=staging.' \
+		--set tasks.max=2 --delete-source
+
+To keep a standby cluster continuously in line with the primary instead, use
+sync, which takes the same rules:
+
+	kafka-connect sync --to http://standby:8083 --interval 1m
+
+Instead of --to, both commands accept --to-context to reach the target cluster
+with the hosts, credentials and TLS settings of a context.
 
-	kafka-connect show connector-name | jq 'del(.tasks)' | \
-		kafka-connect -H http://newcluster:8083 create
-	kafka-connect delete connector-name
+With --prune, sync also deletes connectors from the target that it synced
+earlier in the same run but that have since gone from the --host cluster, so it
+cannot be combined with --once.
 
 For complete details of the data structures, see the REST API documentation:
 http://docs.confluent.io/latest/connect/userguide.html#connect-userguide-rest.
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/go-kafka/connect"
+	"github.com/go-kafka/connect/doctor"
+	"github.com/go-kafka/connect/lint"
+)
+
+var (
+	doctorCmd *kingpin.CmdClause
+
+	doctorLintSeverity string
+	doctorJUnit        bool
+)
+
+func configureDoctorCommand(app *kingpin.Application) {
+	doctorCmd = app.Command("doctor", "Checks the health of the whole cluster, failing if anything is wrong.")
+	doctorCmd.Flag("max-skew", "Flag workers with more than this many times the mean number of tasks, or less than the mean divided by it.").
+		Default(strconv.FormatFloat(connect.DefaultMaxSkew, 'f', -1, 64)).
+		Float64Var(&maxSkew)
+	doctorCmd.Flag("lint-severity", "Count lint findings at least this severe: info, warning or error.").
+		Default("error").
+		EnumVar(&doctorLintSeverity, "info", "warning", "error")
+	doctorCmd.Flag("junit", "Print the report as JUnit XML, for CI pipelines to gate on.").
+		BoolVar(&doctorJUnit)
+
+	// Reset state for in-process tests
+	doctorLintSeverity, doctorJUnit = "", false
+}
+
+func runDoctor(client *connect.Client) error {
+	workers, err := workerClients()
+	if err != nil {
+		return err
+	}
+	catalog, err := loadHints()
+	if err != nil {
+		return err
+	}
+	severity, _ := lint.ParseSeverity(doctorLintSeverity)
+
+	d := &doctor.Doctor{
+		Client:       client,
+		Workers:      workers,
+		MaxSkew:      maxSkew,
+		LintSeverity: severity,
+		Hints:        catalog,
+	}
+	report := d.Examine()
+
+	switch {
+	case doctorJUnit:
+		err = writeJUnit(os.Stdout, report)
+	case outputSpec != "":
+		err = printOutput(report)
+	default:
+		writeDoctorReport(os.Stdout, report)
+	}
+	if err != nil {
+		return err
+	}
+
+	if failed := len(report.Failed()); failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(report.Checks))
+	}
+	return nil
+}
+
+func writeDoctorReport(w io.Writer, report *doctor.Report) {
+	for _, c := range report.Checks {
+		result := "PASS"
+		if !c.Passed() {
+			result = "FAIL"
+		}
+		fmt.Fprintf(w, "%v  %v: %v\n", result, c.Name, c.Description)
+
+		if c.Error != "" {
+			fmt.Fprintf(w, "      %v\n", c.Error)
+		}
+		for _, f := range c.Findings {
+			fmt.Fprintf(w, "      %v: %v\n", f.Subject, f.Message)
+			if f.Hint != "" {
+				fmt.Fprintf(w, "        hint: %v\n", f.Hint)
+			}
+			if f.Link != "" {
+				fmt.Fprintf(w, "        see: %v\n", f.Link)
+			}
+		}
+	}
+
+	if report.Healthy() {
+		fmt.Fprintf(w, "\nAll %d checks passed.\n", len(report.Checks))
+	}
+}
+
+// JUnit XML as understood by most CI systems, each check being a test case.
+type junitSuite struct {
+	XMLName  xml.Name    `xml:"testsuite"`
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func writeJUnit(w io.Writer, report *doctor.Report) error {
+	suite := junitSuite{Name: "kafka-connect doctor", Tests: len(report.Checks)}
+	for _, c := range report.Checks {
+		tc := junitCase{Name: c.Name, ClassName: "kafka-connect.doctor"}
+		switch {
+		case c.Error != "":
+			suite.Errors++
+			tc.Error = &junitProblem{Message: c.Error}
+		case len(c.Findings) > 0:
+			suite.Failures++
+			lines := make([]string, len(c.Findings))
+			for i, f := range c.Findings {
+				lines[i] = fmt.Sprintf("%v: %v", f.Subject, f.Message)
+				if f.Hint != "" {
+					lines[i] += "\n  hint: " + f.Hint
+				}
+			}
+			tc.Failure = &junitProblem{
+				Message: fmt.Sprintf("%d findings", len(c.Findings)),
+				Text:    strings.Join(lines, "\n"),
+			}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	data, err := xml.MarshalIndent(suite, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%v%s\n", xml.Header, data)
+	return err
+}
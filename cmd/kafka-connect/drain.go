@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/go-kafka/connect"
+)
+
+// Ways to move work away from a worker.
+const (
+	drainPause   = "pause"
+	drainRestart = "restart"
+)
+
+var (
+	workersCmd, drainCmd, undrainCmd *kingpin.CmdClause
+
+	workerID       string
+	drainMode      string
+	drainStatePath string
+)
+
+// A drainRecord is saved by drain so that undrain can put connectors back in
+// the states they were in.
+type drainRecord struct {
+	WorkerID string            `json:"worker_id"`
+	Mode     string            `json:"mode"`
+	States   map[string]string `json:"states"`
+}
+
+func configureDrainCommands(app *kingpin.Application) {
+	workersCmd = app.Command("workers", "Lists workers with connectors or tasks assigned, and how many of each.")
+
+	drainCmd = app.Command("drain", "Pauses, or restarts in place, the connectors and tasks on a worker before maintenance.")
+	drainCmd.Arg("worker-id", "ID of the worker, host:port as shown by workers.").
+		Required().
+		StringVar(&workerID)
+	drainCmd.Flag("mode", "Pause connectors with work on the worker, which stops their tasks but leaves them assigned, or restart that work in place.").
+		Default(drainPause).
+		EnumVar(&drainMode, drainPause, drainRestart)
+
+	undrainCmd = app.Command("undrain", "Returns connectors affected by drain to the states they were in.")
+
+	for _, command := range []*kingpin.CmdClause{drainCmd, undrainCmd} {
+		command.Flag("state-file", "File recording connector states from before the drain.").
+			Default("kafka-connect-drain.json").
+			PlaceHolder("FILE").
+			StringVar(&drainStatePath)
+	}
+
+	// Reset state for in-process tests
+	workerID, drainMode, drainStatePath = "", "", ""
+}
+
+func listWorkers(client *connect.Client) error {
+	loads, err := client.ListWorkerLoads()
+	if err != nil {
+		return err
+	}
+
+	if outputSpec != "" {
+		return printOutput(loads)
+	}
+	return writeTable(os.Stdout, workerLoadRows(loads))
+}
+
+func workerLoadRows(loads []connect.WorkerLoad) [][]string {
+	rows := [][]string{{"WORKER", "CONNECTORS", "TASKS"}}
+	for _, load := range loads {
+		rows = append(rows, []string{load.WorkerID, strconv.Itoa(len(load.Connectors)), strconv.Itoa(len(load.Tasks))})
+	}
+	return rows
+}
+
+func drainWorker(client *connect.Client) error {
+	if _, err := os.Stat(drainStatePath); err == nil {
+		return fmt.Errorf("%v exists from an earlier drain, undrain first or remove it", drainStatePath)
+	}
+
+	statuses, err := client.ListConnectorStatuses()
+	if err != nil {
+		return err
+	}
+	var load *connect.WorkerLoad
+	for _, l := range connect.WorkerLoads(statuses) {
+		if l.WorkerID == workerID {
+			load = &l
+			break
+		}
+	}
+	if load == nil {
+		return fmt.Errorf("no connectors or tasks found on worker %v, see workers for known IDs", workerID)
+	}
+
+	// Record every affected connector before touching any, so that a drain
+	// that fails part way can still be undone
+	record := drainRecord{WorkerID: workerID, Mode: drainMode, States: make(map[string]string)}
+	affected := make(map[string]bool)
+	for _, name := range load.Connectors {
+		affected[name] = true
+	}
+	for _, task := range load.Tasks {
+		affected[task.ConnectorName] = true
+	}
+	for _, status := range statuses {
+		if affected[status.Name] {
+			record.States[status.Name] = status.Connector.State
+		}
+	}
+	if err := writeDrainRecord(&record); err != nil {
+		return err
+	}
+
+	var failed int
+	report := func(err error, format string, args ...interface{}) {
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "Failed to "+format+": %v\n", append(args, err)...)
+		}
+	}
+
+	if drainMode == drainPause {
+		for _, name := range sortedStateNames(record.States) {
+			if state := record.States[name]; state == "PAUSED" || state == "STOPPED" {
+				continue
+			}
+			_, err := client.PauseConnector(name)
+			report(err, "pause connector %v", name)
+		}
+	} else {
+		for _, name := range load.Connectors {
+			_, err := client.RestartConnector(name)
+			report(err, "restart connector %v", name)
+		}
+		for _, task := range load.Tasks {
+			_, err := client.RestartTask(task.ConnectorName, task.ID)
+			report(err, "restart task %v/%d", task.ConnectorName, task.ID)
+		}
+	}
+
+	fmt.Printf("Drained worker %v, %d connectors affected. Run undrain to restore them.\n",
+		workerID, len(record.States))
+	if failed > 0 {
+		return fmt.Errorf("%d actions failed while draining %v", failed, workerID)
+	}
+	return nil
+}
+
+func undrainWorker(client *connect.Client) error {
+	data, err := ioutil.ReadFile(drainStatePath)
+	if err != nil {
+		return err
+	}
+	var record drainRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return fmt.Errorf("%v is not a drain record: %v", drainStatePath, err)
+	}
+
+	var failed int
+	for _, name := range sortedStateNames(record.States) {
+		response, err := client.SetConnectorState(name, record.States[name])
+		switch {
+		case response != nil && response.StatusCode == http.StatusNotFound:
+			fmt.Fprintf(os.Stderr, "Connector %v no longer exists, skipping.\n", name)
+		case err != nil:
+			failed++
+			fmt.Fprintf(os.Stderr, "Failed to restore connector %v: %v\n", name, err)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d connectors could not be restored, %v is kept to retry", failed, drainStatePath)
+	}
+	fmt.Printf("Restored %d connectors drained from worker %v.\n", len(record.States), record.WorkerID)
+	return os.Remove(drainStatePath)
+}
+
+func writeDrainRecord(record *drainRecord) error {
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(drainStatePath, data, 0644)
+}
+
+func sortedStateNames(states map[string]string) []string {
+	names := make([]string, 0, len(states))
+	for name := range states {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/go-kafka/connect"
+)
+
+const editInstructions = `Edit the config of connector %v below. Lines at the top beginning with '#'
+are ignored, and an empty file aborts the edit. The result is validated before
+any change is applied.
+`
+
+var editCmd *kingpin.CmdClause
+
+func configureEditCommand(app *kingpin.Application) {
+	editCmd = app.Command("edit", "Edits the config of a connector with $EDITOR.")
+	editCmd.Arg("name", "Name of the connector to edit.").Required().StringVar(&connName)
+	editCmd.Flag("format", "Format to edit the config in.").
+		Default(formatJSON).
+		EnumVar(&inputFormat, formatJSON, formatYAML, formatProperties)
+}
+
+func editConnector(name string, client *connect.Client) error {
+	original, _, err := client.GetConnectorConfig(name)
+	if err != nil {
+		return err
+	}
+
+	body, err := encodeConnectorConfig(original, inputFormat)
+	if err != nil {
+		return err
+	}
+
+	file, err := ioutil.TempFile("", "kafka-connect-edit-*."+inputFormat)
+	if err != nil {
+		return err
+	}
+	_ = file.Close()
+	defer os.Remove(file.Name())
+
+	var edited connect.ConnectorConfig
+	var problems []string
+	for {
+		contents := append(annotate(fmt.Sprintf(editInstructions, name), problems), body...)
+		if err := ioutil.WriteFile(file.Name(), contents, 0600); err != nil {
+			return err
+		}
+		if err := runEditor(file.Name()); err != nil {
+			return err
+		}
+
+		result, err := ioutil.ReadFile(file.Name())
+		if err != nil {
+			return err
+		}
+		result = stripAnnotations(result)
+		if len(bytes.TrimSpace(result)) == 0 {
+			return errors.New("edit cancelled, file was empty")
+		}
+		if len(problems) > 0 && bytes.Equal(result, body) {
+			return fmt.Errorf("edit cancelled, config is still invalid:\n  %v", strings.Join(problems, "\n  "))
+		}
+		body = result
+
+		edited = nil
+		if err := decodeConnectorData(body, inputFormat, &edited); err != nil {
+			problems = []string{fmt.Sprintf("Could not parse %v: %v", inputFormat, err)}
+			continue
+		}
+		if edited.Hash() == original.Hash() {
+			fmt.Println("Edit cancelled, no changes made.")
+			return nil
+		}
+
+		if problems, err = validationProblems(client, edited); err != nil {
+			return err
+		}
+		if len(problems) == 0 {
+			break
+		}
+	}
+
+	for _, line := range configDiff(original, edited) {
+		fmt.Println(line)
+	}
+	if err := askConfirmation(fmt.Sprintf("Apply these changes to connector %v?", name)); err != nil {
+		return err
+	}
+
+	// Someone may have changed the config while we were editing
+	if _, _, err := client.UpdateConnectorConfigIfMatch(name, edited, original.Hash()); err != nil {
+		if err == connect.ErrConcurrentModification {
+			return fmt.Errorf("connector %v was modified by someone else while editing, nothing was changed", name)
+		}
+		return err
+	}
+	fmt.Printf("Updated connector %v.\n", name)
+	return nil
+}
+
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// Allow for editors that need arguments, like "code --wait"
+	args := append(strings.Fields(editor), path)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %v failed: %v", editor, err)
+	}
+	return nil
+}
+
+// Validates config against its plugin, returning any problems found as
+// messages for the user.
+func validationProblems(client *connect.Client, config connect.ConnectorConfig) ([]string, error) {
+	class := config["connector.class"]
+	if class == "" {
+		return []string{"connector.class is required"}, nil
+	}
+
+	validation, _, err := client.ValidateConnectorConfig(class, config)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := validation.Errors()
+	keys := make([]string, 0, len(errs))
+	for key := range errs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var problems []string
+	for _, key := range keys {
+		for _, msg := range errs[key] {
+			problems = append(problems, fmt.Sprintf("%v: %v", key, msg))
+		}
+	}
+	return problems, nil
+}
+
+// Prefixes text and any problems as a block of # comment lines.
+func annotate(text string, problems []string) []byte {
+	var buf bytes.Buffer
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		fmt.Fprintf(&buf, "# %v\n", line)
+	}
+	if len(problems) > 0 {
+		buf.WriteString("#\n# The config is invalid:\n")
+		for _, problem := range problems {
+			fmt.Fprintf(&buf, "#   %v\n", problem)
+		}
+	}
+	buf.WriteString("#\n")
+	return buf.Bytes()
+}
+
+// Removes the leading block of # comment lines added by annotate.
+func stripAnnotations(contents []byte) []byte {
+	for len(contents) > 0 && contents[0] == '#' {
+		i := bytes.IndexByte(contents, '\n')
+		if i < 0 {
+			return nil
+		}
+		contents = contents[i+1:]
+	}
+	return contents
+}
+
+// Describes changes between two configs as lines of removed (-) and added (+)
+// key=value pairs, sorted by key.
+func configDiff(old, new connect.ConnectorConfig) []string {
+	keys := make(map[string]bool)
+	for k := range old {
+		keys[k] = true
+	}
+	for k := range new {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	var lines []string
+	for _, k := range sorted {
+		oldValue, inOld := old[k]
+		newValue, inNew := new[k]
+		if inOld && inNew && oldValue == newValue {
+			continue
+		}
+		if inOld {
+			lines = append(lines, fmt.Sprintf("- %v=%v", k, oldValue))
+		}
+		if inNew {
+			lines = append(lines, fmt.Sprintf("+ %v=%v", k, newValue))
+		}
+	}
+	return lines
+}
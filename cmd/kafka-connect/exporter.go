@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/go-kafka/connect"
+	"github.com/go-kafka/connect/monitor"
+)
+
+var (
+	exporterCmd *kingpin.CmdClause
+
+	listenAddr   string
+	pollInterval time.Duration
+)
+
+func configureExporterCommand(app *kingpin.Application) {
+	exporterCmd = app.Command("exporter", "Serves connector and task states as Prometheus metrics.")
+	exporterCmd.Flag("listen", "Address to serve metrics on, at /metrics.").
+		Default(":9400").
+		StringVar(&listenAddr)
+	exporterCmd.Flag("interval", "How often to poll the REST API. Scrapes are served from the latest poll.").
+		Default(monitor.DefaultInterval.String()).
+		DurationVar(&pollInterval)
+
+	// Reset state for in-process tests
+	listenAddr, pollInterval = "", 0
+}
+
+func runExporter(client *connect.Client) error {
+	poller := &monitor.Poller{Client: client, Interval: pollInterval}
+	go poller.Run(nil)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", monitor.Handler(poller))
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintln(w, "Kafka Connect exporter, metrics are at /metrics")
+	})
+
+	fmt.Fprintf(os.Stderr, "Serving metrics for %v on %v/metrics, polling every %v.\n",
+		client.Host(), listenAddr, pollInterval)
+	return http.ListenAndServe(listenAddr, mux)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/go-kafka/connect"
+)
+
+var failuresCmd *kingpin.CmdClause
+
+func configureFailuresCommand(app *kingpin.Application) {
+	failuresCmd = app.Command("failures", "Groups failed connectors and tasks by the root cause of their failure.")
+}
+
+func showFailures(client *connect.Client) error {
+	groups, err := client.GetFailureGroups()
+	if err != nil {
+		return err
+	}
+
+	if outputSpec != "" {
+		return printOutput(groups)
+	}
+	if len(groups) == 0 {
+		fmt.Println("No failed connectors or tasks.")
+		return nil
+	}
+	return writeTable(os.Stdout, failureGroupRows(groups))
+}
+
+func failureGroupRows(groups []connect.FailureGroup) [][]string {
+	rows := [][]string{{"COUNT", "CAUSE", "AFFECTED"}}
+	for _, g := range groups {
+		affected := append([]string{}, g.Connectors...)
+		for _, task := range g.Tasks {
+			affected = append(affected, fmt.Sprintf("%v/%d", task.ConnectorName, task.ID))
+		}
+		rows = append(rows, []string{strconv.Itoa(g.Count()), g.Cause, strings.Join(affected, ", ")})
+	}
+	return rows
+}
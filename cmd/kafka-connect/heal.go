@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"regexp"
+	"strconv"
+	"syscall"
+	"time"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/go-kafka/connect"
+	"github.com/go-kafka/connect/heal"
+	"github.com/go-kafka/connect/notify"
+)
+
+var (
+	healCmd *kingpin.CmdClause
+
+	healBackoff, healMaxBackoff, healResetAfter time.Duration
+	healMaxAttempts                             int
+	healExcludeNames, healExcludeCauses         []string
+	escalateCommand                             string
+)
+
+func configureHealCommand(app *kingpin.Application) {
+	healCmd = app.Command("heal", "Watches for failed tasks and restarts them, backing off and escalating if they keep failing.")
+	healCmd.Flag("interval", "How often to check statuses.").
+		Default(heal.DefaultInterval.String()).
+		DurationVar(&pollInterval)
+	healCmd.Flag("backoff", "Wait before restarting a task a second time, doubling after each restart.").
+		Default(heal.DefaultBackoff.String()).
+		DurationVar(&healBackoff)
+	healCmd.Flag("max-backoff", "Longest wait between restarts of a task.").
+		Default(heal.DefaultMaxBackoff.String()).
+		DurationVar(&healMaxBackoff)
+	healCmd.Flag("max-attempts", "Restarts of a task before giving up on it and escalating.").
+		Default(strconv.Itoa(heal.DefaultMaxAttempts)).
+		IntVar(&healMaxAttempts)
+	healCmd.Flag("reset-after", "How long a task must keep running for its restarts to start over.").
+		Default(heal.DefaultResetAfter.String()).
+		DurationVar(&healResetAfter)
+	healCmd.Flag("exclude", "Don't restart tasks of connectors matching this glob pattern. Repeatable.").
+		PlaceHolder("PATTERN").
+		StringsVar(&healExcludeNames)
+	healCmd.Flag("exclude-cause", "Don't restart tasks whose root cause matches this regular expression. Repeatable.").
+		PlaceHolder("REGEX").
+		StringsVar(&healExcludeCauses)
+	healCmd.Flag("on-escalate", "Shell command to run when giving up on a task, given the event as JSON on stdin.").
+		PlaceHolder("COMMAND").
+		StringVar(&escalateCommand)
+	healCmd.Flag("dry-run", "Log what would be done without restarting anything.").
+		BoolVar(&dryRun)
+
+	// Reset state for in-process tests
+	healBackoff, healMaxBackoff, healResetAfter, healMaxAttempts = 0, 0, 0, 0
+	healExcludeNames, healExcludeCauses, escalateCommand = nil, nil, ""
+}
+
+func runHealer(client *connect.Client) error {
+	healer := &heal.Healer{
+		Client:       client,
+		Interval:     pollInterval,
+		Backoff:      healBackoff,
+		MaxBackoff:   healMaxBackoff,
+		MaxAttempts:  healMaxAttempts,
+		ResetAfter:   healResetAfter,
+		ExcludeNames: healExcludeNames,
+		DryRun:       dryRun,
+		Log:          logHealEvent,
+	}
+	dispatcher, err := newDispatcher()
+	if err != nil {
+		return err
+	}
+	if dispatcher != nil {
+		healer.Log = func(e heal.Event) {
+			logHealEvent(e)
+			sendNotification(dispatcher, notify.FromHeal(e))
+		}
+	}
+	for _, pattern := range healExcludeCauses {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid --exclude-cause %q: %v", pattern, err)
+		}
+		healer.ExcludeCauses = append(healer.ExcludeCauses, re)
+	}
+	if escalateCommand != "" {
+		healer.Escalate = func(e heal.Event) {
+			if err := runEventCommand(escalateCommand, e); err != nil {
+				fmt.Fprintf(os.Stderr, "Escalation command failed: %v\n", err)
+			}
+		}
+	}
+
+	stop := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		close(stop)
+	}()
+
+	mode := ""
+	if dryRun {
+		mode = " (dry run)"
+	}
+	fmt.Fprintf(os.Stderr, "Healing failed tasks on %v every %v%v.\n", client.Host(), pollInterval, mode)
+	healer.Run(stop)
+	return nil
+}
+
+// Logs events a line each, as JSON with -o json for log shippers.
+func logHealEvent(e heal.Event) {
+	if outputSpec == outputJSON {
+		data, _ := json.Marshal(e)
+		fmt.Println(string(data))
+		return
+	}
+	fmt.Printf("%v %v\n", e.Time.Format(time.RFC3339), e)
+}
+
+// Runs a shell command for an event, with the event as JSON on stdin.
+func runEventCommand(command string, event interface{}) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command("/bin/sh", "-c", command)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+	return cmd.Run()
+}
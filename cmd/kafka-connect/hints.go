@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/go-kafka/connect/hints"
+)
+
+const hintsenv = "KAFKA_CONNECT_CLI_HINTS"
+
+var hintsPath string
+
+func configureHintsFlag(app *kingpin.Application) {
+	app.Flag("hints", "YAML file of remediation hints for known failures, used ahead of the built-in ones.").
+		Envar(hintsenv).
+		PlaceHolder("FILE").
+		StringVar(&hintsPath)
+
+	// Reset state for in-process tests
+	hintsPath = ""
+}
+
+// Loads the hint catalog, with rules from --hints if given.
+func loadHints() (*hints.Catalog, error) {
+	if hintsPath == "" {
+		return hints.Builtin(), nil
+	}
+	return hints.Load(hintsPath)
+}
+
+// Writes the remediation hint for a stack trace, if there is one, indented
+// under the failure it is for.
+func writeHint(buf *bytes.Buffer, catalog *hints.Catalog, stackTrace string) {
+	rule := catalog.MatchTrace(stackTrace)
+	if rule == nil {
+		return
+	}
+	fmt.Fprintf(buf, "  hint: %v\n", rule.Hint)
+	if rule.Link != "" {
+		fmt.Fprintf(buf, "  see: %v\n", rule.Link)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/go-kafka/connect"
+	"github.com/go-kafka/connect/properties"
+)
+
+// Supported formats for connector input.
+const (
+	formatJSON       = "json"
+	formatYAML       = "yaml"
+	formatProperties = "properties"
+)
+
+// Determines the format of source from --format, or else its file extension.
+func detectInputFormat(source string) string {
+	if inputFormat != "" {
+		return inputFormat
+	}
+
+	switch strings.ToLower(filepath.Ext(source)) {
+	case ".yaml", ".yml":
+		return formatYAML
+	case ".properties":
+		return formatProperties
+	default:
+		return formatJSON
+	}
+}
+
+// Attempts to unmarshal data from source into dst. This should be a pointer to
+// a Connector or ConnectorConfig expected to be found in source.
+func decodeConnectorConfig(source string, dst interface{}) error {
+	// TODO: should really buffer stdin just in case...
+	contents, err := ioutil.ReadFile(source)
+	if err != nil {
+		return err
+	}
+
+	if err := decodeConnectorData(contents, detectInputFormat(source), dst); err != nil {
+		return fmt.Errorf("input was not a valid connector configuration (%v): %v", source, err)
+	}
+	return nil
+}
+
+func decodeConnectorData(contents []byte, format string, dst interface{}) error {
+	switch format {
+	case formatProperties:
+		return decodeProperties(contents, dst)
+	case formatYAML:
+		return decodeYAML(contents, dst)
+	default:
+		return json.Unmarshal(contents, dst)
+	}
+}
+
+// Encodes a config for editing by hand in the given format.
+func encodeConnectorConfig(config connect.ConnectorConfig, format string) ([]byte, error) {
+	switch format {
+	case formatProperties:
+		return properties.Marshal(config)
+	case formatYAML:
+		return yaml.Marshal(map[string]string(config))
+	default:
+		data, err := json.MarshalIndent(config, "", "  ")
+		return append(data, '\n'), err
+	}
+}
+
+// A properties file is always a flat config, as in standalone mode, so when a
+// Connector is wanted its name is taken from config.
+func decodeProperties(contents []byte, dst interface{}) error {
+	config, err := properties.Unmarshal(contents)
+	if err != nil {
+		return err
+	}
+
+	switch dst := dst.(type) {
+	case *connect.ConnectorConfig:
+		*dst = config
+	case *connect.Connector:
+		*dst = connect.Connector{Name: config["name"], Config: config}
+	default:
+		return fmt.Errorf("cannot decode properties into %T", dst)
+	}
+	return nil
+}
+
+// YAML is friendlier to write by hand, but it types scalars where Connect
+// configs are all strings (tasks.max: 1), so we convert it to JSON with scalars
+// stringified and decode that.
+func decodeYAML(contents []byte, dst interface{}) error {
+	var doc interface{}
+	if err := yaml.Unmarshal(contents, &doc); err != nil {
+		return err
+	}
+
+	normalized, err := stringifyYAML(doc)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(normalized)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
+
+func stringifyYAML(node interface{}) (interface{}, error) {
+	switch node := node.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(node))
+		for k, v := range node {
+			value, err := stringifyYAML(v)
+			if err != nil {
+				return nil, err
+			}
+			result[fmt.Sprint(k)] = value
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(node))
+		for i, v := range node {
+			value, err := stringifyYAML(v)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = value
+		}
+		return result, nil
+	case nil:
+		return "", nil
+	default:
+		return fmt.Sprint(node), nil
+	}
+}
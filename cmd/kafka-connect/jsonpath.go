@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A jsonPathTemplate is a small subset of the JSONPath templates supported by
+// kubectl: literal text with {expressions} interpolated, where an expression is
+// a path of .field, ['field'], [index] or [*] steps from the result root.
+// Multiple matches are separated by spaces.
+//
+// Example: {.name} is {.connector.state} on {.tasks[*].worker_id}
+type jsonPathTemplate []jsonPathSegment
+
+type jsonPathSegment struct {
+	literal string
+	path    []jsonPathStep // nil for literal segments
+}
+
+type jsonPathStep struct {
+	field    string
+	index    int
+	wildcard bool
+	isIndex  bool
+}
+
+func parseJSONPathTemplate(text string) (jsonPathTemplate, error) {
+	var tmpl jsonPathTemplate
+
+	for text != "" {
+		open := strings.Index(text, "{")
+		if open < 0 {
+			tmpl = append(tmpl, jsonPathSegment{literal: text})
+			break
+		}
+		if open > 0 {
+			tmpl = append(tmpl, jsonPathSegment{literal: text[:open]})
+		}
+		end := strings.Index(text[open:], "}")
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed expression in %q", text)
+		}
+		path, err := parseJSONPath(text[open+1 : open+end])
+		if err != nil {
+			return nil, err
+		}
+		tmpl = append(tmpl, jsonPathSegment{path: path})
+		text = text[open+end+1:]
+	}
+
+	if len(tmpl) == 0 {
+		return nil, fmt.Errorf("empty template")
+	}
+	return tmpl, nil
+}
+
+func parseJSONPath(expr string) ([]jsonPathStep, error) {
+	expr = strings.TrimPrefix(strings.TrimSpace(expr), "$")
+	steps := []jsonPathStep{} // non-nil marks an expression, even for {.}
+
+	for expr != "" && expr != "." {
+		switch {
+		case strings.HasPrefix(expr, "["):
+			end := strings.Index(expr, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed [ in %q", expr)
+			}
+			inner := expr[1:end]
+			switch {
+			case inner == "*":
+				steps = append(steps, jsonPathStep{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				steps = append(steps, jsonPathStep{field: inner[1 : len(inner)-1]})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid index [%v]", inner)
+				}
+				steps = append(steps, jsonPathStep{index: index, isIndex: true})
+			}
+			expr = expr[end+1:]
+		case strings.HasPrefix(expr, "."):
+			expr = expr[1:]
+			end := strings.IndexAny(expr, ".[")
+			if end < 0 {
+				end = len(expr)
+			}
+			field := expr[:end]
+			if field == "" {
+				return nil, fmt.Errorf("empty field name")
+			}
+			if field == "*" {
+				steps = append(steps, jsonPathStep{wildcard: true})
+			} else {
+				steps = append(steps, jsonPathStep{field: field})
+			}
+			expr = expr[end:]
+		default:
+			return nil, fmt.Errorf("unexpected %q, expected . or [", expr)
+		}
+	}
+
+	return steps, nil
+}
+
+func (t jsonPathTemplate) Format(w io.Writer, v interface{}) error {
+	root, err := toGeneric(v)
+	if err != nil {
+		return err
+	}
+
+	for _, segment := range t {
+		if segment.path == nil {
+			if _, err := io.WriteString(w, segment.literal); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var values []string
+		for _, match := range evalJSONPath(segment.path, root) {
+			values = append(values, jsonPathString(match))
+		}
+		if _, err := io.WriteString(w, strings.Join(values, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func evalJSONPath(steps []jsonPathStep, root interface{}) []interface{} {
+	nodes := []interface{}{root}
+
+	for _, step := range steps {
+		var next []interface{}
+		for _, node := range nodes {
+			switch node := node.(type) {
+			case map[string]interface{}:
+				switch {
+				case step.wildcard:
+					keys := make([]string, 0, len(node))
+					for k := range node {
+						keys = append(keys, k)
+					}
+					sort.Strings(keys)
+					for _, k := range keys {
+						next = append(next, node[k])
+					}
+				case !step.isIndex:
+					if value, ok := node[step.field]; ok {
+						next = append(next, value)
+					}
+				}
+			case []interface{}:
+				switch {
+				case step.wildcard:
+					next = append(next, node...)
+				case step.isIndex:
+					i := step.index
+					if i < 0 {
+						i += len(node)
+					}
+					if i >= 0 && i < len(node) {
+						next = append(next, node[i])
+					}
+				}
+			}
+		}
+		nodes = next
+	}
+
+	return nodes
+}
+
+func jsonPathString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(v)
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
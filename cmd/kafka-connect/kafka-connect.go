@@ -1,16 +1,16 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
 
 	"gopkg.in/alecthomas/kingpin.v2"
 
 	"github.com/go-kafka/connect"
+	"github.com/go-kafka/connect/backup"
 )
 
 const (
@@ -45,13 +45,20 @@ var (
 	host     *url.URL
 	connName string
 
+	outputSpec string
+	output     outputFormatter
+
 	// For matching which execution we dispatch without proliferating strings
 	listCmd, createCmd, updateCmd, deleteCmd *kingpin.CmdClause
 	showCmd, configCmd, tasksCmd, statusCmd  *kingpin.CmdClause
 	pauseCmd, resumeCmd, restartCmd          *kingpin.CmdClause
-	versionCmd                               *kingpin.CmdClause
+	upsertCmd, versionCmd                    *kingpin.CmdClause
+	backupCmd, restoreCmd                    *kingpin.CmdClause
 
 	newConnectorFilePath, connectorConfigPath string
+	expectedConfigHash, inputFormat           string
+	backupPath, conflictPolicy                string
+	restartIncludeTasks, restartOnlyFailed    bool
 )
 
 func init() {
@@ -67,25 +74,32 @@ func BuildApp() *kingpin.Application {
 
 	app.HelpFlag.Short('h')
 
-	app.Flag("host", "Host address for the Kafka Connect REST API instance.").
+	app.Flag("host", "Host address for the Kafka Connect REST API instance, default "+
+		connect.DefaultHostURL+" unless set by a context or "+hostenv+".").
 		Short('H').
-		Default(connect.DefaultHostURL).
-		Envar(hostenv).
-		URLVar(&host)
+		PlaceHolder("URL").
+		StringVar(&hostFlag)
+
+	app.Flag("output", "Output format: json (default), yaml, table, properties, name, go-template=TEMPLATE or jsonpath=TEMPLATE.").
+		Short('o').
+		StringVar(&outputSpec)
 
 	// The modular style of Kingpin setup might cut down on the non-local vars,
 	// but it feels pretty heavy and less declarative, so I'm undecided...
 	listCmd = app.Command("list", "Lists active connectors. Aliased as 'ls'.").Alias("ls")
 	createCmd = app.Command("create", "Creates a new connector instance.")
 	updateCmd = app.Command("update", "Updates a connector.")
-	deleteCmd = app.Command("delete", "Deletes a connector. Aliased as 'rm'.").Alias("rm")
+	upsertCmd = app.Command("upsert", "Creates a connector, or updates it if it exists.")
+	deleteCmd = app.Command("delete", "Deletes connectors by name or selection. Aliased as 'rm'.").Alias("rm")
 	showCmd = app.Command("show", "Shows information about a connector and its tasks.")
 	configCmd = app.Command("config", "Displays configuration of a connector.")
 	tasksCmd = app.Command("tasks", "Displays tasks currently running for a connector.")
-	statusCmd = app.Command("status", "Gets current status of a connector.")
-	pauseCmd = app.Command("pause", "Pause a connector and its tasks.")
-	resumeCmd = app.Command("resume", "Resume a paused connector.")
-	restartCmd = app.Command("restart", "Restart a connector and its tasks.")
+	statusCmd = app.Command("status", "Gets current status of a connector, or an overview of all connectors.")
+	pauseCmd = app.Command("pause", "Pause connectors and their tasks.")
+	resumeCmd = app.Command("resume", "Resume paused connectors.")
+	restartCmd = app.Command("restart", "Restart connectors, and with --include-tasks their tasks.")
+	backupCmd = app.Command("backup", "Saves all connector definitions to a directory or archive.")
+	restoreCmd = app.Command("restore", "Recreates connectors from a backup.")
 	versionCmd = app.Command("version", "Shows kafka-connect version information.")
 
 	// TODO: New stuff
@@ -103,31 +117,91 @@ func BuildApp() *kingpin.Application {
 	}
 
 	addConnectorNameArg("create", "create", false)
-	hintedByName := []string{"update", "delete", "show", "pause", "resume", "restart"}
+	addConnectorNameArg("upsert", "create or update", false)
+	hintedByName := []string{"update", "show"}
 	for _, name := range hintedByName {
 		addConnectorNameArg(name, name, true)
 	}
-	for _, name := range []string{"config", "tasks", "status"} {
+	for _, name := range []string{"config", "tasks"} {
 		addConnectorNameArg(name, "look up", true)
 	}
+	addConnectorNameArg("status", "look up, default all", false)
+
+	createCmd.Flag("from-file", "A JSON or YAML file matching API request format, or properties file, including connector name.").
+		Short('f').
+		PlaceHolder("FILE").
+		ExistingFileVar(&newConnectorFilePath)
+	createCmd.Flag("config", "A JSON, YAML or properties file containing connector config.").
+		Short('c').
+		PlaceHolder("FILE").
+		ExistingFileVar(&connectorConfigPath)
 
-	createCmd.Flag("from-file", "A JSON file matching API request format, including connector name.").
+	upsertCmd.Flag("from-file", "A JSON or YAML file matching API request format, or properties file, including connector name.").
 		Short('f').
 		PlaceHolder("FILE").
 		ExistingFileVar(&newConnectorFilePath)
-	createCmd.Flag("config", "A JSON file containing connector config.").
+	upsertCmd.Flag("config", "A JSON, YAML or properties file containing connector config.").
 		Short('c').
 		PlaceHolder("FILE").
 		ExistingFileVar(&connectorConfigPath)
 
-	updateCmd.Flag("config", "A JSON file containing connector config.").
+	updateCmd.Flag("config", "A JSON, YAML or properties file containing connector config.").
 		Short('c').
 		PlaceHolder("FILE").
 		ExistingFileVar(&connectorConfigPath)
+	updateCmd.Flag("expect-hash", "Only update if the live config still has this hash, as shown by config.").
+		PlaceHolder("HASH").
+		StringVar(&expectedConfigHash)
+
+	for _, command := range []*kingpin.CmdClause{createCmd, updateCmd, upsertCmd} {
+		command.Flag("format", "Format of connector input, by default detected from file extension or else JSON.").
+			EnumVar(&inputFormat, formatJSON, formatYAML, formatProperties)
+	}
+
+	backupCmd.Flag("out", "Directory to write, or a file ending in .tar.gz for an archive.").
+		PlaceHolder("PATH").
+		Required().
+		StringVar(&backupPath)
+
+	restoreCmd.Flag("from", "Backup directory or .tar.gz archive to restore.").
+		Short('f').
+		PlaceHolder("PATH").
+		Required().
+		ExistingFileOrDirVar(&backupPath)
+	restoreCmd.Flag("on-conflict", "What to do with connectors that already exist: fail, skip or overwrite.").
+		Default(string(backup.FailOnConflict)).
+		EnumVar(&conflictPolicy, string(backup.FailOnConflict), string(backup.SkipExisting), string(backup.OverwriteExisting))
+
+	restartCmd.Flag("include-tasks", "Restart the connectors' tasks too. Requires Kafka 3.0 or later.").
+		BoolVar(&restartIncludeTasks)
+	restartCmd.Flag("only-failed", "With --include-tasks, only restart connector instances and tasks that have failed.").
+		BoolVar(&restartOnlyFailed)
+
+	configureContextCommands(app)
+	configureHintsFlag(app)
+	configureEditCommand(app)
+	configureSetCommands(app)
+	configureCloneCommands(app)
+	configureSelectors(deleteCmd, pauseCmd, resumeCmd, restartCmd)
+	configureRolloutCommands(app)
+	configureScaleCommand(app)
+	configureDrainCommands(app)
+	configureBalanceCommand(app)
+	configureFailuresCommand(app)
+	configureLintCommand(app)
+	configureDoctorCommand(app)
+	configureExporterCommand(app)
+	configureHealCommand(app)
+	configureServeCommand(app)
+	configureStatusFlags()
+	configureNotifyFlags(statusCmd, healCmd)
+	configureMigrateCommands(app)
 
 	// Re-initialize global state for in-process tests, yeah kinda gross
 	connName, newConnectorFilePath, connectorConfigPath = "", "", ""
-	host = nil
+	expectedConfigHash, inputFormat, backupPath, conflictPolicy = "", "", "", ""
+	restartIncludeTasks, restartOnlyFailed = false, false
+	host, output, outputSpec = nil, nil, ""
 
 	return app
 }
@@ -141,17 +215,120 @@ func ValidateArgs(app *kingpin.Application, argv []string) (subcommand string, e
 		return
 	}
 
-	if !(*host).IsAbs() {
-		msg := fmt.Sprintf("host %v is not a valid absolute URL", (*host).String())
-		if os.Getenv(hostenv) != "" {
-			msg += fmt.Sprintf(" (set by %v)", hostenv)
+	// Context management shouldn't be blocked by a broken current context
+	if strings.HasPrefix(subcommand, contextCmd.FullCommand()+" ") {
+		if cliConf, err = loadCLIConfig(configFilePath); err != nil {
+			err = ValidationError{err.Error(), false}
+		}
+		activeContextName = contextFlag
+		if activeContextName == "" {
+			activeContextName = cliConf.CurrentContext
 		}
-		err = ValidationError{msg, false}
+		return
+	}
+
+	if err = resolveTarget(); err != nil {
+		err = ValidationError{err.Error(), false}
+		return
+	}
+
+	if output, err = newOutputFormatter(outputSpec); err != nil {
+		err = ValidationError{err.Error(), false}
 		return
 	}
 
 	switch subcommand {
-	case createCmd.FullCommand():
+	case deleteCmd.FullCommand(), pauseCmd.FullCommand(), resumeCmd.FullCommand(), restartCmd.FullCommand():
+		if connectorSelector().IsEmpty() {
+			err = ValidationError{"a connector name or selection flag is required", true}
+			return
+		}
+		if restartOnlyFailed && !restartIncludeTasks {
+			err = ValidationError{"--only-failed requires --include-tasks", true}
+			return
+		}
+	case scaleCmd.FullCommand():
+		if scaleTasks < 1 {
+			err = ValidationError{"--tasks must be at least 1", false}
+			return
+		}
+	case balanceCmd.FullCommand(), doctorCmd.FullCommand():
+		if maxSkew < 1 {
+			err = ValidationError{"--max-skew must be at least 1", false}
+			return
+		}
+	case exporterCmd.FullCommand():
+		if pollInterval <= 0 {
+			err = ValidationError{"--interval must be positive", false}
+			return
+		}
+	case healCmd.FullCommand():
+		if pollInterval <= 0 {
+			err = ValidationError{"--interval must be positive", false}
+			return
+		}
+		if healMaxAttempts < 1 {
+			err = ValidationError{"--max-attempts must be at least 1", false}
+			return
+		}
+		if _, err = newDispatcher(); err != nil {
+			err = ValidationError{err.Error(), false}
+			return
+		}
+	case serveCmd.FullCommand():
+		if (serveTLSCertPath == "") != (serveTLSKeyPath == "") {
+			err = ValidationError{"--tls-cert and --tls-key must be given together", false}
+			return
+		}
+	case rollingRestartCmd.FullCommand():
+		if batchSize < 1 {
+			err = ValidationError{"--batch-size must be at least 1", false}
+			return
+		}
+		if maxFailureRate < 0 || maxFailureRate > 1 {
+			err = ValidationError{"--max-failure-rate must be between 0 and 1", false}
+			return
+		}
+	case statusCmd.FullCommand():
+		if connName != "" && (statusAll || statusFailedOnly) {
+			err = ValidationError{"--all and --failed cannot be used with a connector name", true}
+			return
+		}
+		if statusWatch && statusInterval <= 0 {
+			err = ValidationError{"--interval must be positive", false}
+			return
+		}
+		if notificationsEnabled() && !statusWatch {
+			err = ValidationError{"notifications need --watch", true}
+			return
+		}
+		if _, err = newDispatcher(); err != nil {
+			err = ValidationError{err.Error(), false}
+			return
+		}
+	case migrateCmd.FullCommand(), syncCmd.FullCommand():
+		switch {
+		case (targetHost == nil) == (targetContext == ""):
+			err = ValidationError{"exactly one of --to and --to-context is required", true}
+			return
+		case targetHost != nil && !targetHost.IsAbs():
+			err = ValidationError{fmt.Sprintf("target %v is not a valid absolute URL", targetHost), false}
+			return
+		}
+		if _, _, err = targetClient(); err != nil {
+			err = ValidationError{err.Error(), false}
+			return
+		}
+		if _, err = migrateOptions(); err != nil {
+			err = ValidationError{err.Error(), false}
+			return
+		}
+		// Only connectors synced earlier in the same run are known to be ours
+		if pruneTarget && syncOnce {
+			err = ValidationError{"--prune cannot be used with --once", true}
+			return
+		}
+	case createCmd.FullCommand(), upsertCmd.FullCommand():
 		if pipedinput && (newConnectorFilePath != "" || connectorConfigPath != "") {
 			err = ValidationError{"--from-file and --config cannot be used with input from stdin", false}
 			return
@@ -210,50 +387,174 @@ func main() {
 }
 
 func run(subcommand string) error {
-	client := connect.NewClient(host.String())
+	if strings.HasPrefix(subcommand, contextCmd.FullCommand()+" ") {
+		return runContextCommand(subcommand)
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
 
 	// Dispatch subcommands
 	switch subcommand {
 	case listCmd.FullCommand():
+		// A table of names alone isn't much use, show their state too
+		if _, ok := output.(tableFormatter); ok {
+			statuses, err := client.ListConnectorStatuses()
+			return maybePrintAPIResult(statuses, nil, err)
+		}
 		return maybePrintAPIResult(client.ListConnectors())
 
+	case lintCmd.FullCommand():
+		return lintConfigFiles()
+
 	case createCmd.FullCommand():
 		// TODO: verify/improve error output of 409 Conflict
 		return createConnector(connName, client)
 
+	case upsertCmd.FullCommand():
+		return upsertConnector(connName, client)
+
 	case updateCmd.FullCommand():
+		if err := confirmDestructive("update connector " + connName); err != nil {
+			return err
+		}
 		var config connect.ConnectorConfig
 		source := findInputSource()
 		if err := decodeConnectorConfig(source, &config); err != nil {
 			return err
 		}
+		if err := lintBeforeSubmit(config); err != nil {
+			return err
+		}
+		if expectedConfigHash != "" {
+			return maybePrintAPIResult(
+				client.UpdateConnectorConfigIfMatch(connName, config, expectedConfigHash))
+		}
 		return maybePrintAPIResult(client.UpdateConnectorConfig(connName, config))
 
+	case setCmd.FullCommand():
+		if !dryRun {
+			if err := confirmDestructive("change config of connector " + connName); err != nil {
+				return err
+			}
+		}
+		return patchConnector(connName, setSettings, nil, client)
+
+	case unsetCmd.FullCommand():
+		if !dryRun {
+			if err := confirmDestructive("change config of connector " + connName); err != nil {
+				return err
+			}
+		}
+		return patchConnector(connName, nil, unsetKeys, client)
+
+	case editCmd.FullCommand():
+		if err := confirmDestructive("edit connector " + connName); err != nil {
+			return err
+		}
+		return editConnector(connName, client)
+
 	case deleteCmd.FullCommand():
 		// TODO: verify error output of 409 Conflict
-		return affectConnector(connName, client.DeleteConnector, "Deleted")
+		return bulkAction("delete", "Deleted", client.DeleteConnector, client)
 
 	case showCmd.FullCommand():
 		return maybePrintAPIResult(client.GetConnector(connName))
 
 	case configCmd.FullCommand():
-		return maybePrintAPIResult(client.GetConnectorConfig(connName))
+		config, resp, err := client.GetConnectorConfig(connName)
+		if err == nil {
+			// On stderr so that output can still be piped back into update
+			fmt.Fprintf(os.Stderr, "Config hash: %v\n", config.Hash())
+		}
+		return maybePrintAPIResult(config, resp, err)
 
 	case tasksCmd.FullCommand():
 		return maybePrintAPIResult(client.GetConnectorTasks(connName))
 
 	case statusCmd.FullCommand():
-		return maybePrintAPIResult(client.GetConnectorStatus(connName))
+		return showStatus(connName, client)
 
 	case pauseCmd.FullCommand():
-		return affectConnector(connName, client.PauseConnector, "Paused")
+		return bulkAction("pause", "Paused", client.PauseConnector, client)
 
 	case resumeCmd.FullCommand():
-		return affectConnector(connName, client.ResumeConnector, "Resumed")
+		return bulkAction("resume", "Resumed", client.ResumeConnector, client)
 
 	case restartCmd.FullCommand():
 		// TODO: verify error output of 409 Conflict
-		return affectConnector(connName, client.RestartConnector, "Restarted")
+		restart := client.RestartConnector
+		if restartIncludeTasks {
+			restart = func(name string) (*http.Response, error) {
+				return client.RestartConnectorAndTasks(name, restartOnlyFailed)
+			}
+		}
+		return bulkAction("restart", "Restarted", restart, client)
+
+	case cloneCmd.FullCommand():
+		return cloneConnector(client)
+
+	case renameCmd.FullCommand():
+		return renameConnector(client)
+
+	case rollingRestartCmd.FullCommand():
+		return rollingRestart(client)
+
+	case scaleCmd.FullCommand():
+		if err := confirmDestructive("scale connector " + connName); err != nil {
+			return err
+		}
+		return scaleConnector(connName, client)
+
+	case workersCmd.FullCommand():
+		return listWorkers(client)
+
+	case drainCmd.FullCommand():
+		if err := confirmDestructive("drain worker " + workerID); err != nil {
+			return err
+		}
+		return drainWorker(client)
+
+	case undrainCmd.FullCommand():
+		if err := confirmDestructive("restore connector states from " + drainStatePath); err != nil {
+			return err
+		}
+		return undrainWorker(client)
+
+	case balanceCmd.FullCommand():
+		return showBalance(client)
+
+	case failuresCmd.FullCommand():
+		return showFailures(client)
+
+	case doctorCmd.FullCommand():
+		return runDoctor(client)
+
+	case exporterCmd.FullCommand():
+		return runExporter(client)
+
+	case healCmd.FullCommand():
+		return runHealer(client)
+
+	case serveCmd.FullCommand():
+		return runGateway()
+
+	case backupCmd.FullCommand():
+		return backupConnectors(backupPath, client)
+
+	case restoreCmd.FullCommand():
+		if err := confirmDestructive("restore connectors from " + backupPath); err != nil {
+			return err
+		}
+		return restoreConnectors(backupPath, backup.ConflictPolicy(conflictPolicy), client)
+
+	case migrateCmd.FullCommand():
+		return migrateConnectors(client)
+
+	case syncCmd.FullCommand():
+		return syncConnectors(client)
 
 	case versionCmd.FullCommand():
 		_, err := fmt.Println(versionString)
@@ -269,11 +570,7 @@ func maybePrintAPIResult(data interface{}, resp *http.Response, err error) error
 		return err
 	}
 
-	if output, err := formatPrettyJSON(data); err == nil {
-		fmt.Println(output)
-	}
-
-	return err
+	return printOutput(data)
 }
 
 func affectConnector(name string, action connectorAction, desc string) error {
@@ -285,8 +582,52 @@ func affectConnector(name string, action connectorAction, desc string) error {
 	return err
 }
 
-func createConnector(name string, client *connect.Client) (err error) {
-	var connector connect.Connector
+func createConnector(name string, client *connect.Client) error {
+	connector, err := readConnectorInput(name)
+	if err != nil {
+		return err
+	}
+	if err := lintBeforeSubmit(connector.Config); err != nil {
+		return err
+	}
+
+	if _, err = client.CreateConnector(&connector); err != nil {
+		return err
+	}
+
+	return printOutput(connector)
+}
+
+func upsertConnector(name string, client *connect.Client) error {
+	connector, err := readConnectorInput(name)
+	if err != nil {
+		return err
+	}
+	if err := lintBeforeSubmit(connector.Config); err != nil {
+		return err
+	}
+	if err := confirmDestructive("create or update connector " + connector.Name); err != nil {
+		return err
+	}
+
+	created, _, err := client.UpsertConnector(&connector)
+	if err != nil {
+		return err
+	}
+
+	// Report on stderr so that JSON output stays clean for scripting
+	desc := "Updated"
+	if created {
+		desc = "Created"
+	}
+	fmt.Fprintf(os.Stderr, "%v connector %v.\n", desc, connector.Name)
+
+	return printOutput(connector)
+}
+
+// Reads a Connector from the input source given for create or upsert, either a
+// full Connector or a ConnectorConfig combined with the given name.
+func readConnectorInput(name string) (connector connect.Connector, err error) {
 	source := findInputSource()
 
 	if source == newConnectorFilePath || name == "" {
@@ -310,7 +651,8 @@ func createConnector(name string, client *connect.Client) (err error) {
 	// create). The API handles bad input poorly (500s instead of 422), so try
 	// to give the user a better error than HTTP does.
 	if connector.Config == nil {
-		return fmt.Errorf("input was not a valid connector (%v)", source)
+		err = fmt.Errorf("input was not a valid connector (%v)", source)
+		return
 	}
 
 	// The API dubiously allows creating connectors with blank names... That's
@@ -323,12 +665,6 @@ func createConnector(name string, client *connect.Client) (err error) {
 		connector.Name = connector.Config["name"]
 	}
 
-	if _, err = client.CreateConnector(&connector); err == nil {
-		if output, err := formatPrettyJSON(connector); err == nil {
-			fmt.Println(output)
-		}
-	}
-
 	return
 }
 
@@ -346,30 +682,6 @@ func findInputSource() (path string) {
 	return
 }
 
-// Attempts to unmarshal data from source into dst. This should be a pointer to
-// a Connector or ConnectorConfig expected to be found in source.
-func decodeConnectorConfig(source string, dst interface{}) error {
-	// TODO: should really buffer stdin just in case...
-	contents, err := ioutil.ReadFile(source)
-	if err != nil {
-		return err
-	}
-	if err := json.Unmarshal(contents, dst); err != nil {
-		return fmt.Errorf("input was not a valid connector configuration (%v)", source)
-	}
-
-	return nil
-}
-
-// TODO: Some kind of formatter abstraction
-func formatPrettyJSON(v interface{}) (string, error) {
-	pretty, err := json.MarshalIndent(v, "", "  ")
-	if err != nil {
-		return "", err
-	}
-	return string(pretty), nil
-}
-
 // TODO: This probably doesn't work on Windows.
 // https://github.com/mattn/go-isatty
 func isatty(file *os.File) bool {
@@ -2,13 +2,17 @@ package main_test
 
 import (
 	"io/ioutil"
+	"net"
+	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	. "github.com/onsi/gomega/gbytes"
 	. "github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
 	"gopkg.in/alecthomas/kingpin.v2"
 
 	. "github.com/go-kafka/connect/cmd/kafka-connect"
@@ -35,6 +39,1262 @@ var _ = Describe("kafka-connect CLI", func() {
 	})
 })
 
+var _ = Describe("Connector input formats", func() {
+	var server *ghttp.Server
+	var tmpdir string
+
+	writeInput := func(name, contents string) string {
+		path := filepath.Join(tmpdir, name)
+		Expect(ioutil.WriteFile(path, []byte(contents), 0644)).To(Succeed())
+		return path
+	}
+
+	update := func(args ...string) *Session {
+		argv := append([]string{"--host", server.URL(), "update", "a-name"}, args...)
+		session, err := Start(exec.Command(pathToCLI, argv...), GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		return session
+	}
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		tmpdir, _ = ioutil.TempDir("", "connector-input")
+
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("PUT", "/connectors/a-name/config"),
+				ghttp.VerifyJSON(`{"connector.class":"FileStreamSink","tasks.max":"4","topics":"a,b"}`),
+				ghttp.RespondWith(http.StatusOK, `{"name":"a-name"}`),
+			),
+		)
+	})
+
+	AfterEach(func() {
+		server.Close()
+		_ = os.RemoveAll(tmpdir)
+	})
+
+	It("accepts .properties files", func() {
+		path := writeInput("sink.properties", "connector.class=FileStreamSink\ntasks.max=4\ntopics=a,\\\n  b\n")
+		Eventually(update("--config", path)).Should(Exit(0))
+	})
+
+	It("accepts .yaml files", func() {
+		path := writeInput("sink.yaml", "connector.class: FileStreamSink\ntasks.max: 4\ntopics: a,b\n")
+		Eventually(update("--config", path)).Should(Exit(0))
+	})
+
+	It("honors --format over the file extension", func() {
+		path := writeInput("sink.txt", "connector.class: FileStreamSink\ntasks.max: 4\ntopics: a,b\n")
+		Eventually(update("--config", path, "--format", "yaml")).Should(Exit(0))
+	})
+})
+
+var _ = Describe("Output formats", func() {
+	var server *ghttp.Server
+
+	run := func(args ...string) *Session {
+		argv := append([]string{"--host", server.URL()}, args...)
+		session, err := Start(exec.Command(pathToCLI, argv...), nil, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(Exit(0))
+		return session
+	}
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		server.RouteToHandler("GET", "/connectors/a/config", ghttp.RespondWith(http.StatusOK,
+			`{"name":"a","tasks.max":"2"}`))
+		server.RouteToHandler("GET", "/connectors/a/status", ghttp.RespondWith(http.StatusOK,
+			`{"name":"a","connector":{"state":"RUNNING","worker_id":"w1:8083"},
+			  "tasks":[{"id":0,"state":"RUNNING","worker_id":"w1:8083"},
+			           {"id":1,"state":"FAILED","worker_id":"w2:8083","trace":"java.lang.RuntimeException: boom\n\tat Foo.bar"}]}`))
+		server.RouteToHandler("GET", "/connectors/b/status", ghttp.RespondWith(http.StatusOK,
+			`{"name":"b","connector":{"state":"PAUSED","worker_id":"w2:8083"},"tasks":[]}`))
+		server.RouteToHandler("GET", "/connectors/c/status", ghttp.RespondWith(http.StatusOK,
+			`{"name":"c","connector":{"state":"RUNNING","worker_id":"w1:8083"},
+			  "tasks":[{"id":0,"state":"FAILED","worker_id":"w1:8083","trace":"org.apache.kafka.connect.errors.ConnectException: Exiting WorkerSinkTask\n\tat org.apache.kafka.connect.runtime.WorkerSinkTask.deliverMessages(WorkerSinkTask.java:614)\nCaused by: java.sql.SQLException: Connection refused\n\tat org.postgresql.Driver.connect(Driver.java:285)\n\t... 12 more"}]}`))
+		server.RouteToHandler("GET", "/connectors", ghttp.RespondWith(http.StatusOK, `["a","b"]`))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("prints config as properties", func() {
+		Expect(run("-o", "properties", "config", "a").Out.Contents()).To(Equal([]byte("name=a\ntasks.max=2\n")))
+	})
+
+	It("prints config as YAML", func() {
+		Expect(run("-o", "yaml", "config", "a").Out.Contents()).To(Equal([]byte("name: a\ntasks.max: \"2\"\n")))
+	})
+
+	It("prints status as a table", func() {
+		session := run("-o", "table", "status", "a")
+		Expect(session).To(Say(`NAME\s+TYPE\s+ID\s+STATE\s+WORKER`))
+		Expect(session).To(Say(`a\s+task\s+1\s+FAILED\s+w2:8083`))
+	})
+
+	It("prints list as a table with states", func() {
+		session := run("-o", "table", "list")
+		Expect(session).To(Say(`a\s+RUNNING\s+1 FAILED, 1 RUNNING\s+w1:8083`))
+	})
+
+	It("prints an overview table of all connectors for status without a name", func() {
+		session := run("status")
+		Expect(session).To(Say(`NAME\s+STATE\s+TASKS\s+WORKER`))
+		Expect(session).To(Say(`a\s+RUNNING\s+1 FAILED, 1 RUNNING\s+w1:8083`))
+		Expect(session).To(Say(`b\s+PAUSED\s+-\s+w2:8083`))
+	})
+
+	It("shows only unhealthy connectors and trace summaries with status --failed", func() {
+		session := run("status", "--failed")
+		Expect(session.Out.Contents()).NotTo(ContainSubstring("PAUSED"))
+		Expect(session).To(Say(`a task 1 on w2:8083: java.lang.RuntimeException: boom\n`))
+	})
+
+	It("prints status of a connector as JSON by default", func() {
+		session := run("status", "c")
+		Expect(session).To(Say(`^{\n  "name": "c",\n`))
+	})
+
+	It("shows the root cause of failed tasks for status of a connector", func() {
+		session := run("-o", "table", "status", "c")
+		Expect(session).To(Say(`c\s+task\s+0\s+FAILED\s+w1:8083`))
+		Expect(session).To(Say(`c task 0 on w1:8083: java.sql.SQLException: Connection refused\n`))
+		Expect(session.Out.Contents()).NotTo(ContainSubstring("WorkerSinkTask"))
+	})
+
+	It("shows full stack traces with status --trace", func() {
+		session := run("-o", "table", "status", "c", "--trace")
+		Expect(session).To(Say(`c task 0 on w1:8083:\n`))
+		Expect(session).To(Say(`ConnectException: Exiting WorkerSinkTask\n\tat .*WorkerSinkTask.java:614\)\n`))
+		Expect(session).To(Say(`Caused by: java.sql.SQLException: Connection refused\n`))
+	})
+
+	It("evaluates jsonpath templates", func() {
+		session := run("-o", "jsonpath={.name}: {.tasks[*].state}", "status", "a")
+		Expect(session.Out.Contents()).To(Equal([]byte("a: RUNNING FAILED\n")))
+	})
+
+	It("evaluates go templates", func() {
+		session := run("-o", "go-template={{range .tasks}}{{.worker_id}} {{end}}", "status", "a")
+		Expect(session.Out.Contents()).To(Equal([]byte("w1:8083 w2:8083 \n")))
+	})
+})
+
+var _ = Describe("Cluster contexts", func() {
+	var server *ghttp.Server
+	var tmpdir, configFile string
+
+	run := func(args ...string) *Session {
+		argv := append([]string{"--config-file", configFile}, args...)
+		command := exec.Command(pathToCLI, argv...)
+		command.Env = []string{"PATH=" + os.Getenv("PATH")} // No KAFKA_CONNECT_CLI_HOST
+		session, err := Start(command, nil, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		return session
+	}
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		tmpdir, _ = ioutil.TempDir("", "cli-config")
+		configFile = filepath.Join(tmpdir, "config.yaml")
+
+		config := `
+current-context: dev
+contexts:
+  dev:
+    hosts: [http://127.0.0.1:1/, ` + server.URL() + `]
+    username: admin
+    password: secret
+    output: name
+  prod:
+    hosts: [http://prod.invalid:8083/]
+    confirm-destructive: true
+`
+		Expect(ioutil.WriteFile(configFile, []byte(config), 0600)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		server.Close()
+		_ = os.RemoveAll(tmpdir)
+	})
+
+	It("uses the current context's hosts, credentials and output format, failing over", func() {
+		server.AppendHandlers(ghttp.CombineHandlers(
+			ghttp.VerifyRequest("GET", "/connectors"),
+			ghttp.VerifyBasicAuth("admin", "secret"),
+			ghttp.RespondWith(http.StatusOK, `["a","b"]`),
+		))
+		session := run("list")
+		Eventually(session).Should(Exit(0))
+		Expect(session.Out.Contents()).To(Equal([]byte("a\nb\n")))
+	})
+
+	It("lets --host override a context's hosts", func() {
+		server.AppendHandlers(ghttp.CombineHandlers(
+			ghttp.VerifyRequest("GET", "/connectors"),
+			ghttp.RespondWith(http.StatusOK, `[]`),
+		))
+		Eventually(run("--context", "prod", "--host", server.URL(), "list")).Should(Exit(0))
+	})
+
+	It("lists contexts", func() {
+		session := run("context", "list")
+		Eventually(session).Should(Exit(0))
+		Expect(session).To(Say(`\*\s+dev`))
+	})
+
+	It("switches the current context", func() {
+		Eventually(run("context", "use", "prod")).Should(Exit(0))
+		session := run("context", "show")
+		Eventually(session).Should(Exit(0))
+		Expect(session).To(Say("prod:"))
+	})
+
+	It("masks credentials when showing a context", func() {
+		session := run("context", "show", "dev")
+		Eventually(session).Should(Exit(0))
+		Expect(session.Out.Contents()).NotTo(ContainSubstring("secret"))
+	})
+
+	It("requires confirmation for destructive commands where configured", func() {
+		session := run("--context", "prod", "delete", "a")
+		Eventually(session).Should(Exit(1))
+		Expect(session.Err).To(Say("aborted"))
+	})
+
+	It("requires confirmation for commands that overwrite config where configured", func() {
+		for _, args := range [][]string{
+			{"set", "a", "tasks.max=2"},
+			{"unset", "a", "topics"},
+			{"scale", "a", "--tasks", "2"},
+		} {
+			session := run(append([]string{"--context", "prod"}, args...)...)
+			Eventually(session).Should(Exit(1))
+			Expect(session.Err).To(Say("Context prod: really .* connector a\\?"))
+			Expect(session.Err).To(Say("aborted"))
+		}
+	})
+
+	It("migrates to another context's cluster with its credentials", func() {
+		source := ghttp.NewServer()
+		defer source.Close()
+		source.AppendHandlers(
+			ghttp.RespondWith(http.StatusOK, `["a"]`),
+			ghttp.RespondWith(http.StatusOK, `{"name":"a","topics":"orders"}`),
+		)
+		server.AppendHandlers(ghttp.CombineHandlers(
+			ghttp.VerifyRequest("PUT", "/connectors/a/config"),
+			ghttp.VerifyBasicAuth("admin", "secret"),
+			ghttp.RespondWith(http.StatusCreated, `{"name":"a"}`),
+		))
+
+		session := run("--host", source.URL(), "migrate", "a", "--to-context", "dev")
+		Eventually(session).Should(Exit(0))
+		Expect(session).To(Say("a: created"))
+	})
+
+	It("requires confirmation to migrate into a context where configured", func() {
+		source := ghttp.NewServer()
+		defer source.Close()
+
+		session := run("--host", source.URL(), "migrate", "a", "--to-context", "prod")
+		Eventually(session).Should(Exit(1))
+		Expect(session.Err).To(Say("Context prod: really create or overwrite connectors"))
+		Expect(session.Err).To(Say("aborted"))
+		Expect(source.ReceivedRequests()).To(BeEmpty())
+	})
+
+	It("rejects unknown contexts", func() {
+		session := run("--context", "nope", "list")
+		Eventually(session).Should(Exit(1))
+		Expect(session.Err).To(Say(`context "nope" is not defined`))
+	})
+})
+
+var _ = Describe("Editing connectors", func() {
+	var server *ghttp.Server
+	var tmpdir, editor string
+
+	edit := func(args ...string) *Session {
+		argv := append([]string{"--host", server.URL(), "edit", "a", "--yes"}, args...)
+		command := exec.Command(pathToCLI, argv...)
+		command.Env = append(os.Environ(), "VISUAL=", "EDITOR="+editor)
+		session, err := Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		return session
+	}
+
+	validationResult := func(errors string) string {
+		return `{"name":"FileStreamSink","error_count":0,"configs":[
+			{"value":{"name":"tasks.max","errors":[` + errors + `]}}]}`
+	}
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		tmpdir, _ = ioutil.TempDir("", "edit")
+		editor = filepath.Join(tmpdir, "editor.sh")
+
+		// Breaks the config on first edit, and fixes it when told it's invalid
+		script := `#!/bin/sh
+if grep -q "The config is invalid" "$1"; then
+	sed -i 's/"bad"/"2"/' "$1"
+else
+	sed -i 's/"1"/"bad"/' "$1"
+fi
+`
+		Expect(ioutil.WriteFile(editor, []byte(script), 0755)).To(Succeed())
+
+		original := `{"connector.class":"FileStreamSink","tasks.max":"1"}`
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/connectors/a/config"),
+				ghttp.RespondWith(http.StatusOK, original),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("PUT", "/connector-plugins/FileStreamSink/config/validate"),
+				ghttp.VerifyJSON(`{"connector.class":"FileStreamSink","tasks.max":"bad"}`),
+				ghttp.RespondWith(http.StatusOK, validationResult(`"Not a number"`)),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("PUT", "/connector-plugins/FileStreamSink/config/validate"),
+				ghttp.VerifyJSON(`{"connector.class":"FileStreamSink","tasks.max":"2"}`),
+				ghttp.RespondWith(http.StatusOK, validationResult("")),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/connectors/a/config"),
+				ghttp.RespondWith(http.StatusOK, original),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("PUT", "/connectors/a/config"),
+				ghttp.VerifyJSON(`{"connector.class":"FileStreamSink","tasks.max":"2"}`),
+				ghttp.RespondWith(http.StatusOK, `{"name":"a"}`),
+			),
+		)
+	})
+
+	AfterEach(func() {
+		server.Close()
+		_ = os.RemoveAll(tmpdir)
+	})
+
+	It("reopens the editor until the config is valid, then shows a diff and updates", func() {
+		session := edit()
+		Eventually(session).Should(Exit(0))
+		Expect(session).To(Say(`- tasks.max=1\n\+ tasks.max=2\n`))
+		Expect(session).To(Say("Updated connector a."))
+		Expect(server.ReceivedRequests()).To(HaveLen(5))
+	})
+})
+
+var _ = Describe("Setting config values", func() {
+	var server *ghttp.Server
+
+	run := func(args ...string) *Session {
+		argv := append([]string{"--host", server.URL()}, args...)
+		session, err := Start(exec.Command(pathToCLI, argv...), GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		return session
+	}
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		server.AppendHandlers(ghttp.CombineHandlers(
+			ghttp.VerifyRequest("GET", "/connectors/a/config"),
+			ghttp.RespondWith(http.StatusOK, `{"tasks.max":"1","errors.tolerance":"none","transforms.foo.type":"X"}`),
+		))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("shows a diff without changing anything with --dry-run", func() {
+		session := run("set", "a", "tasks.max=4", "errors.tolerance=all", "--dry-run")
+		Eventually(session).Should(Exit(0))
+		Expect(session).To(Say(`- errors.tolerance=none\n\+ errors.tolerance=all\n- tasks.max=1\n\+ tasks.max=4\n`))
+		Expect(server.ReceivedRequests()).To(HaveLen(1))
+	})
+
+	It("refuses to change a config that no longer has the expected hash", func() {
+		session := run("unset", "a", "transforms.foo.type", "--expect-hash", "stale")
+		Eventually(session).Should(Exit(1))
+		Expect(session.Err).To(Say("modified concurrently"))
+	})
+
+	It("removes keys", func() {
+		server.AppendHandlers(
+			ghttp.RespondWith(http.StatusOK, `{"tasks.max":"1","errors.tolerance":"none","transforms.foo.type":"X"}`),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("PUT", "/connectors/a/config"),
+				ghttp.VerifyJSON(`{"tasks.max":"1","errors.tolerance":"none"}`),
+				ghttp.RespondWith(http.StatusOK, `{"name":"a"}`),
+			),
+		)
+		session := run("unset", "a", "transforms.foo.type")
+		Eventually(session).Should(Exit(0))
+		Expect(session).To(Say("Updated connector a."))
+	})
+})
+
+var _ = Describe("Cloning and renaming", func() {
+	var server *ghttp.Server
+
+	run := func(args ...string) *Session {
+		argv := append([]string{"--host", server.URL()}, args...)
+		session, err := Start(exec.Command(pathToCLI, argv...), GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		return session
+	}
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		server.AppendHandlers(ghttp.CombineHandlers(
+			ghttp.VerifyRequest("GET", "/connectors/a/config"),
+			ghttp.RespondWith(http.StatusOK, `{"name":"a","tasks.max":"1"}`),
+		))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("clones a connector with overrides", func() {
+		server.AppendHandlers(ghttp.CombineHandlers(
+			ghttp.VerifyRequest("POST", "/connectors"),
+			ghttp.VerifyJSON(`{"name":"b","config":{"name":"b","tasks.max":"4"}}`),
+			ghttp.RespondWith(http.StatusCreated, `{"name":"b","config":{"name":"b","tasks.max":"4"},"tasks":[]}`),
+		))
+		session := run("clone", "a", "b", "--set", "tasks.max=4")
+		Eventually(session).Should(Exit(0))
+		Expect(session.Err).To(Say("Cloned connector a to b."))
+	})
+
+	It("refuses to rename onto an existing connector", func() {
+		server.AppendHandlers(
+			ghttp.RespondWith(http.StatusOK, `{"name":"a","connector":{"state":"RUNNING"},"tasks":[]}`),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/connectors/b"),
+				ghttp.RespondWith(http.StatusOK, `{"name":"b","config":{},"tasks":[]}`),
+			),
+		)
+		session := run("rename", "a", "b")
+		Eventually(session).Should(Exit(1))
+		Expect(session.Err).To(Say("renaming a to b failed: connector b already exists"))
+		Expect(server.ReceivedRequests()).To(HaveLen(3))
+	})
+})
+
+var _ = Describe("Acting on selected connectors", func() {
+	var server *ghttp.Server
+
+	run := func(args ...string) *Session {
+		argv := append([]string{"--host", server.URL()}, args...)
+		session, err := Start(exec.Command(pathToCLI, argv...), GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		return session
+	}
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		server.RouteToHandler("GET", "/connectors",
+			ghttp.RespondWith(http.StatusOK, `["orders-sink","payments-sink","orders-source"]`))
+		server.RouteToHandler("GET", "/connectors/orders-sink/status",
+			ghttp.RespondWith(http.StatusOK, `{"name":"orders-sink","connector":{"state":"RUNNING"},"tasks":[{"id":0,"state":"FAILED"}]}`))
+		server.RouteToHandler("GET", "/connectors/payments-sink/status",
+			ghttp.RespondWith(http.StatusOK, `{"name":"payments-sink","connector":{"state":"FAILED"},"tasks":[]}`))
+		server.RouteToHandler("GET", "/connectors/orders-source/status",
+			ghttp.RespondWith(http.StatusOK, `{"name":"orders-source","connector":{"state":"RUNNING"},"tasks":[]}`))
+		server.RouteToHandler("POST", "/connectors/orders-sink/restart", ghttp.RespondWith(http.StatusNoContent, nil))
+		server.RouteToHandler("POST", "/connectors/payments-sink/restart",
+			ghttp.RespondWith(http.StatusConflict, `{"error_code":409,"message":"rebalance in progress"}`))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("previews selected connectors with --dry-run", func() {
+		session := run("pause", "*-sink", "--dry-run")
+		Eventually(session).Should(Exit(0))
+		Expect(session.Err).To(Say(`Selected 2 connectors to pause:\n  orders-sink\n  payments-sink\n`))
+		Expect(server.ReceivedRequests()).To(HaveLen(1))
+	})
+
+	It("previews a single named connector with --dry-run", func() {
+		session := run("delete", "orders-sink", "--dry-run")
+		Eventually(session).Should(Exit(0))
+		Expect(session.Err).To(Say(`Would delete connector orders-sink.\n`))
+		Expect(server.ReceivedRequests()).To(BeEmpty())
+	})
+
+	It("acts on each selected connector and summarizes", func() {
+		session := run("restart", "--state", "FAILED", "--regex=-sink$")
+		Eventually(session).Should(Exit(1))
+		Expect(session).To(Say("orders-sink: restarted"))
+		Expect(session).To(Say("payments-sink: failed: rebalance in progress"))
+		Expect(session).To(Say("1 restarted, 1 failed."))
+	})
+
+	It("restarts only the connector instance by default", func() {
+		server.RouteToHandler("POST", "/connectors/orders-source/restart", ghttp.CombineHandlers(
+			ghttp.VerifyRequest("POST", "/connectors/orders-source/restart", ""),
+			ghttp.RespondWith(http.StatusNoContent, nil),
+		))
+		session := run("restart", "orders-source")
+		Eventually(session).Should(Exit(0))
+	})
+
+	It("restarts failed tasks with --include-tasks --only-failed", func() {
+		server.RouteToHandler("POST", "/connectors/orders-sink/restart", ghttp.CombineHandlers(
+			ghttp.VerifyRequest("POST", "/connectors/orders-sink/restart", "includeTasks=true&onlyFailed=true"),
+			ghttp.RespondWith(http.StatusNoContent, nil),
+		))
+		session := run("restart", "orders-sink", "--include-tasks", "--only-failed")
+		Eventually(session).Should(Exit(0))
+		Expect(session).To(Say("Restarted connector orders-sink."))
+	})
+
+	It("requires confirmation above the threshold", func() {
+		session := run("restart", "--state", "FAILED", "--confirm-over", "1")
+		Eventually(session).Should(Exit(1))
+		Expect(session.Err).To(Say(`Really restart 2 connectors\?`))
+	})
+})
+
+var _ = Describe("Rolling restarts", func() {
+	var server *ghttp.Server
+	var dir, progressPath string
+
+	run := func(args ...string) *Session {
+		argv := append([]string{"--host", server.URL(), "rolling-restart", "--progress", progressPath}, args...)
+		session, err := Start(exec.Command(pathToCLI, argv...), GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		return session
+	}
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		var err error
+		dir, err = ioutil.TempDir("", "rolling-restart")
+		Expect(err).NotTo(HaveOccurred())
+		progressPath = filepath.Join(dir, "progress.json")
+
+		for _, name := range []string{"a", "b"} {
+			server.RouteToHandler("POST", "/connectors/"+name+"/restart", ghttp.RespondWith(http.StatusAccepted, nil))
+			server.RouteToHandler("GET", "/connectors/"+name+"/status",
+				ghttp.RespondWith(http.StatusOK, `{"name":"`+name+`","connector":{"state":"RUNNING"},"tasks":[]}`))
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(dir)
+	})
+
+	It("restarts selected connectors and removes the progress file when done", func() {
+		server.RouteToHandler("GET", "/connectors", ghttp.RespondWith(http.StatusOK, `["a","b"]`))
+		session := run("--batch-size", "1")
+		Eventually(session).Should(Exit(0))
+		Expect(session).To(Say("a: restarted\nb: restarted\nRestarted 2 connectors, 0 failed to recover."))
+		Expect(progressPath).NotTo(BeAnExistingFile())
+	})
+
+	It("succeeds without a progress file when nothing is selected", func() {
+		server.RouteToHandler("GET", "/connectors", ghttp.RespondWith(http.StatusOK, `[]`))
+		session := run()
+		Eventually(session).Should(Exit(0))
+		Expect(session.Err).To(Say("No connectors to restart."))
+	})
+
+	It("skips paused connectors", func() {
+		server.RouteToHandler("GET", "/connectors", ghttp.RespondWith(http.StatusOK, `["a","b"]`))
+		server.RouteToHandler("GET", "/connectors/b/status",
+			ghttp.RespondWith(http.StatusOK, `{"name":"b","connector":{"state":"PAUSED"},"tasks":[]}`))
+		session := run("--batch-size", "1")
+		Eventually(session).Should(Exit(0))
+		Expect(session).To(Say("a: restarted\nb: skipped, paused or stopped\nRestarted 1 connectors, 0 failed to recover, 1 skipped."))
+	})
+
+	It("resumes from a progress file", func() {
+		Expect(ioutil.WriteFile(progressPath, []byte(`{"connectors":["a","b"],"restarted":["a"]}`), 0644)).To(Succeed())
+		session := run()
+		Eventually(session).Should(Exit(0))
+		Expect(session.Err).To(Say("Resuming rollout from .*, 1 of 2 connectors restarted."))
+		Expect(session).To(Say("Restarted 2 connectors"))
+		Expect(server.ReceivedRequests()).To(HaveLen(3))
+	})
+})
+
+var _ = Describe("Scaling connectors", func() {
+	var server *ghttp.Server
+
+	run := func(args ...string) *Session {
+		argv := append([]string{"--host", server.URL(), "scale", "orders-sink"}, args...)
+		session, err := Start(exec.Command(pathToCLI, argv...), GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		return session
+	}
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		server.RouteToHandler("GET", "/connectors/orders-sink/config",
+			ghttp.RespondWith(http.StatusOK, `{"topics":"orders","tasks.max":"1"}`))
+		server.RouteToHandler("GET", "/connectors/orders-sink/offsets", ghttp.RespondWith(http.StatusOK, `{"offsets":[
+			{"partition":{"kafka_topic":"orders","kafka_partition":0},"offset":{"kafka_offset":5}},
+			{"partition":{"kafka_topic":"orders","kafka_partition":1},"offset":{"kafka_offset":7}},
+			{"partition":{"kafka_topic":"old-orders","kafka_partition":0},"offset":{"kafka_offset":9}}
+		]}`))
+		server.RouteToHandler("GET", "/connectors/orders-sink/topics",
+			ghttp.RespondWith(http.StatusOK, `{"orders-sink":{"topics":["orders"]}}`))
+		server.RouteToHandler("PUT", "/connectors/orders-sink/config",
+			ghttp.RespondWith(http.StatusOK, `{"name":"orders-sink"}`))
+		server.RouteToHandler("GET", "/connectors/orders-sink/tasks", ghttp.RespondWith(http.StatusOK,
+			`[{"id":{"connector":"orders-sink","task":0}},{"id":{"connector":"orders-sink","task":1}}]`))
+		server.RouteToHandler("GET", "/connectors/orders-sink/status", ghttp.RespondWith(http.StatusOK,
+			`{"name":"orders-sink","connector":{"state":"RUNNING"},"tasks":[
+				{"id":0,"state":"RUNNING","worker_id":"w1:8083"},{"id":1,"state":"RUNNING","worker_id":"w2:8083"}]}`))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("scales and reports tasks per worker", func() {
+		session := run("--tasks", "2")
+		Eventually(session).Should(Exit(0))
+		Expect(session).To(Say("Scaled connector orders-sink to 2 tasks."))
+		Expect(session).To(Say(`WORKER\s+TASKS\nw1:8083\s+1\nw2:8083\s+1\n`))
+	})
+
+	It("refuses to scale a sink beyond its active partitions", func() {
+		session := run("--tasks", "3")
+		Eventually(session).Should(Exit(1))
+		Expect(session.Err).To(Say("orders-sink has committed offsets for 2 partitions.*--partitions"))
+	})
+
+	It("trusts --partitions over committed offsets", func() {
+		session := run("--tasks", "3", "--partitions", "4", "--timeout", "1ms")
+		Eventually(session).Should(Exit(0))
+		Expect(session.Err).To(Say("orders-sink is running 2 tasks after 1ms, not 3."))
+	})
+})
+
+var _ = Describe("Draining workers", func() {
+	var server *ghttp.Server
+	var dir, statePath string
+
+	run := func(args ...string) *Session {
+		argv := append([]string{"--host", server.URL()}, args...)
+		session, err := Start(exec.Command(pathToCLI, argv...), GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		return session
+	}
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		var err error
+		dir, err = ioutil.TempDir("", "drain")
+		Expect(err).NotTo(HaveOccurred())
+		statePath = filepath.Join(dir, "drain.json")
+
+		server.RouteToHandler("GET", "/connectors", ghttp.RespondWith(http.StatusOK, `["a","b","c"]`))
+		server.RouteToHandler("GET", "/connectors/a/status", ghttp.RespondWith(http.StatusOK,
+			`{"name":"a","connector":{"state":"RUNNING","worker_id":"w1:8083"},"tasks":[{"id":0,"state":"RUNNING","worker_id":"w2:8083"}]}`))
+		server.RouteToHandler("GET", "/connectors/b/status", ghttp.RespondWith(http.StatusOK,
+			`{"name":"b","connector":{"state":"PAUSED","worker_id":"w2:8083"},"tasks":[{"id":0,"state":"PAUSED","worker_id":"w2:8083"}]}`))
+		server.RouteToHandler("GET", "/connectors/c/status", ghttp.RespondWith(http.StatusOK,
+			`{"name":"c","connector":{"state":"RUNNING","worker_id":"w1:8083"},"tasks":[{"id":0,"state":"RUNNING","worker_id":"w1:8083"}]}`))
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(dir)
+	})
+
+	It("lists workers with their assignment counts", func() {
+		session := run("workers")
+		Eventually(session).Should(Exit(0))
+		Expect(session).To(Say(`WORKER\s+CONNECTORS\s+TASKS\nw1:8083\s+2\s+1\nw2:8083\s+1\s+2\n`))
+	})
+
+	It("pauses connectors with work on a worker, and undrain restores their states", func() {
+		server.RouteToHandler("PUT", "/connectors/a/pause", ghttp.RespondWith(http.StatusAccepted, nil))
+
+		session := run("drain", "w2:8083", "--state-file", statePath)
+		Eventually(session).Should(Exit(0))
+		Expect(session).To(Say("Drained worker w2:8083, 2 connectors affected."))
+		Expect(statePath).To(BeAnExistingFile())
+
+		server.RouteToHandler("PUT", "/connectors/a/resume", ghttp.RespondWith(http.StatusAccepted, nil))
+		server.RouteToHandler("PUT", "/connectors/b/pause", ghttp.RespondWith(http.StatusAccepted, nil))
+
+		session = run("undrain", "--state-file", statePath)
+		Eventually(session).Should(Exit(0))
+		Expect(session).To(Say("Restored 2 connectors drained from worker w2:8083."))
+		Expect(statePath).NotTo(BeAnExistingFile())
+	})
+})
+
+var _ = Describe("Balance reports", func() {
+	var server *ghttp.Server
+
+	run := func(args ...string) *Session {
+		argv := append([]string{"--host", server.URL(), "balance"}, args...)
+		session, err := Start(exec.Command(pathToCLI, argv...), GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		return session
+	}
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		server.RouteToHandler("GET", "/connectors", ghttp.RespondWith(http.StatusOK, `["a","b"]`))
+		server.RouteToHandler("GET", "/connectors/a/status", ghttp.RespondWith(http.StatusOK,
+			`{"name":"a","connector":{"state":"RUNNING","worker_id":"w1"},"tasks":[
+				{"id":0,"state":"RUNNING","worker_id":"w1"},{"id":1,"state":"RUNNING","worker_id":"w1"},
+				{"id":2,"state":"RUNNING","worker_id":"w1"}]}`))
+		server.RouteToHandler("GET", "/connectors/b/status", ghttp.RespondWith(http.StatusOK,
+			`{"name":"b","connector":{"state":"RUNNING","worker_id":"w2"},"tasks":[]}`))
+		server.RouteToHandler("GET", "/connectors/a/config", ghttp.RespondWith(http.StatusOK, `{"connector.class":"org.example.ASink"}`))
+		server.RouteToHandler("GET", "/connectors/b/config", ghttp.RespondWith(http.StatusOK, `{"connector.class":"org.example.BSink"}`))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("flags skewed workers and suggests restarts", func() {
+		session := run()
+		Eventually(session).Should(Exit(0))
+		Expect(session).To(Say(`w1\s+1\s+3\s+ASink=3\s+overloaded\n`))
+		Expect(session).To(Say(`w2\s+1\s+0\s+underloaded\n`))
+		Expect(session.Err).To(Say(`try --restart:\n  a/0\n$`))
+	})
+
+	It("restarts suggested tasks", func() {
+		server.RouteToHandler("POST", "/connectors/a/tasks/0/restart", ghttp.RespondWith(http.StatusNoContent, nil))
+		session := run("--restart")
+		Eventually(session).Should(Exit(0))
+		Expect(session.Err).To(Say("Restarted task a/0."))
+	})
+})
+
+var _ = Describe("Failure groups", func() {
+	var server *ghttp.Server
+
+	run := func(args ...string) *Session {
+		argv := append([]string{"--host", server.URL()}, args...)
+		session, err := Start(exec.Command(pathToCLI, argv...), GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(Exit(0))
+		return session
+	}
+
+	refused := func(host string) string {
+		return `org.apache.kafka.connect.errors.ConnectException: Exiting WorkerSinkTask\n` +
+			`\tat org.apache.kafka.connect.runtime.WorkerSinkTask.deliverMessages(WorkerSinkTask.java:614)\n` +
+			`Caused by: java.sql.SQLException: Connection to ` + host + ` refused\n\t... 12 more`
+	}
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		server.RouteToHandler("GET", "/connectors", ghttp.RespondWith(http.StatusOK, `["a","b","c"]`))
+		server.RouteToHandler("GET", "/connectors/a/status", ghttp.RespondWith(http.StatusOK,
+			`{"name":"a","connector":{"state":"RUNNING","worker_id":"w1"},"tasks":[
+				{"id":0,"state":"FAILED","worker_id":"w1","trace":"`+refused("db-1:5432")+`"},
+				{"id":1,"state":"FAILED","worker_id":"w2","trace":"`+refused("db-2:5432")+`"}]}`))
+		server.RouteToHandler("GET", "/connectors/b/status", ghttp.RespondWith(http.StatusOK,
+			`{"name":"b","connector":{"state":"FAILED","worker_id":"w2","trace":"java.lang.IllegalArgumentException: bad config"},
+			  "tasks":[{"id":0,"state":"FAILED","worker_id":"w1","trace":"`+refused("db-1:5432")+`"}]}`))
+		server.RouteToHandler("GET", "/connectors/c/status", ghttp.RespondWith(http.StatusOK,
+			`{"name":"c","connector":{"state":"RUNNING","worker_id":"w1"},"tasks":[]}`))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("groups failures by root cause with their counts", func() {
+		session := run("failures")
+		Expect(session).To(Say(`COUNT\s+CAUSE\s+AFFECTED\n`))
+		Expect(session).To(Say(`3\s+java.sql.SQLException: Connection to <host> refused\s+a/0, a/1, b/0\n`))
+		Expect(session).To(Say(`1\s+java.lang.IllegalArgumentException: bad config\s+b\n`))
+	})
+
+	It("prints groups as JSON", func() {
+		session := run("-o", "json", "failures")
+		Expect(session).To(Say(`"cause": "java.sql.SQLException: Connection to \\u003chost\\u003e refused"`))
+		Expect(session).To(Say(`"example": "java.sql.SQLException: Connection to db-1:5432 refused"`))
+	})
+})
+
+var _ = Describe("Remediation hints", func() {
+	var server *ghttp.Server
+	var dir string
+
+	run := func(args ...string) *Session {
+		argv := append([]string{"--host", server.URL()}, args...)
+		session, err := Start(exec.Command(pathToCLI, argv...), GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(Exit(0))
+		return session
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "kafka-connect-hints")
+		Expect(err).NotTo(HaveOccurred())
+
+		server = ghttp.NewServer()
+		server.RouteToHandler("GET", "/connectors/orders-sink/status", ghttp.RespondWith(http.StatusOK,
+			`{"name":"orders-sink","connector":{"state":"RUNNING","worker_id":"w1"},"tasks":[
+				{"id":0,"state":"FAILED","worker_id":"w1","trace":"org.apache.kafka.connect.errors.ConnectException: Exiting WorkerSinkTask\n`+
+				`Caused by: org.postgresql.util.PSQLException: ERROR: relation \"orders\" does not exist\n\t... 1 more"},
+				{"id":1,"state":"RUNNING","worker_id":"w2"}]}`))
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(dir)
+	})
+
+	It("shows built-in hints under failed tasks", func() {
+		session := run("-o", "table", "status", "orders-sink")
+		Expect(session).To(Say(`orders-sink task 0 on w1: org.postgresql.util.PSQLException: ERROR: relation "orders" does not exist\n`))
+		Expect(session).To(Say(`  hint: The sink's target table doesn't exist.`))
+	})
+
+	It("prefers hints loaded with --hints", func() {
+		path := filepath.Join(dir, "hints.yaml")
+		Expect(ioutil.WriteFile(path, []byte(`
+rules:
+- name: orders-schema
+  message: relation "orders"
+  hint: Run the orders migrations.
+  link: https://wiki.example.com/orders
+`), 0644)).To(Succeed())
+
+		session := run("--hints", path, "-o", "table", "status", "orders-sink")
+		Expect(session).To(Say(`  hint: Run the orders migrations.\n  see: https://wiki.example.com/orders\n`))
+	})
+})
+
+var _ = Describe("Linting configs", func() {
+	var tmpdir string
+
+	writeInput := func(name, contents string) string {
+		path := filepath.Join(tmpdir, name)
+		Expect(ioutil.WriteFile(path, []byte(contents), 0644)).To(Succeed())
+		return path
+	}
+
+	run := func(args ...string) *Session {
+		session, err := Start(exec.Command(pathToCLI, args...), GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		return session
+	}
+
+	BeforeEach(func() {
+		tmpdir, _ = ioutil.TempDir("", "connector-lint")
+	})
+
+	AfterEach(func() {
+		_ = os.RemoveAll(tmpdir)
+	})
+
+	It("reports findings with file and line, failing on errors", func() {
+		path := writeInput("sink.properties", "connector.class=FileStreamSink\ntopics=a\ntopics.regex=b.*\n")
+		session := run("lint", "-f", path)
+		Eventually(session).Should(Exit(1))
+		Expect(session).To(Say(`sink.properties:3: error: topics and topics.regex are both set, Connect rejects this \[topics-conflict\]\n`))
+		Expect(session).To(Say(`sink.properties: warning: tasks.max is not set, so the connector runs a single task \[tasks-max\]\n`))
+		Expect(session.Err).To(Say("1 findings at error or above"))
+	})
+
+	It("filters by severity and passes without errors", func() {
+		path := writeInput("sink.yaml", "name: sink\nconfig:\n  connector.class: FileStreamSink\n  topics: a\n")
+		session := run("lint", "-f", path, "--severity", "error")
+		Eventually(session).Should(Exit(0))
+		Expect(session.Out.Contents()).To(BeEmpty())
+	})
+
+	It("prints findings as JSON", func() {
+		path := writeInput("sink.json", `{"tasks.max": "1", "connection.password": "hunter2"}`)
+		session := run("-o", "json", "lint", "-f", path)
+		Eventually(session).Should(Exit(1))
+		Expect(session).To(Say(`"file": ".*sink.json",\s+"line": 1,\s+"rule": "plaintext-secret",\s+"severity": "error",\s+"key": "connection.password"`))
+	})
+
+	It("reports where input fails to parse", func() {
+		path := writeInput("sink.properties", "connector.class=FileStreamSink\ntopics=\\uZZZZ\n")
+		session := run("lint", "-f", path)
+		Eventually(session).Should(Exit(1))
+		Expect(session.Err).To(Say(`sink.properties: not a valid connector configuration: line 2: malformed`))
+	})
+
+	It("prints findings as SARIF", func() {
+		path := writeInput("sink.json", "{\n  \"tasks.max\": \"1\",\n  \"connection.password\": \"hunter2\"\n}\n")
+		session := run("lint", "-f", path, "--sarif")
+		Eventually(session).Should(Exit(1))
+		Expect(session).To(Say(`"version": "2.1.0"`))
+		Expect(session).To(Say(`"id": "plaintext-secret"`))
+		Expect(session).To(Say(`"ruleId": "plaintext-secret",\s+"level": "error"`))
+		Expect(session).To(Say(`"startLine": 3`))
+	})
+
+	Describe("before create", func() {
+		var server *ghttp.Server
+
+		BeforeEach(func() {
+			server = ghttp.NewServer()
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("refuses configs with errors", func() {
+			path := writeInput("sink.json", `{"tasks.max": "1", "topics": "a", "topics.regex": "b"}`)
+			session := run("--host", server.URL(), "create", "sink", "--config", path)
+			Eventually(session).Should(Exit(1))
+			Expect(session.Err).To(Say(`Lint error: topics and topics.regex are both set`))
+			Expect(session.Err).To(Say(`config has lint errors, fix them or use --no-lint`))
+			Expect(server.ReceivedRequests()).To(BeEmpty())
+		})
+
+		It("sends them anyway with --no-lint", func() {
+			server.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyRequest("POST", "/connectors"),
+				ghttp.RespondWith(http.StatusCreated, `{"name":"sink","config":{}}`),
+			))
+			path := writeInput("sink.json", `{"tasks.max": "1", "topics": "a", "topics.regex": "b"}`)
+			session := run("--host", server.URL(), "create", "sink", "--config", path, "--no-lint")
+			Eventually(session).Should(Exit(0))
+			Expect(session.Err.Contents()).To(BeEmpty())
+		})
+	})
+})
+
+var _ = Describe("Doctor", func() {
+	var server *ghttp.Server
+
+	run := func(args ...string) *Session {
+		argv := append([]string{"--host", server.URL(), "doctor"}, args...)
+		session, err := Start(exec.Command(pathToCLI, argv...), GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		return session
+	}
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		server.RouteToHandler("GET", "/", ghttp.RespondWith(http.StatusOK, `{"version":"3.7.0"}`))
+		server.RouteToHandler("GET", "/connectors", ghttp.RespondWith(http.StatusOK, `["a"]`))
+		server.RouteToHandler("GET", "/connectors/a/config", ghttp.RespondWith(http.StatusOK,
+			`{"connector.class":"org.example.ASink","tasks.max":"1","topics":"x"}`))
+		server.RouteToHandler("GET", "/connectors/a/status", ghttp.RespondWith(http.StatusOK,
+			`{"name":"a","connector":{"state":"RUNNING","worker_id":"w1"},"tasks":[{"id":0,"state":"RUNNING","worker_id":"w1"}]}`))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("passes a healthy cluster", func() {
+		session := run()
+		Eventually(session).Should(Exit(0))
+		Expect(session).To(Say(`PASS  workers-reachable: Every worker responds to the REST API.\n`))
+		Expect(session).To(Say(`PASS  lint: Connector configs pass lint.\n`))
+		Expect(session).To(Say(`All 5 checks passed.\n`))
+	})
+
+	Context("with a failed task", func() {
+		BeforeEach(func() {
+			server.RouteToHandler("GET", "/connectors/a/status", ghttp.RespondWith(http.StatusOK,
+				`{"name":"a","connector":{"state":"RUNNING","worker_id":"w1"},"tasks":[
+					{"id":0,"state":"FAILED","worker_id":"w1","trace":"java.net.ConnectException: Connection refused"}]}`))
+		})
+
+		It("reports findings with hints and fails", func() {
+			session := run()
+			Eventually(session).Should(Exit(1))
+			Expect(session).To(Say(`FAIL  connectors-running: .*\n      a/0: FAILED: java.net.ConnectException: Connection refused\n        hint: An external system`))
+			Expect(session.Err).To(Say("1 of 5 checks failed"))
+		})
+
+		It("reports as JSON", func() {
+			session := run("-o", "json")
+			Eventually(session).Should(Exit(1))
+			Expect(session).To(Say(`"name": "connectors-running",`))
+			Expect(session).To(Say(`"subject": "a/0",\s+"message": "FAILED: java.net.ConnectException: Connection refused"`))
+		})
+
+		It("reports as JUnit XML", func() {
+			session := run("--junit")
+			Eventually(session).Should(Exit(1))
+			Expect(session).To(Say(`<\?xml version="1.0" encoding="UTF-8"\?>\n`))
+			Expect(session).To(Say(`<testsuite name="kafka-connect doctor" tests="5" failures="1" errors="0">`))
+			Expect(session).To(Say(`<testcase name="workers-reachable" classname="kafka-connect.doctor"></testcase>`))
+			Expect(session).To(Say(`<failure message="1 findings">a/0: FAILED: java.net.ConnectException: Connection refused`))
+		})
+	})
+})
+
+var _ = Describe("Prometheus exporter", func() {
+	var server *ghttp.Server
+	var session *Session
+	var addr string
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		server.RouteToHandler("GET", "/connectors", ghttp.RespondWith(http.StatusOK, `["a"]`))
+		server.RouteToHandler("GET", "/connectors/a/status", ghttp.RespondWith(http.StatusOK,
+			`{"name":"a","type":"source","connector":{"state":"RUNNING","worker_id":"w1"},"tasks":[{"id":0,"state":"RUNNING","worker_id":"w1"}]}`))
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		addr = listener.Addr().String()
+		listener.Close()
+
+		session, err = Start(exec.Command(pathToCLI, "--host", server.URL(), "exporter",
+			"--listen", addr, "--interval", "50ms"), GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		session.Kill().Wait()
+		server.Close()
+	})
+
+	scrape := func() string {
+		response, err := http.Get("http://" + addr + "/metrics")
+		if err != nil {
+			return ""
+		}
+		defer response.Body.Close()
+		body, _ := ioutil.ReadAll(response.Body)
+		return string(body)
+	}
+
+	It("serves metrics from polling the API", func() {
+		Eventually(scrape).Should(ContainSubstring("kafka_connect_up 1\n"))
+		Expect(scrape()).To(ContainSubstring(
+			`kafka_connect_task_state{connector="a",task="0",type="source",worker="w1",state="RUNNING"} 1`))
+	})
+
+	It("counts API errors", func() {
+		Eventually(scrape).Should(ContainSubstring("kafka_connect_up 1\n"))
+		server.RouteToHandler("GET", "/connectors", ghttp.RespondWith(http.StatusServiceUnavailable, `{}`))
+		Eventually(scrape).Should(ContainSubstring("kafka_connect_up 0\n"))
+		Expect(scrape()).To(MatchRegexp(`kafka_connect_api_errors_total [1-9]`))
+		Expect(scrape()).To(ContainSubstring(`state="RUNNING"} 1`), "keeps the last known states")
+	})
+})
+
+var _ = Describe("Healing", func() {
+	var server *ghttp.Server
+	var tmpdir string
+
+	start := func(args ...string) *Session {
+		argv := append([]string{"--host", server.URL(), "heal", "--interval", "20ms", "--backoff", "20ms"}, args...)
+		session, err := Start(exec.Command(pathToCLI, argv...), GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		return session
+	}
+
+	BeforeEach(func() {
+		tmpdir, _ = ioutil.TempDir("", "kafka-connect-heal")
+		server = ghttp.NewServer()
+		server.RouteToHandler("GET", "/connectors", ghttp.RespondWith(http.StatusOK, `["a"]`))
+		server.RouteToHandler("GET", "/connectors/a/status", ghttp.RespondWith(http.StatusOK,
+			`{"name":"a","connector":{"state":"RUNNING","worker_id":"w1"},"tasks":[
+				{"id":0,"state":"FAILED","worker_id":"w1","trace":"java.net.ConnectException: Connection refused"}]}`))
+		server.RouteToHandler("POST", "/connectors/a/tasks/0/restart", ghttp.RespondWith(http.StatusNoContent, nil))
+	})
+
+	AfterEach(func() {
+		server.Close()
+		_ = os.RemoveAll(tmpdir)
+	})
+
+	It("restarts failed tasks, then gives up and escalates", func() {
+		escalated := filepath.Join(tmpdir, "escalated.json")
+		session := start("--max-attempts", "2", "--on-escalate", "cat > "+escalated)
+		defer session.Kill()
+
+		Eventually(session).Should(Say(`restarted task a/0, attempt 1: java.net.ConnectException: Connection refused\n`))
+		Eventually(session).Should(Say(`restarted task a/0, attempt 2`))
+		Eventually(session).Should(Say(`gave up on task a/0 after 2 attempts`))
+		Eventually(func() string {
+			data, _ := ioutil.ReadFile(escalated)
+			return string(data)
+		}).Should(ContainSubstring(`"action":"give-up","task":{"connector":"a","task":0},"attempt":2`))
+	})
+
+	It("logs JSON lines without restarting in a dry run", func() {
+		session := start("--dry-run", "-o", "json")
+		defer session.Kill()
+
+		Eventually(session).Should(Say(`"action":"restart".*"dry_run":true`))
+		Consistently(func() int { return len(server.ReceivedRequests()) }, "50ms").ShouldNot(BeZero())
+		for _, r := range server.ReceivedRequests() {
+			Expect(r.Method).To(Equal("GET"))
+		}
+	})
+
+	It("stops on interrupt", func() {
+		session := start("--exclude", "a")
+		Eventually(session).Should(Say(`not restarting excluded task a/0`))
+		session.Interrupt()
+		Eventually(session).Should(Exit(0))
+	})
+})
+
+var _ = Describe("Notifications", func() {
+	var server *ghttp.Server
+	var tmpdir string
+	var bodies chan string
+
+	statusJSON := func(taskState string) string {
+		return `{"name":"a","connector":{"state":"RUNNING","worker_id":"w1"},"tasks":[
+			{"id":0,"state":"` + taskState + `","worker_id":"w1","trace":"java.net.ConnectException: Connection refused"}]}`
+	}
+
+	BeforeEach(func() {
+		tmpdir, _ = ioutil.TempDir("", "kafka-connect-notify")
+		bodies = make(chan string, 10)
+		server = ghttp.NewServer()
+		server.RouteToHandler("GET", "/connectors", ghttp.RespondWith(http.StatusOK, `["a"]`))
+		server.RouteToHandler("GET", "/connectors/a/status", ghttp.RespondWith(http.StatusOK, statusJSON("RUNNING")))
+		server.RouteToHandler("POST", "/connectors/a/tasks/0/restart", ghttp.RespondWith(http.StatusNoContent, nil))
+		server.RouteToHandler("POST", "/hook", func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			bodies <- string(body)
+		})
+	})
+
+	AfterEach(func() {
+		server.Close()
+		_ = os.RemoveAll(tmpdir)
+	})
+
+	It("posts state changes seen by status --watch to a webhook", func() {
+		template := filepath.Join(tmpdir, "slack.tmpl")
+		Expect(ioutil.WriteFile(template, []byte(`{"text": {{json .Message}}}`), 0644)).To(Succeed())
+
+		command := exec.Command(pathToCLI, "--host", server.URL(), "status", "--watch", "--interval", "20ms",
+			"--notify-webhook", server.URL()+"/hook", "--notify-template", template, "--notify-on", "failed")
+		session, err := Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		defer session.Kill()
+
+		Eventually(session).Should(Say("Every 20ms"))
+		Consistently(bodies, "60ms").ShouldNot(Receive())
+
+		server.RouteToHandler("GET", "/connectors/a/status", ghttp.RespondWith(http.StatusOK, statusJSON("FAILED")))
+		Eventually(bodies).Should(Receive(MatchJSON(
+			`{"text":"task a/0 is FAILED (was RUNNING): java.net.ConnectException: Connection refused"}`)))
+		Consistently(bodies, "60ms").ShouldNot(Receive())
+	})
+
+	It("runs a command for heal events", func() {
+		server.RouteToHandler("GET", "/connectors/a/status", ghttp.RespondWith(http.StatusOK, statusJSON("FAILED")))
+		events := filepath.Join(tmpdir, "events")
+
+		command := exec.Command(pathToCLI, "--host", server.URL(), "heal", "--interval", "20ms", "--backoff", "20ms",
+			"--max-attempts", "1", "--notify-on", "give-up",
+			"--notify-command", `echo "$KAFKA_CONNECT_EVENT_TYPE $KAFKA_CONNECT_EVENT_CONNECTOR/$KAFKA_CONNECT_EVENT_TASK" >> `+events)
+		session, err := Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		defer session.Kill()
+
+		Eventually(session).Should(Say("gave up on task a/0"))
+		Eventually(func() string {
+			data, _ := ioutil.ReadFile(events)
+			return string(data)
+		}).Should(Equal("give-up a/0\n"))
+	})
+})
+
+var _ = Describe("REST gateway", func() {
+	var prod, dev *ghttp.Server
+	var session *Session
+	var tmpdir, addr, auditLog string
+
+	BeforeEach(func() {
+		prod, dev = ghttp.NewServer(), ghttp.NewServer()
+		prod.RouteToHandler("GET", "/connectors", ghttp.RespondWith(http.StatusOK, `["orders"]`))
+		prod.RouteToHandler("GET", "/connectors/orders/status", ghttp.RespondWith(http.StatusOK,
+			`{"name":"orders","connector":{"state":"RUNNING","worker_id":"w1"},"tasks":[]}`))
+		dev.RouteToHandler("PUT", "/connectors/scratch/pause", ghttp.RespondWith(http.StatusAccepted, nil))
+
+		tmpdir, _ = ioutil.TempDir("", "kafka-connect-serve")
+		configFile := filepath.Join(tmpdir, "config.yaml")
+		authFile := filepath.Join(tmpdir, "auth.yaml")
+		auditLog = filepath.Join(tmpdir, "audit.log")
+		config := "contexts:\n  prod:\n    hosts: [" + prod.URL() + "]\n  dev:\n    hosts: [" + dev.URL() + "]\n"
+		Expect(ioutil.WriteFile(configFile, []byte(config), 0600)).To(Succeed())
+		auth := "users:\n- name: portal\n  key: portal-key\n  roles:\n    prod: read\n    dev: write\n"
+		Expect(ioutil.WriteFile(authFile, []byte(auth), 0600)).To(Succeed())
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		addr = listener.Addr().String()
+		listener.Close()
+
+		session, err = Start(exec.Command(pathToCLI, "--config-file", configFile, "serve",
+			"--listen", addr, "--auth-file", authFile, "--audit-log", auditLog), GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session.Err).Should(Say("Serving clusters dev, prod"))
+	})
+
+	AfterEach(func() {
+		session.Kill().Wait()
+		prod.Close()
+		dev.Close()
+		_ = os.RemoveAll(tmpdir)
+	})
+
+	call := func(method, path string) (int, string) {
+		request, _ := http.NewRequest(method, "http://"+addr+path, nil)
+		request.Header.Set("Authorization", "Bearer portal-key")
+		var response *http.Response
+		Eventually(func() error {
+			var err error
+			response, err = http.DefaultClient.Do(request)
+			return err
+		}).Should(Succeed())
+		defer response.Body.Close()
+		body, _ := ioutil.ReadAll(response.Body)
+		return response.StatusCode, string(body)
+	}
+
+	It("serves each context's cluster and aggregates", func() {
+		code, body := call("GET", "/clusters/prod/connectors/orders/status")
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(body).To(MatchJSON(`{"name":"orders","connector":{"state":"RUNNING","worker_id":"w1"},"tasks":[]}`))
+
+		dev.RouteToHandler("GET", "/connectors", ghttp.RespondWith(http.StatusOK, `[]`))
+		code, body = call("GET", "/summary")
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(body).To(MatchJSON(`[{"cluster":"dev","up":true,"connectors":{},"tasks":{}},
+			{"cluster":"prod","up":true,"connectors":{"RUNNING":1},"tasks":{}}]`))
+	})
+
+	It("writes changes to the audit log", func() {
+		code, _ := call("PUT", "/clusters/dev/connectors/scratch/pause")
+		Expect(code).To(Equal(http.StatusAccepted))
+		code, _ = call("PUT", "/clusters/prod/connectors/orders/pause")
+		Expect(code).To(Equal(http.StatusForbidden))
+
+		data, err := ioutil.ReadFile(auditLog)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(MatchRegexp(`"user":"portal",.*"cluster":"dev","method":"PUT","path":"/clusters/dev/connectors/scratch/pause","status":202}\n`))
+		Expect(string(data)).To(MatchRegexp(`"cluster":"prod",.*"status":403}\n$`))
+	})
+})
+
 var _ = Describe("Argument Validation", func() {
 	var app *kingpin.Application
 	var argv []string
@@ -63,6 +1323,105 @@ var _ = Describe("Argument Validation", func() {
 		})
 	})
 
+	Describe("with an unknown --output format", func() {
+		BeforeEach(func() { argv = []string{"-o", "xml", "list"} })
+
+		It("fails", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix(`unknown output format "xml"`))
+		})
+	})
+
+	Describe("with an invalid jsonpath", func() {
+		BeforeEach(func() { argv = []string{"-o", "jsonpath={.name", "list"} })
+
+		It("fails", func() {
+			Expect(err).To(MatchError(ContainSubstring("invalid jsonpath")))
+		})
+	})
+
+	Describe("for restart", func() {
+		Context("with --only-failed but not --include-tasks", func() {
+			BeforeEach(func() { argv = []string{"restart", "a-name", "--only-failed"} })
+
+			It("fails", func() {
+				Expect(err).To(MatchError("--only-failed requires --include-tasks"))
+			})
+		})
+	})
+
+	Describe("for status", func() {
+		Context("with a connector name and --failed", func() {
+			BeforeEach(func() { argv = []string{"status", "a-name", "--failed"} })
+
+			It("fails", func() {
+				Expect(err).To(MatchError("--all and --failed cannot be used with a connector name"))
+			})
+		})
+
+		Context("with notifications but not --watch", func() {
+			BeforeEach(func() { argv = []string{"status", "--notify-command", "true"} })
+
+			It("fails", func() {
+				Expect(err).To(MatchError("notifications need --watch"))
+			})
+		})
+
+		Context("with --watch and a zero --interval", func() {
+			BeforeEach(func() { argv = []string{"status", "--watch", "--interval", "0s"} })
+
+			It("fails", func() {
+				Expect(err).To(MatchError("--interval must be positive"))
+			})
+		})
+
+		Context("without a connector name", func() {
+			BeforeEach(func() { argv = []string{"status", "--watch"} })
+
+			It("succeeds", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("for serve", func() {
+		Context("without --auth-file", func() {
+			BeforeEach(func() { argv = []string{"serve"} })
+
+			It("fails", func() {
+				Expect(err).To(MatchError(ContainSubstring("required flag --auth-file not provided")))
+			})
+		})
+	})
+
+	Describe("for pause", func() {
+		Context("without a name or selection flags", func() {
+			BeforeEach(func() { argv = []string{"pause"} })
+
+			It("fails", func() {
+				Expect(err).To(MatchError("a connector name or selection flag is required"))
+			})
+		})
+
+		Context("with a selection flag", func() {
+			BeforeEach(func() { argv = []string{"pause", "--class", "JdbcSinkConnector"} })
+
+			It("succeeds", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("for rolling-restart", func() {
+		Context("with a failure rate above 1", func() {
+			BeforeEach(func() { argv = []string{"rolling-restart", "--max-failure-rate", "1.5"} })
+
+			It("fails", func() {
+				Expect(err).To(MatchError("--max-failure-rate must be between 0 and 1"))
+			})
+		})
+	})
+
 	Describe("for a nonexistent command", func() {
 		BeforeEach(func() { argv = []string{"asdfjk"} })
 
@@ -158,6 +1517,26 @@ var _ = Describe("Argument Validation", func() {
 		})
 	})
 
+	Describe("for upsert", func() {
+		BeforeEach(func() { argv = []string{"upsert"} })
+
+		Context("without a connector name or --from-file", func() {
+			It("fails", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("either a connector name or --from-file is required"))
+			})
+		})
+
+		Context("with a connector name but no --config", func() {
+			BeforeEach(func() { argv = append(argv, "a-name") })
+
+			It("fails", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("--config is required with a connector name"))
+			})
+		})
+	})
+
 	Describe("for update", func() {
 		BeforeEach(func() { argv = []string{"update"} })
 
@@ -184,4 +1563,107 @@ var _ = Describe("Argument Validation", func() {
 			})
 		})
 	})
+
+	Describe("for restore", func() {
+		var backupDir string
+
+		BeforeEach(func() {
+			backupDir, _ = ioutil.TempDir("", "connect-backup")
+			argv = []string{"restore", "--from", backupDir}
+		})
+
+		AfterEach(func() {
+			_ = os.RemoveAll(backupDir)
+		})
+
+		Context("with an unknown --on-conflict policy", func() {
+			BeforeEach(func() { argv = append(argv, "--on-conflict", "merge") })
+
+			It("fails", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("enum value must be one of fail,skip,overwrite"))
+			})
+		})
+
+		Context("with defaults", func() {
+			It("succeeds", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(subcommand).To(Equal("restore"))
+			})
+		})
+	})
+
+	Describe("for migrate", func() {
+		BeforeEach(func() { argv = []string{"migrate", "prod-*", "--to", "http://other:8083/"} })
+
+		Context("with a malformed --rewrite rule", func() {
+			BeforeEach(func() { argv = append(argv, "--rewrite", "topics=foo") })
+
+			It("fails", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("KEY:PATTERN=REPLACEMENT"))
+			})
+		})
+
+		Context("with a --to that is not an absolute URL", func() {
+			BeforeEach(func() { argv = []string{"migrate", "prod-*", "--to", "other"} })
+
+			It("fails", func() {
+				Expect(err).To(MatchError("target other is not a valid absolute URL"))
+			})
+		})
+
+		Context("without a target", func() {
+			BeforeEach(func() { argv = []string{"migrate", "prod-*"} })
+
+			It("fails", func() {
+				Expect(err).To(MatchError("exactly one of --to and --to-context is required"))
+			})
+		})
+
+		Context("with both --to and --to-context", func() {
+			BeforeEach(func() { argv = append(argv, "--to-context", "other") })
+
+			It("fails", func() {
+				Expect(err).To(MatchError("exactly one of --to and --to-context is required"))
+			})
+		})
+
+		Context("with an unknown --to-context", func() {
+			BeforeEach(func() { argv = []string{"migrate", "prod-*", "--to-context", "nope"} })
+
+			It("fails", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(`context "nope" is not defined`))
+			})
+		})
+
+		Context("with valid rules", func() {
+			BeforeEach(func() { argv = append(argv, "--rename", "^prod-(.*)=staging-$1") })
+
+			It("succeeds", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("for sync", func() {
+		BeforeEach(func() { argv = []string{"sync", "--to", "http://other:8083/"} })
+
+		Context("with --prune and --once", func() {
+			BeforeEach(func() { argv = append(argv, "--prune", "--once") })
+
+			It("fails", func() {
+				Expect(err).To(MatchError("--prune cannot be used with --once"))
+			})
+		})
+
+		Context("with --prune", func() {
+			BeforeEach(func() { argv = append(argv, "--prune") })
+
+			It("succeeds", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+	})
 })
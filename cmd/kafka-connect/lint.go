@@ -0,0 +1,253 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/go-kafka/connect"
+	"github.com/go-kafka/connect/lint"
+)
+
+var (
+	lintCmd *kingpin.CmdClause
+
+	lintFiles                   []string
+	lintMinSeverity, lintFailOn string
+	lintSARIF, noLint           bool
+)
+
+// A lintResult is a finding in a file, as printed by lint.
+type lintResult struct {
+	File string `json:"file"`
+	Line int    `json:"line,omitempty"`
+	lint.Finding
+}
+
+func configureLintCommand(app *kingpin.Application) {
+	lintCmd = app.Command("lint", "Checks connector configs for common mistakes, without a cluster.")
+	lintCmd.Flag("file", "A connector or config file in any format create accepts. Repeatable.").
+		Short('f').
+		Required().
+		PlaceHolder("FILE").
+		ExistingFilesVar(&lintFiles)
+	lintCmd.Flag("format", "Format of the files, by default detected from file extension or else JSON.").
+		EnumVar(&inputFormat, formatJSON, formatYAML, formatProperties)
+	lintCmd.Flag("severity", "Only report findings at least this severe: info, warning or error.").
+		Default("info").
+		EnumVar(&lintMinSeverity, "info", "warning", "error")
+	lintCmd.Flag("fail-on", "Exit with an error if there are findings at least this severe.").
+		Default("error").
+		EnumVar(&lintFailOn, "info", "warning", "error")
+	lintCmd.Flag("sarif", "Print findings as SARIF, for code scanning in CI.").
+		BoolVar(&lintSARIF)
+
+	for _, command := range []*kingpin.CmdClause{createCmd, updateCmd, upsertCmd} {
+		command.Flag("no-lint", "Don't check the config for mistakes before sending it.").
+			BoolVar(&noLint)
+	}
+
+	// Reset state for in-process tests
+	lintFiles, lintMinSeverity, lintFailOn = nil, "", ""
+	lintSARIF, noLint = false, false
+}
+
+func lintConfigFiles() error {
+	minSeverity, _ := lint.ParseSeverity(lintMinSeverity)
+	failOn, _ := lint.ParseSeverity(lintFailOn)
+	linter := lint.Default()
+
+	results := []lintResult{}
+	for _, path := range lintFiles {
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		config, err := decodeLintInput(contents, detectInputFormat(path))
+		if err != nil {
+			return fmt.Errorf("%v: %v", path, err)
+		}
+
+		for _, f := range linter.Lint(config) {
+			if f.Severity >= minSeverity {
+				results = append(results, lintResult{File: path, Line: findKeyLine(contents, f.Key), Finding: f})
+			}
+		}
+	}
+
+	var err error
+	switch {
+	case lintSARIF:
+		err = writeSARIF(os.Stdout, linter, results)
+	case outputSpec != "":
+		err = printOutput(results)
+	default:
+		for _, r := range results {
+			location := r.File
+			if r.Line > 0 {
+				location = fmt.Sprintf("%v:%d", r.File, r.Line)
+			}
+			fmt.Printf("%v: %v\n", location, r.Finding)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	var failing int
+	for _, r := range results {
+		if r.Severity >= failOn {
+			failing++
+		}
+	}
+	if failing > 0 {
+		return fmt.Errorf("%d findings at %v or above", failing, failOn)
+	}
+	return nil
+}
+
+// Decodes a file holding either a Connector or just its config.
+func decodeLintInput(contents []byte, format string) (connect.ConnectorConfig, error) {
+	var connector connect.Connector
+	if err := decodeConnectorData(contents, format, &connector); err == nil && connector.Config != nil {
+		return connector.Config, nil
+	}
+	var config connect.ConnectorConfig
+	if err := decodeConnectorData(contents, format, &config); err != nil {
+		return nil, fmt.Errorf("not a valid connector configuration: %v", err)
+	}
+	return config, nil
+}
+
+// Finds the line a config key is set on, for pointing editors and code review
+// at findings. Zero if it isn't found, e.g. for keys that are missing.
+func findKeyLine(contents []byte, key string) int {
+	if key == "" {
+		return 0
+	}
+	for i, line := range bytes.Split(contents, []byte("\n")) {
+		text := strings.TrimSpace(string(line))
+		if strings.Contains(text, `"`+key+`"`) {
+			return i + 1
+		}
+		// Unquoted as in properties or YAML, followed by a separator
+		if rest := strings.TrimPrefix(text, key); len(rest) < len(text) &&
+			strings.IndexAny(strings.TrimLeft(rest, " \t"), "=:") == 0 {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// Checks a config about to be sent to the cluster, reporting findings on
+// stderr and failing if there are errors, unless --no-lint is given.
+func lintBeforeSubmit(config connect.ConnectorConfig) error {
+	if noLint {
+		return nil
+	}
+	findings := lint.Default().Lint(config)
+	for _, f := range findings {
+		fmt.Fprintf(os.Stderr, "Lint %v\n", f)
+	}
+	if worst, ok := lint.Worst(findings); ok && worst == lint.Error {
+		return fmt.Errorf("config has lint errors, fix them or use --no-lint to send it anyway")
+	}
+	return nil
+}
+
+// Static Analysis Results Interchange Format, which CI systems such as GitHub
+// code scanning take. Only what we need of the 2.1.0 schema is modeled.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver struct {
+		Name           string      `json:"name"`
+		Version        string      `json:"version"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	} `json:"driver"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+		Region *sarifRegion `json:"region,omitempty"`
+	} `json:"physicalLocation"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+func writeSARIF(w io.Writer, linter *lint.Linter, results []lintResult) error {
+	var run sarifRun
+	run.Tool.Driver.Name = "kafka-connect"
+	run.Tool.Driver.Version = Version
+	run.Tool.Driver.InformationURI = "https://github.com/go-kafka/connect"
+	for _, rule := range linter.Rules {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules,
+			sarifRule{ID: rule.ID, ShortDescription: sarifMessage{rule.Description}})
+	}
+
+	run.Results = []sarifResult{}
+	for _, r := range results {
+		level := r.Severity.String()
+		if r.Severity == lint.Info {
+			level = "note"
+		}
+		var location sarifLocation
+		location.PhysicalLocation.ArtifactLocation.URI = filepath.ToSlash(r.File)
+		if r.Line > 0 {
+			location.PhysicalLocation.Region = &sarifRegion{StartLine: r.Line}
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    r.Rule,
+			Level:     level,
+			Message:   sarifMessage{r.Message},
+			Locations: []sarifLocation{location},
+		})
+	}
+
+	log := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
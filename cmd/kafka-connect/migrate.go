@@ -0,0 +1,217 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"os/signal"
+	"time"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/go-kafka/connect"
+	"github.com/go-kafka/connect/migrate"
+)
+
+var (
+	migrateCmd, syncCmd *kingpin.CmdClause
+
+	targetHost                *url.URL
+	targetContext             string
+	connPatterns              []string
+	renameRules, rewriteRules []string
+	setValues                 map[string]string
+	deleteSource, pruneTarget bool
+	syncOnce                  bool
+	waitTimeout, syncInterval time.Duration
+)
+
+func configureMigrateCommands(app *kingpin.Application) {
+	migrateCmd = app.Command("migrate", "Copies connectors to another cluster.")
+	syncCmd = app.Command("sync", "Continuously keeps connectors on another cluster in line with this one.")
+
+	migrateCmd.Arg("pattern", "Names or glob patterns of connectors to migrate.").
+		Required().
+		StringsVar(&connPatterns)
+	syncCmd.Arg("pattern", "Names or glob patterns of connectors to sync, default all.").
+		StringsVar(&connPatterns)
+
+	for _, command := range []*kingpin.CmdClause{migrateCmd, syncCmd} {
+		command.Flag("to", "Host address for the Kafka Connect REST API of the target cluster.").
+			URLVar(&targetHost)
+		command.Flag("to-context", "Context from the config file for the target cluster, instead of --to.").
+			PlaceHolder("CONTEXT").
+			StringVar(&targetContext)
+		command.Flag("rename", "Rename connectors matching a regular expression, e.g. '^prod-(.*)=staging-$1'. Repeatable.").
+			PlaceHolder("PATTERN=REPLACEMENT").
+			StringsVar(&renameRules)
+		command.Flag("rewrite", "Rewrite a config value matching a regular expression, e.g. 'topics:^prod\\.=staging.'. Repeatable.").
+			PlaceHolder("KEY:PATTERN=REPLACEMENT").
+			StringsVar(&rewriteRules)
+		command.Flag("set", "Set a config value on the target. Repeatable.").
+			PlaceHolder("KEY=VALUE").
+			StringMapVar(&setValues)
+	}
+
+	migrateCmd.Flag("delete-source", "Delete connectors from this cluster once they are running on the target.").
+		BoolVar(&deleteSource)
+	migrateCmd.Flag("wait", "Wait up to this long for migrated connectors to be running on the target.").
+		PlaceHolder("DURATION").
+		DurationVar(&waitTimeout)
+
+	syncCmd.Flag("interval", "How often to sync.").
+		Default("30s").
+		DurationVar(&syncInterval)
+	syncCmd.Flag("prune", "Delete connectors from the target that were synced while running but have since gone from this cluster.").
+		BoolVar(&pruneTarget)
+	syncCmd.Flag("once", "Sync a single time and exit.").
+		BoolVar(&syncOnce)
+
+	// Reset state for in-process tests
+	targetHost, targetContext = nil, ""
+	connPatterns, renameRules, rewriteRules = nil, nil, nil
+	setValues = map[string]string{}
+	deleteSource, pruneTarget, syncOnce = false, false, false
+	waitTimeout, syncInterval = 0, 0
+}
+
+func migrateOptions() (opts migrate.Options, err error) {
+	opts.Patterns = connPatterns
+	opts.Set = setValues
+	opts.WaitTimeout = waitTimeout
+	opts.DeleteSource = deleteSource
+
+	for _, rule := range renameRules {
+		rename, err := migrate.ParseRename(rule)
+		if err != nil {
+			return opts, err
+		}
+		opts.Renames = append(opts.Renames, rename)
+	}
+	for _, rule := range rewriteRules {
+		rewrite, err := migrate.ParseRewrite(rule)
+		if err != nil {
+			return opts, err
+		}
+		opts.Rewrites = append(opts.Rewrites, rewrite)
+	}
+
+	return
+}
+
+// Returns a client for the target cluster given by --to or --to-context, with
+// a description of it for prompts.
+func targetClient() (*connect.Client, string, error) {
+	if targetContext == "" {
+		return connect.NewClient(targetHost.String()), targetHost.String(), nil
+	}
+
+	ctx, ok := cliConf.Contexts[targetContext]
+	if !ok {
+		return nil, "", fmt.Errorf("context %q is not defined in %v", targetContext, configFilePath)
+	}
+	client, err := contextClient(targetContext, ctx)
+	return client, "context " + targetContext, err
+}
+
+// Asks for confirmation before changing the target cluster, if it is given by
+// --to-context and that context requires it.
+func confirmTarget(action string) error {
+	if targetContext == "" {
+		return nil
+	}
+	return confirmDestructiveIn(targetContext, cliConf.Contexts[targetContext], action)
+}
+
+func printMigrateResults(results []migrate.Result, verbose bool) {
+	for _, r := range results {
+		switch {
+		case r.Action == migrate.Unchanged && !verbose:
+			continue
+		case r.Action == migrate.Pruned:
+			fmt.Printf("%v: %v\n", r.TargetName, r.Action)
+		case r.Name != r.TargetName:
+			fmt.Printf("%v -> %v: %v\n", r.Name, r.TargetName, r.Action)
+		default:
+			fmt.Printf("%v: %v\n", r.Name, r.Action)
+		}
+	}
+}
+
+func migrateConnectors(client *connect.Client) error {
+	opts, err := migrateOptions()
+	if err != nil {
+		return err
+	}
+	target, _, err := targetClient()
+	if err != nil {
+		return err
+	}
+	if opts.DeleteSource {
+		if err := confirmDestructive("delete migrated connectors from this cluster"); err != nil {
+			return err
+		}
+	}
+	if err := confirmTarget("create or overwrite connectors migrated from " + host.String()); err != nil {
+		return err
+	}
+
+	results, err := migrate.Migrate(client, target, opts)
+	printMigrateResults(results, true)
+	return err
+}
+
+func syncConnectors(client *connect.Client) error {
+	opts, err := migrateOptions()
+	if err != nil {
+		return err
+	}
+
+	target, targetName, err := targetClient()
+	if err != nil {
+		return err
+	}
+	switch {
+	case targetContext != "":
+		action := "overwrite connectors with those synced from " + host.String()
+		if pruneTarget {
+			action = "overwrite and prune connectors synced from " + host.String()
+		}
+		if err := confirmTarget(action); err != nil {
+			return err
+		}
+	case pruneTarget:
+		if err := confirmDestructive("prune connectors from " + targetName); err != nil {
+			return err
+		}
+	}
+
+	syncer := &migrate.Syncer{
+		Source:  client,
+		Target:  target,
+		Options: opts,
+		Prune:   pruneTarget,
+	}
+
+	if syncOnce {
+		results, err := syncer.SyncOnce()
+		printMigrateResults(results, true)
+		return err
+	}
+
+	stop := make(chan struct{})
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	go func() {
+		<-interrupts
+		close(stop)
+	}()
+
+	syncer.Run(syncInterval, stop, func(results []migrate.Result, err error) {
+		printMigrateResults(results, false)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "sync failed, will retry: %v\n", err)
+		}
+	})
+	return nil
+}
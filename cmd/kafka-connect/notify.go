@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/go-kafka/connect"
+	"github.com/go-kafka/connect/notify"
+)
+
+var (
+	notifyWebhook                  *url.URL
+	notifyTemplatePath             string
+	notifyHeaders                  map[string]string
+	notifyRetries                  int
+	notifyCommand                  string
+	notifyTypes, notifyConnectors  []string
+	notifyDedupe, notifyRatePeriod time.Duration
+	notifyRateLimit                int
+)
+
+// Adds flags for sending notifications of events to commands that watch for
+// them.
+func configureNotifyFlags(commands ...*kingpin.CmdClause) {
+	for _, command := range commands {
+		command.Flag("notify-webhook", "POST events as JSON to this URL.").
+			PlaceHolder("URL").
+			URLVar(&notifyWebhook)
+		command.Flag("notify-template", "Go template file for --notify-webhook request bodies, given the event.").
+			PlaceHolder("FILE").
+			ExistingFileVar(&notifyTemplatePath)
+		command.Flag("notify-header", "Header for --notify-webhook requests. Repeatable.").
+			PlaceHolder("KEY=VALUE").
+			StringMapVar(&notifyHeaders)
+		command.Flag("notify-retries", "Times to retry a webhook that fails with a network error, 429 or 5xx.").
+			Default("3").
+			IntVar(&notifyRetries)
+		command.Flag("notify-command", "Shell command to run for events, given the event as JSON on stdin and in KAFKA_CONNECT_EVENT_* variables.").
+			PlaceHolder("COMMAND").
+			StringVar(&notifyCommand)
+		command.Flag("notify-on", "Only notify of events of this type, such as failed, running or give-up. Repeatable.").
+			PlaceHolder("TYPE").
+			StringsVar(&notifyTypes)
+		command.Flag("notify-connector", "Only notify of events for connectors matching this glob pattern, and those about no connector such as failed polls. Repeatable.").
+			PlaceHolder("PATTERN").
+			StringsVar(&notifyConnectors)
+		command.Flag("notify-dedupe", "Notify of the same event for a connector or task once in this long.").
+			Default("10m").
+			DurationVar(&notifyDedupe)
+		command.Flag("notify-rate-limit", "Most events to notify of for a connector per --notify-rate-period, 0 for no limit.").
+			Default("0").
+			IntVar(&notifyRateLimit)
+		command.Flag("notify-rate-period", "Period for --notify-rate-limit.").
+			Default("1h").
+			DurationVar(&notifyRatePeriod)
+	}
+
+	// Reset state for in-process tests
+	notifyWebhook, notifyTemplatePath, notifyHeaders = nil, "", map[string]string{}
+	notifyRetries, notifyCommand, notifyTypes, notifyConnectors = 0, "", nil, nil
+	notifyDedupe, notifyRatePeriod, notifyRateLimit = 0, 0, 0
+}
+
+func notificationsEnabled() bool {
+	return notifyWebhook != nil || notifyCommand != ""
+}
+
+// Returns a dispatcher for the notification flags, or nil if no notifier is
+// given.
+func newDispatcher() (*notify.Dispatcher, error) {
+	if !notificationsEnabled() {
+		return nil, nil
+	}
+	if notifyRateLimit > 0 && notifyRatePeriod <= 0 {
+		return nil, fmt.Errorf("--notify-rate-period must be positive")
+	}
+
+	d := &notify.Dispatcher{
+		Filter: notify.Filter{
+			Connectors: connect.Selector{Names: notifyConnectors},
+			Types:      notifyTypes,
+		},
+		Dedupe:     notifyDedupe,
+		RateLimit:  notifyRateLimit,
+		RatePeriod: notifyRatePeriod,
+	}
+
+	if notifyWebhook != nil {
+		hook := &notify.Webhook{URL: notifyWebhook.String(), Header: http.Header{}, Retries: notifyRetries}
+		for key, value := range notifyHeaders {
+			hook.Header.Set(key, value)
+		}
+		if notifyTemplatePath != "" {
+			text, err := ioutil.ReadFile(notifyTemplatePath)
+			if err != nil {
+				return nil, err
+			}
+			if hook.Template, err = notify.NewTemplate(notifyTemplatePath, string(text)); err != nil {
+				return nil, fmt.Errorf("invalid --notify-template: %v", err)
+			}
+		}
+		d.Notifiers = append(d.Notifiers, hook)
+	}
+	if notifyCommand != "" {
+		d.Notifiers = append(d.Notifiers, &notify.Command{Command: notifyCommand, Output: os.Stderr})
+	}
+	return d, nil
+}
+
+// Sends an event if notifications are enabled. Failures are reported without
+// stopping the command, which is likely a long-running watch.
+func sendNotification(d *notify.Dispatcher, e notify.Event) {
+	if d == nil {
+		return
+	}
+	if err := d.Send(e); err != nil {
+		fmt.Fprintf(os.Stderr, "Notification failed: %v\n", err)
+	}
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/go-kafka/connect"
+	"github.com/go-kafka/connect/properties"
+)
+
+// An outputFormatter renders API results for display. Results are the values
+// returned by the library, so formatters may need to handle several types.
+type outputFormatter interface {
+	Format(w io.Writer, v interface{}) error
+}
+
+// Names of --output formats. Templates take their text after an =.
+const (
+	outputJSON       = "json"
+	outputYAML       = "yaml"
+	outputTable      = "table"
+	outputProperties = "properties"
+	outputName       = "name"
+	outputTemplate   = "go-template"
+	outputJSONPath   = "jsonpath"
+)
+
+// newOutputFormatter returns the formatter selected by a --output value.
+func newOutputFormatter(spec string) (outputFormatter, error) {
+	kind, arg := spec, ""
+	if i := strings.Index(spec, "="); i >= 0 {
+		kind, arg = spec[:i], spec[i+1:]
+	}
+
+	switch kind {
+	case "", outputJSON:
+		return jsonFormatter{}, nil
+	case outputYAML:
+		return yamlFormatter{}, nil
+	case outputTable:
+		return tableFormatter{}, nil
+	case outputProperties:
+		return propertiesFormatter{}, nil
+	case outputName:
+		return nameFormatter{}, nil
+	case outputTemplate:
+		tmpl, err := template.New("output").Parse(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid go-template: %v", err)
+		}
+		return templateFormatter{tmpl}, nil
+	case outputJSONPath:
+		path, err := parseJSONPathTemplate(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jsonpath: %v", err)
+		}
+		return path, nil
+	}
+
+	return nil, fmt.Errorf("unknown output format %q, try json, yaml, table, properties, name, go-template=... or jsonpath=...", spec)
+}
+
+// Formats a value with the formatter selected by --output and prints it.
+func printOutput(v interface{}) error {
+	var buf bytes.Buffer
+	if err := output.Format(&buf, v); err != nil {
+		return err
+	}
+
+	// Templates may not end in a newline, be nice to the prompt
+	if buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+	_, err := buf.WriteTo(os.Stdout)
+	return err
+}
+
+func unsupportedOutput(format string, v interface{}) error {
+	return fmt.Errorf("%v output is not supported for %T", format, v)
+}
+
+// Converts a result to the plain maps and slices it looks like as JSON, so that
+// templates and paths address fields by their API names.
+func toGeneric(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	err = json.Unmarshal(data, &generic)
+	return generic, err
+}
+
+type jsonFormatter struct{}
+
+func (jsonFormatter) Format(w io.Writer, v interface{}) error {
+	pretty, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(pretty))
+	return err
+}
+
+type yamlFormatter struct{}
+
+func (yamlFormatter) Format(w io.Writer, v interface{}) error {
+	generic, err := toGeneric(v)
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(generic)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+type propertiesFormatter struct{}
+
+func (propertiesFormatter) Format(w io.Writer, v interface{}) error {
+	switch v := v.(type) {
+	case connect.ConnectorConfig:
+		return properties.Format(w, v)
+	case *connect.Connector:
+		return properties.Format(w, v.Config)
+	case connect.Connector:
+		return properties.Format(w, v.Config)
+	}
+	return unsupportedOutput(outputProperties, v)
+}
+
+type nameFormatter struct{}
+
+func (nameFormatter) Format(w io.Writer, v interface{}) error {
+	var names []string
+
+	switch v := v.(type) {
+	case []string:
+		names = v
+	case []connect.ConnectorStatus:
+		for _, status := range v {
+			names = append(names, status.Name)
+		}
+	case *connect.ConnectorStatus:
+		names = []string{v.Name}
+	case *connect.Connector:
+		names = []string{v.Name}
+	case connect.Connector:
+		names = []string{v.Name}
+	case []connect.Task:
+		for _, task := range v {
+			names = append(names, fmt.Sprintf("%v/%d", task.ID.ConnectorName, task.ID.ID))
+		}
+	default:
+		return unsupportedOutput(outputName, v)
+	}
+
+	for _, name := range names {
+		if _, err := fmt.Fprintln(w, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type tableFormatter struct{}
+
+func (tableFormatter) Format(w io.Writer, v interface{}) error {
+	var rows [][]string
+
+	switch v := v.(type) {
+	case []string:
+		rows = append(rows, []string{"NAME"})
+		for _, name := range v {
+			rows = append(rows, []string{name})
+		}
+	case []connect.ConnectorStatus:
+		rows = overviewRows(v)
+	case *connect.ConnectorStatus:
+		rows = detailRows(v)
+	case []connect.WorkerLoad:
+		rows = workerLoadRows(v)
+	case *connect.BalanceReport:
+		rows = balanceRows(v)
+	case []connect.FailureGroup:
+		rows = failureGroupRows(v)
+	case connect.ConnectorConfig:
+		rows = configRows(v)
+	case *connect.Connector:
+		return tableFormatter{}.Format(w, *v)
+	case connect.Connector:
+		rows = append(rows, []string{"NAME", "CLASS", "TASKS"})
+		rows = append(rows, []string{v.Name, v.Config["connector.class"], strconv.Itoa(len(v.Tasks))})
+	case []connect.Task:
+		rows = append(rows, []string{"CONNECTOR", "ID", "CLASS"})
+		for _, task := range v {
+			rows = append(rows, []string{task.ID.ConnectorName, strconv.Itoa(task.ID.ID), task.Config["task.class"]})
+		}
+	default:
+		return unsupportedOutput(outputTable, v)
+	}
+
+	return writeTable(w, rows)
+}
+
+func configRows(config connect.ConnectorConfig) [][]string {
+	rows := [][]string{{"KEY", "VALUE"}}
+	for _, key := range sortedKeys(config) {
+		rows = append(rows, []string{key, config[key]})
+	}
+	return rows
+}
+
+func sortedKeys(config connect.ConnectorConfig) []string {
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func writeTable(w io.Writer, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, row := range rows {
+		if _, err := fmt.Fprintln(tw, strings.Join(row, "\t")); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+type templateFormatter struct {
+	tmpl *template.Template
+}
+
+func (f templateFormatter) Format(w io.Writer, v interface{}) error {
+	generic, err := toGeneric(v)
+	if err != nil {
+		return err
+	}
+	return f.tmpl.Execute(w, generic)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/go-kafka/connect"
+	"github.com/go-kafka/connect/rollout"
+)
+
+var (
+	rollingRestartCmd *kingpin.CmdClause
+
+	batchSize      int
+	maxFailureRate float64
+	progressPath   string
+)
+
+func configureRolloutCommands(app *kingpin.Application) {
+	rollingRestartCmd = app.Command("rolling-restart", "Restarts connectors in batches, waiting for each batch to be healthy.")
+	addSelectorArgs(rollingRestartCmd)
+
+	rollingRestartCmd.Flag("batch-size", "How many connectors to restart at once.").
+		Default("5").
+		IntVar(&batchSize)
+	rollingRestartCmd.Flag("timeout", "How long to wait for a batch to be RUNNING before counting it as failed.").
+		Default("2m").
+		DurationVar(&stateWaitTimeout)
+	rollingRestartCmd.Flag("max-failure-rate", "Stop the rollout when more than this share of connectors, from 0 to 1, fail to recover.").
+		Default("0.1").
+		Float64Var(&maxFailureRate)
+	rollingRestartCmd.Flag("progress", "File to record progress in. If it exists, an interrupted rollout is resumed from it.").
+		Default("rolling-restart.json").
+		PlaceHolder("FILE").
+		StringVar(&progressPath)
+	rollingRestartCmd.Flag("dry-run", "List the connectors that would be restarted, in order, without restarting them.").
+		BoolVar(&dryRun)
+
+	// Reset state for in-process tests
+	batchSize, maxFailureRate, progressPath = 0, 0, ""
+}
+
+func rollingRestart(client *connect.Client) error {
+	progress, err := rollout.LoadProgress(progressPath)
+	switch {
+	case err == nil:
+		if !connectorSelector().IsEmpty() {
+			return fmt.Errorf("%v holds an interrupted rollout, resume it without a selection or remove it", progressPath)
+		}
+		fmt.Fprintf(os.Stderr, "Resuming rollout from %v, %d of %d connectors restarted.\n",
+			progressPath, len(progress.Restarted), len(progress.Connectors))
+	case os.IsNotExist(err):
+		names, err := client.SelectConnectors(connectorSelector())
+		if err != nil {
+			return err
+		}
+		progress = rollout.NewProgress(names)
+	default:
+		return err
+	}
+
+	if dryRun {
+		for _, name := range progress.Remaining() {
+			fmt.Println(name)
+		}
+		return nil
+	}
+
+	remaining := len(progress.Remaining())
+	if remaining == 0 {
+		fmt.Fprintln(os.Stderr, "No connectors to restart.")
+		return removeProgress()
+	}
+	if err := confirmDestructive(fmt.Sprintf("restart %d connectors", remaining)); err != nil {
+		return err
+	}
+
+	restarter := &rollout.Restarter{
+		Client:         client,
+		BatchSize:      batchSize,
+		Timeout:        stateWaitTimeout,
+		MaxFailureRate: maxFailureRate,
+		Report: func(results []rollout.Result) {
+			for _, result := range results {
+				if result.Skipped {
+					fmt.Printf("%v: skipped, paused or stopped\n", result.Name)
+				} else if result.Err != nil {
+					fmt.Printf("%v: failed: %v\n", result.Name, result.Err)
+				} else {
+					fmt.Printf("%v: restarted\n", result.Name)
+				}
+			}
+		},
+		Checkpoint: func(progress *rollout.Progress) error {
+			return progress.Save(progressPath)
+		},
+	}
+
+	if err := restarter.Run(progress); err != nil {
+		return fmt.Errorf("%v; progress is saved in %v, run again to resume", err, progressPath)
+	}
+
+	fmt.Printf("Restarted %d connectors, %d failed to recover", len(progress.Restarted), len(progress.Failed))
+	if len(progress.Skipped) > 0 {
+		fmt.Printf(", %d skipped", len(progress.Skipped))
+	}
+	fmt.Println(".")
+	if len(progress.Failed) > 0 {
+		// Keep the progress so the failures are retried on the next run
+		return fmt.Errorf("%d connectors failed to recover; progress is saved in %v, run again to retry them",
+			len(progress.Failed), progressPath)
+	}
+	return removeProgress()
+}
+
+// Removes the progress file of a finished rollout, which a rollout with
+// nothing to do never wrote.
+func removeProgress() error {
+	if err := os.Remove(progressPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
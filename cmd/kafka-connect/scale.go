@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/go-kafka/connect"
+)
+
+var (
+	scaleCmd *kingpin.CmdClause
+
+	scaleTasks      int
+	scalePartitions int
+	scaleForce      bool
+)
+
+func configureScaleCommand(app *kingpin.Application) {
+	scaleCmd = app.Command("scale", "Changes the number of tasks a connector runs.")
+	scaleCmd.Arg("name", "Name of the connector to scale.").Required().StringVar(&connName)
+	scaleCmd.Flag("tasks", "Number of tasks to run, the tasks.max setting.").
+		Required().
+		IntVar(&scaleTasks)
+	scaleCmd.Flag("partitions", "Partitions a sink connector consumes, when they can't be worked out from its offsets.").
+		IntVar(&scalePartitions)
+	scaleCmd.Flag("force", "Scale a sink connector beyond the partitions it consumes.").
+		BoolVar(&scaleForce)
+	scaleCmd.Flag("timeout", "How long to wait for the new tasks to show up.").
+		Default("1m").
+		DurationVar(&stateWaitTimeout)
+
+	// Reset state for in-process tests
+	scaleTasks, scalePartitions, scaleForce = 0, 0, false
+}
+
+func scaleConnector(name string, client *connect.Client) error {
+	if !scaleForce {
+		if err := checkPartitions(name, client); err != nil {
+			return err
+		}
+	}
+
+	tasks, err := client.ScaleConnector(name, scaleTasks, stateWaitTimeout, 0)
+	if err == connect.ErrTimeout {
+		fmt.Fprintf(os.Stderr, "Warning: %v is running %d tasks after %v, not %d.\n",
+			name, len(tasks), stateWaitTimeout, scaleTasks)
+	} else if err != nil {
+		return err
+	} else {
+		fmt.Printf("Scaled connector %v to %d tasks.\n", name, len(tasks))
+	}
+
+	status, _, err := client.GetConnectorStatus(name)
+	if err != nil {
+		return err
+	}
+	return writeTable(os.Stdout, workerSpreadRows(status))
+}
+
+// Refuses to scale a sink connector to more tasks than it has partitions to
+// consume, because the extra tasks would sit idle. Partition counts aren't in
+// the Connect API, so unless given --partitions they are estimated from the
+// partitions of the connector's active topics that have committed offsets,
+// which is a lower bound.
+func checkPartitions(name string, client *connect.Client) error {
+	config, _, err := client.GetConnectorConfig(name)
+	if err != nil {
+		return err
+	}
+	if config["topics"] == "" && config["topics.regex"] == "" {
+		return nil // Not a sink
+	}
+
+	if scalePartitions > 0 {
+		if scaleTasks > scalePartitions {
+			return fmt.Errorf("%v consumes %d partitions, so tasks beyond that would be idle; "+
+				"use --force to scale anyway", name, scalePartitions)
+		}
+		return nil
+	}
+
+	// Partitions without committed offsets yet are missed, so this is only a
+	// lower bound
+	partitions, err := countSinkPartitions(name, client)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not check %v's partitions against its task count: %v\n", name, err)
+		return nil
+	}
+	if partitions > 0 && scaleTasks > partitions {
+		return fmt.Errorf("%v has committed offsets for %d partitions, so tasks beyond that may be idle; "+
+			"it may consume more partitions that have no commits yet, "+
+			"give the real count with --partitions, or use --force to scale anyway", name, partitions)
+	}
+	return nil
+}
+
+func countSinkPartitions(name string, client *connect.Client) (int, error) {
+	offsets, _, err := client.GetConnectorOffsets(name)
+	if err != nil {
+		return 0, err
+	}
+
+	// Offsets may remain for topics the connector no longer consumes
+	var active map[string]bool
+	if topics, _, err := client.GetConnectorTopics(name); err == nil {
+		active = make(map[string]bool, len(topics))
+		for _, topic := range topics {
+			active[topic] = true
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, offset := range offsets.Offsets {
+		topic, _ := offset.Partition["kafka_topic"].(string)
+		if active != nil && !active[topic] {
+			continue
+		}
+		seen[fmt.Sprintf("%v/%v", topic, offset.Partition["kafka_partition"])] = true
+	}
+	return len(seen), nil
+}
+
+// Rows counting a connector's tasks on each worker.
+func workerSpreadRows(status *connect.ConnectorStatus) [][]string {
+	counts := make(map[string]int)
+	for _, task := range status.Tasks {
+		counts[task.WorkerID]++
+	}
+	workers := make([]string, 0, len(counts))
+	for worker := range counts {
+		workers = append(workers, worker)
+	}
+	sort.Strings(workers)
+
+	rows := [][]string{{"WORKER", "TASKS"}}
+	for _, worker := range workers {
+		rows = append(rows, []string{worker, strconv.Itoa(counts[worker])})
+	}
+	return rows
+}
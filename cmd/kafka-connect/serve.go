@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/go-kafka/connect"
+	"github.com/go-kafka/connect/gateway"
+)
+
+var (
+	serveCmd *kingpin.CmdClause
+
+	serveContexts                     []string
+	serveAuthPath, serveAuditPath     string
+	serveTLSCertPath, serveTLSKeyPath string
+)
+
+func configureServeCommand(app *kingpin.Application) {
+	serveCmd = app.Command("serve", "Serves one REST API in front of the clusters of several contexts.")
+	serveCmd.Flag("listen", "Address to serve the API on.").
+		Default(":8080").
+		StringVar(&listenAddr)
+	serveCmd.Flag("cluster", "Context whose cluster to serve, with the context name as its ID. Repeatable, all contexts by default.").
+		PlaceHolder("CONTEXT").
+		StringsVar(&serveContexts)
+	serveCmd.Flag("auth-file", "YAML file of users with their API keys and roles.").
+		Required().
+		PlaceHolder("FILE").
+		ExistingFileVar(&serveAuthPath)
+	serveCmd.Flag("audit-log", "File to append a JSON line to for each call that changes something, stderr by default.").
+		PlaceHolder("FILE").
+		StringVar(&serveAuditPath)
+	serveCmd.Flag("tls-cert", "Certificate file to serve HTTPS with, given with --tls-key.").
+		PlaceHolder("FILE").
+		ExistingFileVar(&serveTLSCertPath)
+	serveCmd.Flag("tls-key", "Private key file for --tls-cert.").
+		PlaceHolder("FILE").
+		ExistingFileVar(&serveTLSKeyPath)
+
+	// Reset state for in-process tests
+	serveContexts, serveAuthPath, serveAuditPath = nil, "", ""
+	serveTLSCertPath, serveTLSKeyPath = "", ""
+}
+
+// Returns clients for the served clusters by context name.
+func gatewayClusters() (map[string]*connect.Client, error) {
+	names := serveContexts
+	if len(names) == 0 {
+		for name := range cliConf.Contexts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no contexts to serve, define them in %v", configFilePath)
+	}
+
+	clusters := make(map[string]*connect.Client, len(names))
+	for _, name := range names {
+		ctx, ok := cliConf.Contexts[name]
+		if !ok {
+			return nil, fmt.Errorf("context %q is not defined in %v", name, configFilePath)
+		}
+		client, err := contextClient(name, ctx)
+		if err != nil {
+			return nil, err
+		}
+		clusters[name] = client
+	}
+	return clusters, nil
+}
+
+func runGateway() error {
+	auth, err := gateway.LoadAuth(serveAuthPath)
+	if err != nil {
+		return err
+	}
+	clusters, err := gatewayClusters()
+	if err != nil {
+		return err
+	}
+
+	var auditLog io.Writer = os.Stderr
+	if serveAuditPath != "" {
+		file, err := os.OpenFile(serveAuditPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		auditLog = file
+	}
+	var mu sync.Mutex
+	encoder := json.NewEncoder(auditLog)
+
+	gw := &gateway.Gateway{
+		Clusters: clusters,
+		Auth:     auth,
+		Audit: func(record gateway.AuditRecord) {
+			mu.Lock()
+			defer mu.Unlock()
+			if err := encoder.Encode(record); err != nil {
+				fmt.Fprintf(os.Stderr, "Writing audit log failed: %v\n", err)
+			}
+		},
+	}
+
+	ids := make([]string, 0, len(clusters))
+	for id := range clusters {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	fmt.Fprintf(os.Stderr, "Serving clusters %v on %v for %d users.\n", strings.Join(ids, ", "), listenAddr, len(auth.Users))
+
+	server := &http.Server{Addr: listenAddr, Handler: gw.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	if serveTLSCertPath != "" {
+		return server.ListenAndServeTLS(serveTLSCertPath, serveTLSKeyPath)
+	}
+	return server.ListenAndServe()
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/go-kafka/connect"
+)
+
+var (
+	setCmd, unsetCmd *kingpin.CmdClause
+
+	setSettings map[string]string
+	unsetKeys   []string
+	dryRun      bool
+)
+
+func configureSetCommands(app *kingpin.Application) {
+	setCmd = app.Command("set", "Sets individual config values of a connector.")
+	setCmd.Arg("name", "Name of the connector to change.").Required().StringVar(&connName)
+	setCmd.Arg("settings", "Config values to set, as KEY=VALUE.").Required().StringMapVar(&setSettings)
+
+	unsetCmd = app.Command("unset", "Removes individual config keys from a connector.")
+	unsetCmd.Arg("name", "Name of the connector to change.").Required().StringVar(&connName)
+	unsetCmd.Arg("keys", "Config keys to remove.").Required().StringsVar(&unsetKeys)
+
+	for _, command := range []*kingpin.CmdClause{setCmd, unsetCmd} {
+		command.Flag("dry-run", "Show what would change without changing it.").
+			BoolVar(&dryRun)
+		command.Flag("expect-hash", "Only change the config if it still has this hash, as shown by config.").
+			PlaceHolder("HASH").
+			StringVar(&expectedConfigHash)
+	}
+
+	setSettings, unsetKeys, dryRun = map[string]string{}, nil, false
+}
+
+func patchConnector(name string, set connect.ConnectorConfig, unset []string, client *connect.Client) error {
+	current, _, err := client.GetConnectorConfig(name)
+	if err != nil {
+		return err
+	}
+	if expectedConfigHash != "" && current.Hash() != expectedConfigHash {
+		return connect.ErrConcurrentModification
+	}
+
+	patched := current.Patch(set, unset)
+	diff := configDiff(current, patched)
+	if len(diff) == 0 {
+		fmt.Printf("No changes to connector %v.\n", name)
+		return nil
+	}
+	for _, line := range diff {
+		fmt.Println(line)
+	}
+	if dryRun {
+		return nil
+	}
+
+	if _, _, err := client.UpdateConnectorConfigIfMatch(name, patched, current.Hash()); err != nil {
+		return err
+	}
+	fmt.Printf("Updated connector %v.\n", name)
+	return nil
+}
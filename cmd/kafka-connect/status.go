@@ -0,0 +1,338 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kafka/connect"
+	"github.com/go-kafka/connect/hints"
+	"github.com/go-kafka/connect/notify"
+	"github.com/go-kafka/connect/trace"
+)
+
+// ANSI escapes for watch mode on a terminal.
+const (
+	clearScreen = "\033[H\033[2J"
+	highlight   = "\033[7m"
+	resetStyle  = "\033[0m"
+)
+
+var (
+	statusAll, statusFailedOnly, statusWatch bool
+	statusFullTrace                          bool
+	statusInterval                           time.Duration
+)
+
+func configureStatusFlags() {
+	statusCmd.Flag("all", "Show an overview of all connectors, the default without a name.").
+		BoolVar(&statusAll)
+	statusCmd.Flag("failed", "Only show connectors that are unhealthy, with the first line of task traces.").
+		BoolVar(&statusFailedOnly)
+	statusCmd.Flag("watch", "Redraw status periodically, highlighting changes.").
+		Short('w').
+		BoolVar(&statusWatch)
+	statusCmd.Flag("interval", "Refresh interval for --watch.").
+		Default("2s").
+		DurationVar(&statusInterval)
+	statusCmd.Flag("trace", "Show full stack traces of failures, rather than their root cause.").
+		BoolVar(&statusFullTrace)
+
+	statusAll, statusFailedOnly, statusWatch, statusInterval = false, false, false, 0
+	statusFullTrace = false
+}
+
+func showStatus(name string, client *connect.Client) error {
+	catalog, err := loadHints()
+	if err != nil {
+		return err
+	}
+
+	if statusWatch {
+		dispatcher, err := newDispatcher()
+		if err != nil {
+			return err
+		}
+		return watchStatus(name, catalog, dispatcher, client)
+	}
+
+	if name != "" {
+		// Stay with JSON by default for scripts, adding failures to tables
+		status, _, err := client.GetConnectorStatus(name)
+		if err != nil || outputSpec != outputTable {
+			return maybePrintAPIResult(status, nil, err)
+		}
+		var buf bytes.Buffer
+		if err := writeTable(&buf, detailRows(status)); err != nil {
+			return err
+		}
+		writeFailures(&buf, []connect.ConnectorStatus{*status}, catalog)
+		_, err = buf.WriteTo(os.Stdout)
+		return err
+	}
+
+	statuses, err := fetchOverview(client)
+	if err != nil {
+		return err
+	}
+
+	// An overview is for people, so default to a table rather than JSON
+	if outputSpec != "" {
+		return printOutput(statuses)
+	}
+	var buf bytes.Buffer
+	if err := renderOverview(&buf, statuses, nil, catalog); err != nil {
+		return err
+	}
+	_, err = buf.WriteTo(os.Stdout)
+	return err
+}
+
+func fetchOverview(client *connect.Client) ([]connect.ConnectorStatus, error) {
+	statuses, err := client.ListConnectorStatuses()
+	return onlyUnhealthy(statuses), err
+}
+
+// Filters statuses to unhealthy connectors if only failures are being shown.
+func onlyUnhealthy(statuses []connect.ConnectorStatus) []connect.ConnectorStatus {
+	if !statusFailedOnly {
+		return statuses
+	}
+
+	var failed []connect.ConnectorStatus
+	for _, status := range statuses {
+		if isUnhealthy(&status) {
+			failed = append(failed, status)
+		}
+	}
+	return failed
+}
+
+// Renders an overview table of statuses, followed by a summary of task traces
+// if only failures are being shown. Rows whose key is in changed are
+// highlighted.
+func renderOverview(buf *bytes.Buffer, statuses []connect.ConnectorStatus, changed map[string]bool, catalog *hints.Catalog) error {
+	if len(statuses) == 0 {
+		if statusFailedOnly {
+			_, err := fmt.Fprintln(buf, "No unhealthy connectors.")
+			return err
+		}
+		_, err := fmt.Fprintln(buf, "No connectors.")
+		return err
+	}
+
+	if err := writeHighlightedTable(buf, overviewRows(statuses), changed); err != nil {
+		return err
+	}
+	if !statusFailedOnly {
+		return nil
+	}
+
+	writeFailures(buf, statuses, catalog)
+	return nil
+}
+
+// Writes a line for each failed connector or task with the root cause of its
+// failure, or with --trace the whole stack trace, and any remediation hint.
+func writeFailures(buf *bytes.Buffer, statuses []connect.ConnectorStatus, catalog *hints.Catalog) {
+	describe := func(stackTrace string) string {
+		if statusFullTrace {
+			return "\n" + strings.TrimRight(stackTrace, "\n") + "\n"
+		}
+		return " " + trace.Summarize(stackTrace)
+	}
+
+	first := true
+	failure := func(format string, args ...interface{}) {
+		if first {
+			fmt.Fprintln(buf)
+			first = false
+		}
+		fmt.Fprintf(buf, format, args...)
+	}
+
+	for _, status := range statuses {
+		if status.Connector.State == "FAILED" {
+			failure("%v connector on %v:%v\n",
+				status.Name, status.Connector.WorkerID, describe(status.Connector.Trace))
+			writeHint(buf, catalog, status.Connector.Trace)
+		}
+		for _, task := range status.Tasks {
+			if task.State == "FAILED" {
+				failure("%v task %d on %v:%v\n",
+					status.Name, task.ID, task.WorkerID, describe(task.Trace))
+				writeHint(buf, catalog, task.Trace)
+			}
+		}
+	}
+}
+
+// Writes rows as a table, highlighting rows whose key is in changed. Keys are
+// the row's identifying cells, see rowKey.
+func writeHighlightedTable(buf *bytes.Buffer, rows [][]string, changed map[string]bool) error {
+	var table bytes.Buffer
+	if err := writeTable(&table, rows); err != nil {
+		return err
+	}
+
+	lines := strings.SplitAfter(table.String(), "\n")
+	for i, line := range lines {
+		if i > 0 && i < len(rows) && changed[rowKey(rows[i])] {
+			line = highlight + strings.TrimSuffix(line, "\n") + resetStyle + "\n"
+		}
+		buf.WriteString(line)
+	}
+	return nil
+}
+
+// Identifies a row across refreshes: the connector name, and for detail rows
+// also the type and task ID.
+func rowKey(row []string) string {
+	if len(row) == 5 {
+		return strings.Join(row[:3], "/")
+	}
+	return row[0]
+}
+
+// Redraws status every interval. Given a dispatcher, it is sent an event for
+// each change of state since the first refresh.
+func watchStatus(name string, catalog *hints.Catalog, dispatcher *notify.Dispatcher, client *connect.Client) error {
+	// Without a terminal to redraw, print a plain snapshot every interval
+	terminal := isatty(os.Stdout)
+	previous := make(map[string]string)
+	var seen []connect.ConnectorStatus
+	var watching bool
+
+	for {
+		var buf bytes.Buffer
+		var rows [][]string
+		var all, statuses []connect.ConnectorStatus
+		var err error
+
+		if name != "" {
+			var status *connect.ConnectorStatus
+			if status, _, err = client.GetConnectorStatus(name); err == nil {
+				rows = detailRows(status)
+				all = []connect.ConnectorStatus{*status}
+			}
+		} else if all, err = client.ListConnectorStatuses(); err == nil {
+			statuses = onlyUnhealthy(all)
+			rows = overviewRows(statuses)
+		}
+
+		// Changes are found among all connectors, so that one recovering
+		// isn't mistaken for one deleted when only failures are shown
+		if err == nil && dispatcher != nil {
+			if watching {
+				for _, event := range notify.Diff(seen, all, time.Now()) {
+					sendNotification(dispatcher, event)
+				}
+			}
+			seen, watching = all, true
+		}
+
+		if terminal {
+			buf.WriteString(clearScreen)
+		}
+		fmt.Fprintf(&buf, "Every %v: %v\n\n", statusInterval, time.Now().Format("2006-01-02 15:04:05"))
+
+		if err != nil {
+			// Keep watching through transient errors, e.g. during a rebalance
+			fmt.Fprintf(&buf, "Error: %v\n", err)
+		} else {
+			changed := make(map[string]bool)
+			current := make(map[string]string, len(rows))
+			for _, row := range rows[1:] {
+				key, line := rowKey(row), strings.Join(row, "\t")
+				current[key] = line
+				if old, ok := previous[key]; terminal && len(previous) > 0 && (!ok || old != line) {
+					changed[key] = true
+				}
+			}
+			previous = current
+
+			if name != "" {
+				err = writeHighlightedTable(&buf, rows, changed)
+			} else {
+				err = renderOverview(&buf, statuses, changed, catalog)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if _, err := buf.WriteTo(os.Stdout); err != nil {
+			return err
+		}
+		time.Sleep(statusInterval)
+	}
+}
+
+// Rows summarizing many connectors, one each with task state counts.
+func overviewRows(statuses []connect.ConnectorStatus) [][]string {
+	rows := [][]string{{"NAME", "STATE", "TASKS", "WORKER"}}
+	for _, status := range statuses {
+		rows = append(rows, []string{
+			status.Name,
+			status.Connector.State,
+			taskSummary(status.Tasks),
+			status.Connector.WorkerID,
+		})
+	}
+	return rows
+}
+
+// Rows detailing a connector and each of its tasks.
+func detailRows(status *connect.ConnectorStatus) [][]string {
+	rows := [][]string{{"NAME", "TYPE", "ID", "STATE", "WORKER"}}
+	rows = append(rows, []string{status.Name, "connector", "-", status.Connector.State, status.Connector.WorkerID})
+	for _, task := range status.Tasks {
+		rows = append(rows, []string{status.Name, "task", strconv.Itoa(task.ID), task.State, task.WorkerID})
+	}
+	return rows
+}
+
+// Summarizes task states as counts, e.g. "3 RUNNING, 1 FAILED".
+func taskSummary(tasks []connect.TaskState) string {
+	if len(tasks) == 0 {
+		return "-"
+	}
+
+	counts := make(map[string]int)
+	for _, task := range tasks {
+		counts[task.State]++
+	}
+	states := make([]string, 0, len(counts))
+	for state := range counts {
+		states = append(states, state)
+	}
+	sort.Strings(states)
+
+	parts := make([]string, len(states))
+	for i, state := range states {
+		parts[i] = fmt.Sprintf("%d %v", counts[state], state)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// A connector is unhealthy if it or any task is failed, or not yet assigned to
+// a worker. Paused and stopped connectors are there by choice.
+func isUnhealthy(status *connect.ConnectorStatus) bool {
+	unhealthy := func(state string) bool {
+		return state == "FAILED" || state == "UNASSIGNED"
+	}
+
+	if unhealthy(status.Connector.State) {
+		return true
+	}
+	for _, task := range status.Tasks {
+		if unhealthy(task.State) {
+			return true
+		}
+	}
+	return false
+}
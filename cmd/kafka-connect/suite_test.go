@@ -1,6 +1,7 @@
 package main_test
 
 import (
+	"os"
 	"testing"
 
 	. "github.com/onsi/ginkgo"
@@ -18,6 +19,9 @@ func TestKafkaConnectCLI(t *testing.T) {
 var _ = BeforeSuite(func() {
 	var err error
 
+	// Isolate tests from any contexts configured by the developer
+	Expect(os.Setenv("KAFKA_CONNECT_CLI_CONFIG", os.DevNull)).To(Succeed())
+
 	// Build the executable in a sandbox
 	pathToCLI, err = gexec.Build("github.com/go-kafka/connect/cmd/kafka-connect")
 	Expect(err).NotTo(HaveOccurred())
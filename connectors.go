@@ -1,9 +1,12 @@
 package connect
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net/http"
+	"sort"
 )
 
 // A Connector represents a Kafka Connect connector instance.
@@ -21,6 +24,40 @@ type Connector struct {
 // See: http://docs.confluent.io/current/connect/userguide.html#configuring-connectors
 type ConnectorConfig map[string]string
 
+// Hash returns a stable fingerprint of the config, suitable for detecting
+// whether it has changed between reads. Key order does not affect the result.
+func (c ConnectorConfig) Hash() string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, k := range keys {
+		// Length-prefix both parts so that e.g. {"a": "bc"} and {"ab": "c"}
+		// can't collide.
+		fmt.Fprintf(h, "%d:%s%d:%s", len(k), k, len(c[k]), c[k])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Patch returns a copy of the config with the values in set added or replaced,
+// and the keys in unset removed.
+func (c ConnectorConfig) Patch(set ConnectorConfig, unset []string) ConnectorConfig {
+	result := make(ConnectorConfig, len(c)+len(set))
+	for k, v := range c {
+		result[k] = v
+	}
+	for _, k := range unset {
+		delete(result, k)
+	}
+	for k, v := range set {
+		result[k] = v
+	}
+	return result
+}
+
 // A Task is a unit of work dispatched by a Connector to parallelize the work of
 // a data copy job.
 //
@@ -45,6 +82,9 @@ type ConnectorStatus struct {
 	Name      string         `json:"name"`
 	Connector ConnectorState `json:"connector"`
 	Tasks     []TaskState    `json:"tasks"`
+
+	// Source or sink, from Kafka 2.0.
+	Type string `json:"type,omitempty"`
 }
 
 // ConnectorState reflects the running state of a Connector and the worker where
@@ -52,6 +92,7 @@ type ConnectorStatus struct {
 type ConnectorState struct {
 	State    string `json:"state"`
 	WorkerID string `json:"worker_id"`
+	Trace    string `json:"trace,omitempty"`
 }
 
 // TaskState reflects the running state of a Task and the worker where it is
@@ -111,6 +152,29 @@ func (c *Client) GetConnectorConfig(name string) (ConnectorConfig, *http.Respons
 	return config, response, err
 }
 
+// ListConnectorStatuses retrieves the status of every active connector, sorted
+// by name. Connectors deleted while statuses are being collected are omitted.
+func (c *Client) ListConnectorStatuses() ([]ConnectorStatus, error) {
+	names, _, err := c.ListConnectors()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	statuses := make([]ConnectorStatus, 0, len(names))
+	for _, name := range names {
+		status, response, err := c.GetConnectorStatus(name)
+		if err != nil {
+			if response != nil && response.StatusCode == http.StatusNotFound {
+				continue
+			}
+			return nil, err
+		}
+		statuses = append(statuses, *status)
+	}
+	return statuses, nil
+}
+
 // GetConnectorTasks retrieves a list of tasks currently running for a connector
 // with the given name.
 //
@@ -148,6 +212,60 @@ func (c *Client) UpdateConnectorConfig(name string, config ConnectorConfig) (*Co
 	return connector, response, err
 }
 
+// UpdateConnectorConfigIfMatch updates configuration for a connector like
+// UpdateConnectorConfig, but only if its live configuration still has the given
+// Hash. If it does not, ErrConcurrentModification is returned and no update is
+// made.
+//
+// Connect has no native versioning of configs, so this narrows the window for
+// lost updates rather than closing it entirely.
+func (c *Client) UpdateConnectorConfigIfMatch(name string, config ConnectorConfig, hash string) (*Connector, *http.Response, error) {
+	current, response, err := c.GetConnectorConfig(name)
+	if err != nil {
+		return new(Connector), response, err
+	}
+	if current.Hash() != hash {
+		return new(Connector), response, ErrConcurrentModification
+	}
+	return c.UpdateConnectorConfig(name, config)
+}
+
+// PatchConnectorConfig changes individual values of a connector's config,
+// setting those in set and removing the keys in unset, returning the new state
+// of the Connector. It is a read-modify-write guarded like
+// UpdateConnectorConfigIfMatch, so it fails with ErrConcurrentModification
+// rather than overwrite a change made by someone else in between.
+func (c *Client) PatchConnectorConfig(name string, set ConnectorConfig, unset []string) (*Connector, *http.Response, error) {
+	current, response, err := c.GetConnectorConfig(name)
+	if err != nil {
+		return new(Connector), response, err
+	}
+	return c.UpdateConnectorConfigIfMatch(name, current.Patch(set, unset), current.Hash())
+}
+
+// UpsertConnector creates a connector if one by the name of conn does not exist,
+// or otherwise updates its configuration. created reports which of these
+// happened. If successful, conn is updated with the connector's state returned
+// by the API, including Tasks.
+//
+// As with CreateConnector, passing an object that already contains Tasks
+// produces an error.
+func (c *Client) UpsertConnector(conn *Connector) (created bool, response *http.Response, err error) {
+	if len(conn.Tasks) != 0 {
+		return false, nil, errors.New("Cannot upsert Connector with existing Tasks")
+	}
+	if conn.Name == "" {
+		return false, nil, errors.New("Cannot upsert Connector without a name")
+	}
+
+	result, response, err := c.UpdateConnectorConfig(conn.Name, conn.Config)
+	if err != nil {
+		return false, response, err
+	}
+	*conn = *result
+	return response.StatusCode == http.StatusCreated, response, nil
+}
+
 // DeleteConnector deletes a connector with the given name, halting all tasks
 // and deleting its configuration.
 //
@@ -182,3 +300,43 @@ func (c *Client) RestartConnector(name string) (*http.Response, error) {
 	path := fmt.Sprintf("connectors/%v/restart", name)
 	return c.doRequest("POST", path, nil, nil)
 }
+
+// RestartConnectorAndTasks restarts a connector instance along with its tasks,
+// or if onlyFailed is set, only those of them that have failed. Workers before
+// Kafka 3.0 ignore the options and restart only the connector instance.
+//
+// See: https://docs.confluent.io/current/connect/references/restapi.html#post--connectors-(string-name)-restart
+func (c *Client) RestartConnectorAndTasks(name string, onlyFailed bool) (*http.Response, error) {
+	path := fmt.Sprintf("connectors/%v/restart?includeTasks=true&onlyFailed=%v", name, onlyFailed)
+	return c.doRequest("POST", path, nil, nil)
+}
+
+// RestartTask restarts an individual task of a connector.
+//
+// See: https://docs.confluent.io/current/connect/references/restapi.html#post--connectors-(string-name)-tasks-(int-taskid)-restart
+func (c *Client) RestartTask(name string, id int) (*http.Response, error) {
+	path := fmt.Sprintf("connectors/%v/tasks/%d/restart", name, id)
+	return c.doRequest("POST", path, nil, nil)
+}
+
+// StopConnector stops a connector and shuts down its tasks, but unlike pausing
+// it also releases their task assignments. Requires Kafka 3.5 or later.
+//
+// See: https://docs.confluent.io/current/connect/references/restapi.html#put--connectors-(string-name)-stop
+func (c *Client) StopConnector(name string) (*http.Response, error) {
+	path := fmt.Sprintf("connectors/%v/stop", name)
+	return c.doRequest("PUT", path, nil, nil)
+}
+
+// SetConnectorState pauses or stops a connector if state is PAUSED or STOPPED,
+// or otherwise resumes it, e.g. to put it back in a state seen earlier. Like
+// the calls it makes, it returns before the connector has changed state.
+func (c *Client) SetConnectorState(name, state string) (*http.Response, error) {
+	switch state {
+	case "PAUSED":
+		return c.PauseConnector(name)
+	case "STOPPED":
+		return c.StopConnector(name)
+	}
+	return c.ResumeConnector(name)
+}
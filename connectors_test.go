@@ -128,6 +128,34 @@ var _ = Describe("Connector CRUD", func() {
 		})
 	})
 
+	Describe("ListConnectorStatuses", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `["b", "gone", "a"]`),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/connectors/a/status"),
+					ghttp.RespondWith(http.StatusOK, `{"name":"a","connector":{"state":"RUNNING"}}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/connectors/b/status"),
+					ghttp.RespondWith(http.StatusOK, `{"name":"b","connector":{"state":"PAUSED"}}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/connectors/gone/status"),
+					ghttp.RespondWith(http.StatusNotFound, `{"error_code":404,"message":"not found"}`),
+				),
+			)
+		})
+
+		It("returns statuses sorted by name, skipping deleted connectors", func() {
+			statuses, err := client.ListConnectorStatuses()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(statuses).To(HaveLen(2))
+			Expect(statuses[0].Name).To(Equal("a"))
+			Expect(statuses[1].Connector.State).To(Equal("PAUSED"))
+		})
+	})
+
 	Describe("GetConnector", func() {
 		var resultConnector interface{}
 		var statusCode int
@@ -373,6 +401,127 @@ var _ = Describe("Connector CRUD", func() {
 		})
 	})
 
+	Describe("UpdateConnectorConfigIfMatch", func() {
+		var liveConfig ConnectorConfig
+
+		BeforeEach(func() {
+			liveConfig = ConnectorConfig{"connector.class": "FileStreamSource", "tasks.max": "1"}
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/connectors/local-file-source/config"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, liveConfig),
+				),
+			)
+		})
+
+		Context("when the live config matches the expected hash", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/connectors/local-file-source/config"),
+						ghttp.VerifyJSONRepresenting(fileSourceConfig),
+						ghttp.RespondWithJSONEncoded(http.StatusOK, Connector{
+							Name:   "local-file-source",
+							Config: fileSourceConfig,
+						}),
+					),
+				)
+			})
+
+			It("updates the connector", func() {
+				connector, resp, err := client.UpdateConnectorConfigIfMatch(
+					"local-file-source", fileSourceConfig, liveConfig.Hash())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+				Expect(connector.Config).To(Equal(fileSourceConfig))
+				Expect(server.ReceivedRequests()).To(HaveLen(2))
+			})
+		})
+
+		Context("when the live config has changed", func() {
+			It("returns ErrConcurrentModification without updating", func() {
+				stale := ConnectorConfig{"connector.class": "FileStreamSource", "tasks.max": "2"}
+				_, _, err := client.UpdateConnectorConfigIfMatch(
+					"local-file-source", fileSourceConfig, stale.Hash())
+				Expect(err).To(Equal(ErrConcurrentModification))
+				Expect(server.ReceivedRequests()).To(HaveLen(1))
+			})
+		})
+	})
+
+	Describe("PatchConnectorConfig", func() {
+		BeforeEach(func() {
+			current := `{"connector.class":"FileStreamSource","tasks.max":"1","topic":"old"}`
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, current),
+				ghttp.RespondWith(http.StatusOK, current),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/connectors/local-file-source/config"),
+					ghttp.VerifyJSON(`{"connector.class":"FileStreamSource","tasks.max":"4"}`),
+					ghttp.RespondWith(http.StatusOK, `{"name":"local-file-source"}`),
+				),
+			)
+		})
+
+		It("sets and removes individual values", func() {
+			_, resp, err := client.PatchConnectorConfig("local-file-source",
+				ConnectorConfig{"tasks.max": "4"}, []string{"topic"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		})
+	})
+
+	Describe("UpsertConnector", func() {
+		var connector, resultConnector Connector
+		var statusCode int
+
+		BeforeEach(func() {
+			connector = Connector{Name: "local-file-source", Config: fileSourceConfig}
+			resultConnector = Connector{
+				Name:   "local-file-source",
+				Config: fileSourceConfig,
+				Tasks:  []TaskID{{"local-file-source", 0}},
+			}
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/connectors/local-file-source/config"),
+					ghttp.VerifyJSONRepresenting(fileSourceConfig),
+					ghttp.RespondWithJSONEncodedPtr(&statusCode, &resultConnector),
+				),
+			)
+		})
+
+		Context("when the connector does not exist", func() {
+			BeforeEach(func() { statusCode = http.StatusCreated })
+
+			It("reports creation and updates reference", func() {
+				created, _, err := client.UpsertConnector(&connector)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(created).To(BeTrue())
+				Expect(connector).To(Equal(resultConnector))
+			})
+		})
+
+		Context("when the connector exists", func() {
+			BeforeEach(func() { statusCode = http.StatusOK })
+
+			It("reports an update", func() {
+				created, _, err := client.UpsertConnector(&connector)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(created).To(BeFalse())
+			})
+		})
+
+		Context("when a Connector without a name is given", func() {
+			It("returns an error", func() {
+				connector.Name = ""
+				_, _, err := client.UpsertConnector(&connector)
+				Expect(err).To(MatchError("Cannot upsert Connector without a name"))
+			})
+		})
+	})
+
 	Describe("DeleteConnector", func() {
 		var statusCode int
 
@@ -510,6 +659,66 @@ var _ = Describe("Connector Lifecycle", func() {
 		})
 	})
 
+	Describe("RestartConnectorAndTasks", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/connectors/local-file-source/restart", "includeTasks=true&onlyFailed=true"),
+					ghttp.RespondWith(http.StatusAccepted, `{"name":"local-file-source","connector":{"state":"RESTARTING"},"tasks":[]}`),
+				),
+			)
+		})
+
+		It("restarts connector and tasks", func() {
+			resp, err := client.RestartConnectorAndTasks("local-file-source", true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusAccepted))
+		})
+	})
+
+	Describe("RestartTask", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/connectors/local-file-source/tasks/1/restart"),
+					ghttp.RespondWith(http.StatusNoContent, nil),
+				),
+			)
+		})
+
+		It("restarts the task", func() {
+			resp, err := client.RestartTask("local-file-source", 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+		})
+	})
+
+	Describe("StopConnector", func() {
+		var statusCode int
+
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/connectors/local-file-source/stop"),
+					ghttp.VerifyHeader(jsonAcceptHeader),
+					ghttp.RespondWithPtr(&statusCode, nil),
+				),
+			)
+		})
+
+		Context("when existing connector name is given", func() {
+			BeforeEach(func() {
+				statusCode = http.StatusAccepted
+			})
+
+			It("stops connector", func() {
+				resp, err := client.StopConnector("local-file-source")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusAccepted))
+			})
+		})
+	})
+
 	Describe("RestartConnector", func() {
 		var statusCode int
 
@@ -561,3 +770,34 @@ var _ = Describe("Connector Lifecycle", func() {
 		})
 	})
 })
+
+var _ = Describe("ConnectorConfig", func() {
+	Describe("Patch", func() {
+		It("returns a changed copy", func() {
+			config := ConnectorConfig{"a": "1", "b": "2"}
+			patched := config.Patch(ConnectorConfig{"a": "9", "c": "3"}, []string{"b", "nope"})
+			Expect(patched).To(Equal(ConnectorConfig{"a": "9", "c": "3"}))
+			Expect(config).To(Equal(ConnectorConfig{"a": "1", "b": "2"}))
+		})
+	})
+
+	Describe("Hash", func() {
+		It("is stable for equal configs", func() {
+			a := ConnectorConfig{"tasks.max": "1", "topic": "test"}
+			b := ConnectorConfig{"topic": "test", "tasks.max": "1"}
+			Expect(a.Hash()).To(Equal(b.Hash()))
+		})
+
+		It("differs when a value changes", func() {
+			a := ConnectorConfig{"tasks.max": "1"}
+			b := ConnectorConfig{"tasks.max": "2"}
+			Expect(a.Hash()).NotTo(Equal(b.Hash()))
+		})
+
+		It("is not fooled by shifting key/value boundaries", func() {
+			a := ConnectorConfig{"a": "bc"}
+			b := ConnectorConfig{"ab": "c"}
+			Expect(a.Hash()).NotTo(Equal(b.Hash()))
+		})
+	})
+})
@@ -0,0 +1,249 @@
+// Package doctor examines the health of a whole Connect cluster: that its
+// workers respond, its connectors and tasks run and are evenly spread, and that
+// their configs pass lint.
+package doctor
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/go-kafka/connect"
+	"github.com/go-kafka/connect/hints"
+	"github.com/go-kafka/connect/lint"
+	"github.com/go-kafka/connect/trace"
+)
+
+// Names of the checks, in the order they are run.
+const (
+	CheckWorkers  = "workers-reachable"
+	CheckRunning  = "connectors-running"
+	CheckAssigned = "tasks-assigned"
+	CheckBalance  = "balance"
+	CheckLint     = "lint"
+)
+
+var descriptions = map[string]string{
+	CheckWorkers:  "Every worker responds to the REST API.",
+	CheckRunning:  "Every connector and task is RUNNING.",
+	CheckAssigned: "No connector or task is UNASSIGNED.",
+	CheckBalance:  "Tasks are spread evenly across workers.",
+	CheckLint:     "Connector configs pass lint.",
+}
+
+// A Finding is a problem a check found with a worker, connector or task.
+type Finding struct {
+	// What the finding is about: a worker, connector name, or connector/task.
+	Subject string `json:"subject"`
+	Message string `json:"message"`
+
+	// Remediation hint for failures matching a known error.
+	Hint string `json:"hint,omitempty"`
+	Link string `json:"link,omitempty"`
+}
+
+// A Check is the outcome of one check.
+type Check struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Findings    []Finding `json:"findings"`
+
+	// Why the check couldn't be carried out, if it couldn't. A check with an
+	// error has not passed.
+	Error string `json:"error,omitempty"`
+}
+
+// Passed reports whether the check ran and found nothing wrong.
+func (c Check) Passed() bool {
+	return c.Error == "" && len(c.Findings) == 0
+}
+
+// A Report is the outcome of all the checks.
+type Report struct {
+	Checks []Check `json:"checks"`
+}
+
+// Healthy reports whether every check passed.
+func (r *Report) Healthy() bool {
+	for i := range r.Checks {
+		if !r.Checks[i].Passed() {
+			return false
+		}
+	}
+	return true
+}
+
+// Failed returns the checks that didn't pass.
+func (r *Report) Failed() []Check {
+	var failed []Check
+	for _, c := range r.Checks {
+		if !c.Passed() {
+			failed = append(failed, c)
+		}
+	}
+	return failed
+}
+
+// A Doctor examines a cluster.
+type Doctor struct {
+	// Client for the cluster API.
+	Client *connect.Client
+
+	// A client for each worker to check responds. If empty, only Client is.
+	Workers []*connect.Client
+
+	// Ratio to the mean task count beyond which a worker is unbalanced. If
+	// zero, connect.DefaultMaxSkew is used.
+	MaxSkew float64
+
+	// Linter for configs, and the severity from which its findings count. If
+	// nil, lint.Default() is used, and if zero, lint.Error.
+	Linter       *lint.Linter
+	LintSeverity lint.Severity
+
+	// Hints for failures. If nil, hints.Builtin() is used.
+	Hints *hints.Catalog
+}
+
+// Examine runs every check and reports on them. Checks that can't be carried
+// out, for instance because the cluster can't be reached, are reported with
+// an error rather than stopping the examination.
+func (d *Doctor) Examine() *Report {
+	report := &Report{}
+	for _, name := range []string{CheckWorkers, CheckRunning, CheckAssigned, CheckBalance, CheckLint} {
+		report.Checks = append(report.Checks, Check{Name: name, Description: descriptions[name], Findings: []Finding{}})
+	}
+	workers, running, assigned, balance, linted :=
+		&report.Checks[0], &report.Checks[1], &report.Checks[2], &report.Checks[3], &report.Checks[4]
+
+	d.checkWorkers(workers)
+
+	statuses, err := d.Client.ListConnectorStatuses()
+	if err != nil {
+		for _, c := range []*Check{running, assigned, balance, linted} {
+			c.Error = fmt.Sprintf("could not get connector statuses: %v", err)
+		}
+		return report
+	}
+
+	d.checkStates(running, assigned, statuses)
+	configs := d.fetchConfigs(linted, statuses)
+	d.checkBalance(balance, statuses, configs)
+	d.checkLint(linted, configs)
+	return report
+}
+
+func (d *Doctor) checkWorkers(c *Check) {
+	workers := d.Workers
+	if len(workers) == 0 {
+		workers = []*connect.Client{d.Client}
+	}
+	for _, w := range workers {
+		if _, _, err := w.GetServerInfo(); err != nil {
+			c.Findings = append(c.Findings, Finding{Subject: w.Host(), Message: err.Error()})
+		}
+	}
+}
+
+func (d *Doctor) checkStates(running, assigned *Check, statuses []connect.ConnectorStatus) {
+	catalog := d.Hints
+	if catalog == nil {
+		catalog = hints.Builtin()
+	}
+
+	finding := func(subject, state, stackTrace string) {
+		if state == "UNASSIGNED" {
+			assigned.Findings = append(assigned.Findings, Finding{Subject: subject, Message: "UNASSIGNED"})
+			return
+		}
+
+		f := Finding{Subject: subject, Message: state}
+		if stackTrace != "" {
+			f.Message += ": " + trace.Summarize(stackTrace)
+			if rule := catalog.MatchTrace(stackTrace); rule != nil {
+				f.Hint, f.Link = rule.Hint, rule.Link
+			}
+		}
+		running.Findings = append(running.Findings, f)
+	}
+
+	for _, status := range statuses {
+		if status.Connector.State != "RUNNING" {
+			finding(status.Name, status.Connector.State, status.Connector.Trace)
+		}
+		for _, task := range status.Tasks {
+			if task.State != "RUNNING" {
+				finding(fmt.Sprintf("%v/%d", status.Name, task.ID), task.State, task.Trace)
+			}
+		}
+	}
+}
+
+// Fetches the config of each connector, noting those that can't be fetched
+// as lint findings since they can't be linted.
+func (d *Doctor) fetchConfigs(c *Check, statuses []connect.ConnectorStatus) map[string]connect.ConnectorConfig {
+	configs := make(map[string]connect.ConnectorConfig, len(statuses))
+	for _, status := range statuses {
+		config, response, err := d.Client.GetConnectorConfig(status.Name)
+		if response != nil && response.StatusCode == http.StatusNotFound {
+			continue // Deleted since listing
+		}
+		if err != nil {
+			c.Findings = append(c.Findings, Finding{Subject: status.Name, Message: fmt.Sprintf("could not get config: %v", err)})
+			continue
+		}
+		configs[status.Name] = config
+	}
+	return configs
+}
+
+func (d *Doctor) checkBalance(c *Check, statuses []connect.ConnectorStatus, configs map[string]connect.ConnectorConfig) {
+	classes := make(map[string]string, len(configs))
+	for name, config := range configs {
+		classes[name] = config["connector.class"]
+	}
+
+	report := connect.Balance(statuses, classes, d.MaxSkew)
+	for _, w := range report.Workers {
+		var load string
+		switch {
+		case w.Overloaded:
+			load = "overloaded"
+		case w.Underloaded:
+			load = "underloaded"
+		default:
+			continue
+		}
+		c.Findings = append(c.Findings, Finding{
+			Subject: w.WorkerID,
+			Message: fmt.Sprintf("%v with %d tasks, against a mean of %.1f", load, len(w.Tasks), report.MeanTasks),
+		})
+	}
+}
+
+func (d *Doctor) checkLint(c *Check, configs map[string]connect.ConnectorConfig) {
+	linter, severity := d.Linter, d.LintSeverity
+	if linter == nil {
+		linter = lint.Default()
+	}
+	if severity == 0 {
+		severity = lint.Error
+	}
+
+	for _, name := range sortedNames(configs) {
+		for _, f := range linter.Lint(configs[name]) {
+			if f.Severity >= severity {
+				c.Findings = append(c.Findings, Finding{Subject: name, Message: f.String()})
+			}
+		}
+	}
+}
+
+func sortedNames(configs map[string]connect.ConnectorConfig) []string {
+	names := make([]string, 0, len(configs))
+	for name := range configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,115 @@
+package doctor_test
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	"github.com/go-kafka/connect"
+	. "github.com/go-kafka/connect/doctor"
+)
+
+var _ = Describe("Doctor", func() {
+	var server *ghttp.Server
+	var doctor *Doctor
+
+	check := func(report *Report, name string) Check {
+		for _, c := range report.Checks {
+			if c.Name == name {
+				return c
+			}
+		}
+		Fail("no check " + name)
+		return Check{}
+	}
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		server.RouteToHandler("GET", "/", ghttp.RespondWith(http.StatusOK, `{"version":"3.7.0"}`))
+		server.RouteToHandler("GET", "/connectors", ghttp.RespondWith(http.StatusOK, `["a","b"]`))
+		server.RouteToHandler("GET", "/connectors/a/config", ghttp.RespondWith(http.StatusOK,
+			`{"connector.class":"org.example.ASink","tasks.max":"2","topics":"x"}`))
+		server.RouteToHandler("GET", "/connectors/b/config", ghttp.RespondWith(http.StatusOK,
+			`{"connector.class":"org.example.BSink","tasks.max":"1","topics":"y"}`))
+		server.RouteToHandler("GET", "/connectors/a/status", ghttp.RespondWith(http.StatusOK,
+			`{"name":"a","connector":{"state":"RUNNING","worker_id":"w1"},"tasks":[
+				{"id":0,"state":"RUNNING","worker_id":"w1"},{"id":1,"state":"RUNNING","worker_id":"w2"}]}`))
+		server.RouteToHandler("GET", "/connectors/b/status", ghttp.RespondWith(http.StatusOK,
+			`{"name":"b","connector":{"state":"RUNNING","worker_id":"w2"},"tasks":[{"id":0,"state":"RUNNING","worker_id":"w1"}]}`))
+		doctor = &Doctor{Client: connect.NewClient(server.URL())}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("passes a healthy cluster", func() {
+		report := doctor.Examine()
+		Expect(report.Checks).To(HaveLen(5))
+		Expect(report.Healthy()).To(BeTrue())
+		Expect(report.Failed()).To(BeEmpty())
+	})
+
+	It("reports unreachable workers", func() {
+		down := ghttp.NewServer()
+		downURL := down.URL()
+		down.Close()
+		doctor.Workers = []*connect.Client{connect.NewClient(server.URL()), connect.NewClient(downURL)}
+
+		report := doctor.Examine()
+		Expect(report.Healthy()).To(BeFalse())
+		findings := check(report, CheckWorkers).Findings
+		Expect(findings).To(HaveLen(1))
+		Expect(findings[0].Subject).To(Equal(downURL))
+	})
+
+	It("reports failed and unassigned tasks with hints", func() {
+		server.RouteToHandler("GET", "/connectors/b/status", ghttp.RespondWith(http.StatusOK,
+			`{"name":"b","connector":{"state":"PAUSED","worker_id":"w2"},"tasks":[
+				{"id":0,"state":"FAILED","worker_id":"w1","trace":"org.apache.kafka.common.errors.TopicAuthorizationException: Not authorized to access topics: [y]"},
+				{"id":1,"state":"UNASSIGNED","worker_id":""}]}`))
+
+		report := doctor.Examine()
+		running := check(report, CheckRunning)
+		Expect(running.Findings).To(HaveLen(2))
+		Expect(running.Findings[0]).To(Equal(Finding{Subject: "b", Message: "PAUSED"}))
+		Expect(running.Findings[1].Subject).To(Equal("b/0"))
+		Expect(running.Findings[1].Message).To(Equal("FAILED: org.apache.kafka.common.errors.TopicAuthorizationException: Not authorized to access topics: [y]"))
+		Expect(running.Findings[1].Hint).To(ContainSubstring("ACLs"))
+
+		Expect(check(report, CheckAssigned).Findings).To(Equal([]Finding{{Subject: "b/1", Message: "UNASSIGNED"}}))
+	})
+
+	It("reports unbalanced workers", func() {
+		server.RouteToHandler("GET", "/connectors/a/status", ghttp.RespondWith(http.StatusOK,
+			`{"name":"a","connector":{"state":"RUNNING","worker_id":"w1"},"tasks":[
+				{"id":0,"state":"RUNNING","worker_id":"w1"},{"id":1,"state":"RUNNING","worker_id":"w1"},
+				{"id":2,"state":"RUNNING","worker_id":"w1"},{"id":3,"state":"RUNNING","worker_id":"w1"}]}`))
+
+		balance := check(doctor.Examine(), CheckBalance)
+		Expect(balance.Findings).To(HaveLen(2))
+		Expect(balance.Findings[0].Message).To(Equal("overloaded with 5 tasks, against a mean of 2.5"))
+		Expect(balance.Findings[1].Message).To(Equal("underloaded with 0 tasks, against a mean of 2.5"))
+	})
+
+	It("reports lint findings at the given severity", func() {
+		server.RouteToHandler("GET", "/connectors/b/config", ghttp.RespondWith(http.StatusOK,
+			`{"connector.class":"org.example.BSink","topics":"y","topics.regex":"z"}`))
+
+		linted := check(doctor.Examine(), CheckLint)
+		Expect(linted.Findings).To(Equal([]Finding{
+			{Subject: "b", Message: "error: topics and topics.regex are both set, Connect rejects this [topics-conflict]"},
+		}))
+	})
+
+	It("reports checks that can't run as errors", func() {
+		server.RouteToHandler("GET", "/connectors", ghttp.RespondWith(http.StatusInternalServerError, `{"error_code":500,"message":"oops"}`))
+
+		report := doctor.Examine()
+		Expect(check(report, CheckWorkers).Passed()).To(BeTrue())
+		Expect(check(report, CheckRunning).Error).To(ContainSubstring("could not get connector statuses"))
+		Expect(report.Failed()).To(HaveLen(4))
+	})
+})
@@ -1,10 +1,16 @@
 package connect
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 )
 
+// ErrConcurrentModification is returned by conditional updates when the
+// connector's live configuration no longer matches the expected fingerprint,
+// i.e. someone else has changed it since it was last read.
+var ErrConcurrentModification = errors.New("connector config was modified concurrently")
+
 // APIError holds information returned from a Kafka Connect API instance about
 // why an API call failed.
 type APIError struct {
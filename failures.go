@@ -0,0 +1,73 @@
+package connect
+
+import (
+	"sort"
+
+	"github.com/go-kafka/connect/trace"
+)
+
+// A FailureGroup is a set of failed connectors and tasks whose traces share a
+// root cause, once details such as hosts and IDs are masked.
+type FailureGroup struct {
+	// The normalized root cause, see trace.Signature.
+	Cause string `json:"cause"`
+
+	// The root cause of one of the failures as it was, unmasked.
+	Example string `json:"example"`
+
+	Connectors []string `json:"connectors,omitempty"`
+	Tasks      []TaskID `json:"tasks,omitempty"`
+}
+
+// Count is the number of failed connectors and tasks in the group.
+func (g *FailureGroup) Count() int {
+	return len(g.Connectors) + len(g.Tasks)
+}
+
+// GroupFailures groups the failed connectors and tasks in statuses by root
+// cause, largest groups first.
+func GroupFailures(statuses []ConnectorStatus) []FailureGroup {
+	byCause := make(map[string]*FailureGroup)
+	group := func(stackTrace string) *FailureGroup {
+		cause := trace.Signature(stackTrace)
+		if byCause[cause] == nil {
+			byCause[cause] = &FailureGroup{Cause: cause, Example: trace.Summarize(stackTrace)}
+		}
+		return byCause[cause]
+	}
+
+	for _, status := range statuses {
+		if status.Connector.State == "FAILED" {
+			g := group(status.Connector.Trace)
+			g.Connectors = append(g.Connectors, status.Name)
+		}
+		for _, task := range status.Tasks {
+			if task.State == "FAILED" {
+				g := group(task.Trace)
+				g.Tasks = append(g.Tasks, TaskID{ConnectorName: status.Name, ID: task.ID})
+			}
+		}
+	}
+
+	groups := make([]FailureGroup, 0, len(byCause))
+	for _, g := range byCause {
+		groups = append(groups, *g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Count() != groups[j].Count() {
+			return groups[i].Count() > groups[j].Count()
+		}
+		return groups[i].Cause < groups[j].Cause
+	})
+	return groups
+}
+
+// GetFailureGroups fetches the status of all connectors and groups their
+// failures, as GroupFailures does.
+func (c *Client) GetFailureGroups() ([]FailureGroup, error) {
+	statuses, err := c.ListConnectorStatuses()
+	if err != nil {
+		return nil, err
+	}
+	return GroupFailures(statuses), nil
+}
@@ -0,0 +1,59 @@
+package connect_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/go-kafka/connect"
+)
+
+var _ = Describe("GroupFailures", func() {
+	refused := func(host string) string {
+		return "org.apache.kafka.connect.errors.ConnectException: Exiting WorkerSinkTask\n" +
+			"\tat org.apache.kafka.connect.runtime.WorkerSinkTask.deliverMessages(WorkerSinkTask.java:614)\n" +
+			"Caused by: java.sql.SQLException: Connection to " + host + " refused\n" +
+			"\t... 12 more"
+	}
+
+	It("groups failed connectors and tasks by normalized root cause", func() {
+		statuses := []ConnectorStatus{
+			{
+				Name:      "orders-sink",
+				Connector: ConnectorState{State: "RUNNING", WorkerID: "w1:8083"},
+				Tasks: []TaskState{
+					{ID: 0, State: "FAILED", WorkerID: "w1:8083", Trace: refused("db-1:5432")},
+					{ID: 1, State: "RUNNING", WorkerID: "w2:8083"},
+					{ID: 2, State: "FAILED", WorkerID: "w2:8083", Trace: refused("db-2:5432")},
+				},
+			},
+			{
+				Name:      "payments-sink",
+				Connector: ConnectorState{State: "FAILED", WorkerID: "w2:8083", Trace: "java.lang.IllegalArgumentException: bad config"},
+				Tasks:     []TaskState{{ID: 0, State: "FAILED", WorkerID: "w1:8083", Trace: refused("db-1:5432")}},
+			},
+		}
+
+		Expect(GroupFailures(statuses)).To(Equal([]FailureGroup{
+			{
+				Cause:   "java.sql.SQLException: Connection to <host> refused",
+				Example: "java.sql.SQLException: Connection to db-1:5432 refused",
+				Tasks: []TaskID{
+					{ConnectorName: "orders-sink", ID: 0},
+					{ConnectorName: "orders-sink", ID: 2},
+					{ConnectorName: "payments-sink", ID: 0},
+				},
+			},
+			{
+				Cause:      "java.lang.IllegalArgumentException: bad config",
+				Example:    "java.lang.IllegalArgumentException: bad config",
+				Connectors: []string{"payments-sink"},
+			},
+		}))
+	})
+
+	It("is empty without failures", func() {
+		Expect(GroupFailures([]ConnectorStatus{
+			{Name: "a", Connector: ConnectorState{State: "RUNNING"}},
+		})).To(BeEmpty())
+	})
+})
@@ -0,0 +1,130 @@
+package gateway
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// A Role is what a user may do on a cluster.
+type Role string
+
+// Roles, each allowing what those before it do.
+const (
+	// Reading connectors, configs, statuses and so on.
+	RoleRead Role = "read"
+
+	// Also creating, changing and deleting connectors and acting on them.
+	RoleWrite Role = "write"
+)
+
+// AllClusters as a cluster ID in a user's roles gives a role on clusters not
+// otherwise listed.
+const AllClusters = "*"
+
+// A User is someone or something holding an API key, such as a portal.
+type User struct {
+	Name string `yaml:"name"`
+	Key  string `yaml:"key"`
+
+	// Roles by cluster ID, or AllClusters.
+	Roles map[string]Role `yaml:"roles"`
+}
+
+// Can reports whether the user may read, or if write is set change, a cluster.
+func (u *User) Can(cluster string, write bool) bool {
+	role, ok := u.Roles[cluster]
+	if !ok {
+		role = u.Roles[AllClusters]
+	}
+	if write {
+		return role == RoleWrite
+	}
+	return role == RoleRead || role == RoleWrite
+}
+
+// Auth holds the users allowed to use a gateway.
+type Auth struct {
+	Users []User `yaml:"users"`
+}
+
+// LoadAuth reads users from a YAML file:
+//
+//	users:
+//	- name: portal
+//	  key: ${PORTAL_API_KEY}
+//	  roles:
+//	    production: read
+//	    "*": write
+//
+// Keys may reference environment variables as $VAR or ${VAR}, so that they
+// needn't be kept in the file.
+func LoadAuth(path string) (*Auth, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	auth := new(Auth)
+	if err := yaml.UnmarshalStrict(data, auth); err != nil {
+		return nil, fmt.Errorf("invalid auth file %v: %v", path, err)
+	}
+	for i := range auth.Users {
+		auth.Users[i].Key = os.ExpandEnv(auth.Users[i].Key)
+	}
+	if err := auth.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid auth file %v: %v", path, err)
+	}
+	return auth, nil
+}
+
+// Validate checks that every user has a name, a key of their own and only
+// known roles.
+func (a *Auth) Validate() error {
+	names, keys := make(map[string]bool), make(map[string]bool)
+	for _, user := range a.Users {
+		switch {
+		case user.Name == "":
+			return fmt.Errorf("a user has no name")
+		case names[user.Name]:
+			return fmt.Errorf("user %v is defined twice", user.Name)
+		case user.Key == "":
+			return fmt.Errorf("user %v has no key", user.Name)
+		case keys[user.Key]:
+			return fmt.Errorf("user %v has the same key as another", user.Name)
+		}
+		names[user.Name], keys[user.Key] = true, true
+
+		for cluster, role := range user.Roles {
+			if role != RoleRead && role != RoleWrite {
+				return fmt.Errorf("user %v has unknown role %q for %v, try read or write", user.Name, role, cluster)
+			}
+		}
+	}
+	return nil
+}
+
+// Authenticate returns the user whose key a request carries, as an X-API-Key
+// header or a bearer token, or nil.
+func (a *Auth) Authenticate(r *http.Request) *User {
+	key := r.Header.Get("X-API-Key")
+	if auth := r.Header.Get("Authorization"); key == "" && strings.HasPrefix(auth, "Bearer ") {
+		key = strings.TrimPrefix(auth, "Bearer ")
+	}
+	if key == "" {
+		return nil
+	}
+
+	// Compare with every key in constant time, so as not to hint at any
+	var found *User
+	for i := range a.Users {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(a.Users[i].Key)) == 1 {
+			found = &a.Users[i]
+		}
+	}
+	return found
+}
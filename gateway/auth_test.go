@@ -0,0 +1,91 @@
+package gateway_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/go-kafka/connect/gateway"
+)
+
+var _ = Describe("User", func() {
+	user := &User{Name: "portal", Roles: map[string]Role{"prod": RoleRead, AllClusters: RoleWrite}}
+
+	It("has the role given for a cluster", func() {
+		Expect(user.Can("prod", false)).To(BeTrue())
+		Expect(user.Can("prod", true)).To(BeFalse())
+	})
+
+	It("falls back on the role for all clusters", func() {
+		Expect(user.Can("dev", true)).To(BeTrue())
+		Expect((&User{Roles: map[string]Role{"dev": RoleRead}}).Can("prod", false)).To(BeFalse())
+	})
+})
+
+var _ = Describe("LoadAuth", func() {
+	var tmpdir, path string
+
+	write := func(text string) {
+		Expect(ioutil.WriteFile(path, []byte(text), 0600)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		tmpdir, _ = ioutil.TempDir("", "gateway-auth")
+		path = filepath.Join(tmpdir, "auth.yaml")
+		os.Setenv("GATEWAY_TEST_KEY", "s3cret")
+	})
+
+	AfterEach(func() {
+		_ = os.RemoveAll(tmpdir)
+		os.Unsetenv("GATEWAY_TEST_KEY")
+	})
+
+	It("reads users, expanding keys from the environment", func() {
+		write("users:\n- name: portal\n  key: ${GATEWAY_TEST_KEY}\n  roles:\n    prod: read\n")
+		auth, err := LoadAuth(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(auth.Users).To(Equal([]User{{Name: "portal", Key: "s3cret", Roles: map[string]Role{"prod": RoleRead}}}))
+	})
+
+	It("rejects unknown roles", func() {
+		write("users:\n- name: portal\n  key: k\n  roles:\n    prod: admin\n")
+		_, err := LoadAuth(path)
+		Expect(err).To(MatchError(ContainSubstring(`user portal has unknown role "admin" for prod`)))
+	})
+
+	It("rejects users without a key", func() {
+		write("users:\n- name: portal\n  key: ${GATEWAY_UNSET_KEY}\n")
+		_, err := LoadAuth(path)
+		Expect(err).To(MatchError(ContainSubstring("user portal has no key")))
+	})
+
+	It("rejects shared keys", func() {
+		write("users:\n- name: a\n  key: k\n- name: b\n  key: k\n")
+		_, err := LoadAuth(path)
+		Expect(err).To(MatchError(ContainSubstring("user b has the same key as another")))
+	})
+})
+
+var _ = Describe("Authenticate", func() {
+	auth := &Auth{Users: []User{{Name: "a", Key: "key-a"}, {Name: "b", Key: "key-b"}}}
+
+	request := func(header, value string) *http.Request {
+		r, _ := http.NewRequest("GET", "/clusters", nil)
+		r.Header.Set(header, value)
+		return r
+	}
+
+	It("accepts an X-API-Key header or bearer token", func() {
+		Expect(auth.Authenticate(request("X-API-Key", "key-b")).Name).To(Equal("b"))
+		Expect(auth.Authenticate(request("Authorization", "Bearer key-a")).Name).To(Equal("a"))
+	})
+
+	It("rejects unknown or missing keys", func() {
+		Expect(auth.Authenticate(request("X-API-Key", "key-c"))).To(BeNil())
+		Expect(auth.Authenticate(request("Authorization", "Basic a2V5LWE="))).To(BeNil())
+	})
+})
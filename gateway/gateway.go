@@ -0,0 +1,479 @@
+// Package gateway serves one REST API in front of several Connect clusters, so
+// that tools such as an internal portal needn't reach each cluster directly.
+// Under /clusters/{id}/ it serves Connect's own endpoints and shapes, and at
+// the top level aggregates across clusters. Users authenticate with API keys
+// and have a read or write role per cluster, and every call that changes
+// anything is audited.
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kafka/connect"
+)
+
+// Largest request body accepted, far more than any connector config needs.
+const maxBodySize = 1 << 20
+
+// An AuditRecord describes a call that changes something, whether it was
+// allowed or not.
+type AuditRecord struct {
+	Time    time.Time `json:"time"`
+	User    string    `json:"user,omitempty"`
+	Remote  string    `json:"remote"`
+	Cluster string    `json:"cluster,omitempty"`
+	Method  string    `json:"method"`
+	Path    string    `json:"path"`
+	Query   string    `json:"query,omitempty"`
+	Status  int       `json:"status"`
+}
+
+// A Gateway serves the API for a set of clusters.
+type Gateway struct {
+	// Clients for the clusters by ID, as used in paths.
+	Clusters map[string]*connect.Client
+
+	Auth *Auth
+
+	// Called after every call that changes something, if set.
+	Audit func(AuditRecord)
+}
+
+// A Cluster describes a cluster a user may read.
+type Cluster struct {
+	ID   string `json:"id"`
+	Host string `json:"host"`
+}
+
+// A ClusterStatus is a connector's status along with its cluster.
+type ClusterStatus struct {
+	Cluster string `json:"cluster"`
+	connect.ConnectorStatus
+}
+
+// Statuses are the connectors across clusters, and errors by cluster ID for
+// those that could not be reached.
+type Statuses struct {
+	Connectors []ClusterStatus   `json:"connectors"`
+	Errors     map[string]string `json:"errors,omitempty"`
+}
+
+// A Summary counts the connectors and tasks of a cluster in each state.
+type Summary struct {
+	Cluster    string         `json:"cluster"`
+	Up         bool           `json:"up"`
+	Error      string         `json:"error,omitempty"`
+	Connectors map[string]int `json:"connectors"`
+	Tasks      map[string]int `json:"tasks"`
+}
+
+type userKey struct{}
+
+type paramsKey struct{}
+
+// A route serves a method and path, with path parameters written in braces.
+type route struct {
+	method   string
+	segments []string
+	handler  http.HandlerFunc
+}
+
+// Handler returns the gateway's HTTP handler.
+func (g *Gateway) Handler() http.Handler {
+	var routes []route
+	handle := func(pattern string, handler http.HandlerFunc) {
+		fields := strings.Fields(pattern)
+		routes = append(routes, route{
+			method:   fields[0],
+			segments: strings.Split(strings.TrimPrefix(fields[1], "/"), "/"),
+			handler:  handler,
+		})
+	}
+	handle("GET /clusters", g.listClusters)
+	handle("GET /connectors", g.listStatuses)
+	handle("GET /summary", g.summarize)
+
+	// Connect's own API, per cluster
+	cluster := func(pattern string, write bool, handler func(http.ResponseWriter, *http.Request, *connect.Client)) {
+		handle(pattern, func(w http.ResponseWriter, r *http.Request) {
+			if client := g.authorize(w, r, write); client != nil {
+				handler(w, r, client)
+			}
+		})
+	}
+	cluster("GET /clusters/{cluster}", false, func(w http.ResponseWriter, r *http.Request, c *connect.Client) {
+		respond(w)(c.GetServerInfo())
+	})
+	cluster("GET /clusters/{cluster}/connectors", false, func(w http.ResponseWriter, r *http.Request, c *connect.Client) {
+		respond(w)(c.ListConnectors())
+	})
+	cluster("POST /clusters/{cluster}/connectors", true, func(w http.ResponseWriter, r *http.Request, c *connect.Client) {
+		conn := new(connect.Connector)
+		if decodeBody(w, r, conn) {
+			response, err := c.CreateConnector(conn)
+			respond(w)(conn, response, err)
+		}
+	})
+	cluster("GET /clusters/{cluster}/connectors/{name}", false, connectorRoute(func(w http.ResponseWriter, r *http.Request, c *connect.Client, name string) {
+		respond(w)(c.GetConnector(name))
+	}))
+	cluster("DELETE /clusters/{cluster}/connectors/{name}", true, connectorRoute(func(w http.ResponseWriter, r *http.Request, c *connect.Client, name string) {
+		respondEmpty(w)(c.DeleteConnector(name))
+	}))
+	cluster("GET /clusters/{cluster}/connectors/{name}/config", false, connectorRoute(func(w http.ResponseWriter, r *http.Request, c *connect.Client, name string) {
+		respond(w)(c.GetConnectorConfig(name))
+	}))
+	cluster("PUT /clusters/{cluster}/connectors/{name}/config", true, connectorRoute(func(w http.ResponseWriter, r *http.Request, c *connect.Client, name string) {
+		var config connect.ConnectorConfig
+		if decodeBody(w, r, &config) {
+			respond(w)(c.UpdateConnectorConfig(name, config))
+		}
+	}))
+	cluster("GET /clusters/{cluster}/connectors/{name}/status", false, connectorRoute(func(w http.ResponseWriter, r *http.Request, c *connect.Client, name string) {
+		respond(w)(c.GetConnectorStatus(name))
+	}))
+	cluster("GET /clusters/{cluster}/connectors/{name}/tasks", false, connectorRoute(func(w http.ResponseWriter, r *http.Request, c *connect.Client, name string) {
+		respond(w)(c.GetConnectorTasks(name))
+	}))
+	cluster("GET /clusters/{cluster}/connectors/{name}/offsets", false, connectorRoute(func(w http.ResponseWriter, r *http.Request, c *connect.Client, name string) {
+		respond(w)(c.GetConnectorOffsets(name))
+	}))
+	cluster("PATCH /clusters/{cluster}/connectors/{name}/offsets", true, connectorRoute(func(w http.ResponseWriter, r *http.Request, c *connect.Client, name string) {
+		offsets := new(connect.ConnectorOffsets)
+		if decodeBody(w, r, offsets) {
+			respondEmpty(w)(c.AlterConnectorOffsets(name, offsets))
+		}
+	}))
+	cluster("POST /clusters/{cluster}/connectors/{name}/restart", true, connectorRoute(func(w http.ResponseWriter, r *http.Request, c *connect.Client, name string) {
+		query := r.URL.Query()
+		if query.Get("includeTasks") == "true" {
+			respondEmpty(w)(c.RestartConnectorAndTasks(name, query.Get("onlyFailed") == "true"))
+			return
+		}
+		respondEmpty(w)(c.RestartConnector(name))
+	}))
+	cluster("PUT /clusters/{cluster}/connectors/{name}/pause", true, connectorRoute(func(w http.ResponseWriter, r *http.Request, c *connect.Client, name string) {
+		respondEmpty(w)(c.PauseConnector(name))
+	}))
+	cluster("PUT /clusters/{cluster}/connectors/{name}/resume", true, connectorRoute(func(w http.ResponseWriter, r *http.Request, c *connect.Client, name string) {
+		respondEmpty(w)(c.ResumeConnector(name))
+	}))
+	cluster("PUT /clusters/{cluster}/connectors/{name}/stop", true, connectorRoute(func(w http.ResponseWriter, r *http.Request, c *connect.Client, name string) {
+		respondEmpty(w)(c.StopConnector(name))
+	}))
+	cluster("POST /clusters/{cluster}/connectors/{name}/tasks/{task}/restart", true, connectorRoute(func(w http.ResponseWriter, r *http.Request, c *connect.Client, name string) {
+		id, err := strconv.Atoi(pathValue(r, "task"))
+		if err != nil {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("no task %q", pathValue(r, "task")))
+			return
+		}
+		respondEmpty(w)(c.RestartTask(name, id))
+	}))
+	cluster("PUT /clusters/{cluster}/connector-plugins/{class}/config/validate", false, func(w http.ResponseWriter, r *http.Request, c *connect.Client) {
+		var config connect.ConnectorConfig
+		if decodeBody(w, r, &config) {
+			respond(w)(c.ValidateConnectorConfig(pathValue(r, "class"), config))
+		}
+	})
+
+	return g.authenticate(dispatch(routes))
+}
+
+// Serves a request with the route matching its method and path. Paths are
+// matched as they are, not cleaned, and split before unescaping so that an
+// escaped slash stays within its segment.
+func dispatch(routes []route) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		segments := strings.Split(strings.TrimPrefix(r.URL.EscapedPath(), "/"), "/")
+		var allowed []string
+		for _, rt := range routes {
+			params, ok := rt.match(segments)
+			if !ok {
+				continue
+			}
+			// HEAD is served along with GET
+			if r.Method != rt.method && !(rt.method == "GET" && r.Method == "HEAD") {
+				allowed = append(allowed, rt.method)
+				continue
+			}
+			rt.handler(w, r.WithContext(context.WithValue(r.Context(), paramsKey{}, params)))
+			return
+		}
+
+		if len(allowed) == 0 {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+// Returns the unescaped values of the route's parameters if the path segments
+// match it.
+func (rt *route) match(segments []string) (map[string]string, bool) {
+	if len(segments) != len(rt.segments) {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for i, s := range rt.segments {
+		if !strings.HasPrefix(s, "{") {
+			if segments[i] != s {
+				return nil, false
+			}
+			continue
+		}
+		value, err := url.PathUnescape(segments[i])
+		if err != nil || value == "" {
+			return nil, false
+		}
+		params[strings.Trim(s, "{}")] = value
+	}
+	return params, true
+}
+
+func pathValue(r *http.Request, name string) string {
+	params, _ := r.Context().Value(paramsKey{}).(map[string]string)
+	return params[name]
+}
+
+// Requires a known API key for everything, and audits calls that may change
+// something.
+func (g *Gateway) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := g.Auth.Authenticate(r)
+
+		if g.Audit != nil && r.Method != "GET" && r.Method != "HEAD" {
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			w = rec
+			defer func() {
+				record := AuditRecord{
+					Time:    time.Now(),
+					Remote:  r.RemoteAddr,
+					Cluster: clusterInPath(r.URL.Path),
+					Method:  r.Method,
+					Path:    r.URL.Path,
+					Query:   r.URL.RawQuery,
+					Status:  rec.status,
+				}
+				if user != nil {
+					record.User = user.Name
+				}
+				g.Audit(record)
+			}()
+		}
+
+		if user == nil {
+			writeError(w, http.StatusUnauthorized, "an API key is required, as X-API-Key or a bearer token")
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userKey{}, user)))
+	})
+}
+
+// Returns the client for the cluster in the path if the user may use it as
+// asked, or writes an error response and returns nil.
+func (g *Gateway) authorize(w http.ResponseWriter, r *http.Request, write bool) *connect.Client {
+	id := pathValue(r, "cluster")
+	user := r.Context().Value(userKey{}).(*User)
+	client, ok := g.Clusters[id]
+
+	// Clusters the user can't read might as well not exist
+	if !ok || !user.Can(id, false) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no cluster %q", id))
+		return nil
+	}
+	if write && !user.Can(id, true) {
+		writeError(w, http.StatusForbidden, fmt.Sprintf("%v may not make changes on %v", user.Name, id))
+		return nil
+	}
+	return client
+}
+
+// Passes the connector name from the path to a handler, refusing names that
+// would change the meaning of the upstream path.
+func connectorRoute(handler func(http.ResponseWriter, *http.Request, *connect.Client, string)) func(http.ResponseWriter, *http.Request, *connect.Client) {
+	return func(w http.ResponseWriter, r *http.Request, c *connect.Client) {
+		name := pathValue(r, "name")
+		if strings.ContainsAny(name, "/?#") || name == "." || name == ".." {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid connector name %q", name))
+			return
+		}
+		handler(w, r, c, name)
+	}
+}
+
+func (g *Gateway) readable(r *http.Request) []string {
+	user := r.Context().Value(userKey{}).(*User)
+	var ids []string
+	for id := range g.Clusters {
+		if user.Can(id, false) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (g *Gateway) listClusters(w http.ResponseWriter, r *http.Request) {
+	clusters := []Cluster{}
+	for _, id := range g.readable(r) {
+		clusters = append(clusters, Cluster{ID: id, Host: g.Clusters[id].Host()})
+	}
+	writeJSON(w, http.StatusOK, clusters)
+}
+
+// Fetches statuses from each cluster at once, in the order of ids.
+func (g *Gateway) fetchStatuses(ids []string) ([][]connect.ConnectorStatus, []error) {
+	statuses, errs := make([][]connect.ConnectorStatus, len(ids)), make([]error, len(ids))
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, client *connect.Client) {
+			defer wg.Done()
+			statuses[i], errs[i] = client.ListConnectorStatuses()
+		}(i, g.Clusters[id])
+	}
+	wg.Wait()
+	return statuses, errs
+}
+
+// Lists connector statuses across clusters, optionally selected by glob
+// patterns of names and by states, as name and state query parameters.
+func (g *Gateway) listStatuses(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	sel := connect.Selector{Names: query["name"], States: query["state"]}
+	ids := g.readable(r)
+	statuses, errs := g.fetchStatuses(ids)
+
+	result := Statuses{Connectors: []ClusterStatus{}}
+	for i, id := range ids {
+		if errs[i] != nil {
+			if result.Errors == nil {
+				result.Errors = make(map[string]string)
+			}
+			result.Errors[id] = errs[i].Error()
+			continue
+		}
+		for _, status := range statuses[i] {
+			if sel.Matches(status.Name, nil, &status) {
+				result.Connectors = append(result.Connectors, ClusterStatus{Cluster: id, ConnectorStatus: status})
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (g *Gateway) summarize(w http.ResponseWriter, r *http.Request) {
+	ids := g.readable(r)
+	statuses, errs := g.fetchStatuses(ids)
+
+	summaries := make([]Summary, len(ids))
+	for i, id := range ids {
+		s := Summary{Cluster: id, Up: errs[i] == nil, Connectors: map[string]int{}, Tasks: map[string]int{}}
+		if errs[i] != nil {
+			s.Error = errs[i].Error()
+		}
+		for _, status := range statuses[i] {
+			s.Connectors[status.Connector.State]++
+			for _, task := range status.Tasks {
+				s.Tasks[task.State]++
+			}
+		}
+		summaries[i] = s
+	}
+	writeJSON(w, http.StatusOK, summaries)
+}
+
+// The cluster ID in a path under /clusters/, if any.
+func clusterInPath(path string) string {
+	parts := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 3)
+	if len(parts) >= 2 && parts[0] == "clusters" {
+		return parts[1]
+	}
+	return ""
+}
+
+// Decodes a JSON request body into v, or writes an error response and returns
+// false.
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return false
+	}
+	return true
+}
+
+// Writes the result of a Client call with the status the cluster responded
+// with, or an error as Connect would.
+func respond(w http.ResponseWriter) func(interface{}, *http.Response, error) {
+	return func(v interface{}, response *http.Response, err error) {
+		if err != nil {
+			respondError(w, err)
+			return
+		}
+		status := http.StatusOK
+		if response != nil {
+			status = response.StatusCode
+		}
+		if status == http.StatusNoContent {
+			w.WriteHeader(status)
+			return
+		}
+		writeJSON(w, status, v)
+	}
+}
+
+// Like respond, for calls with no result.
+func respondEmpty(w http.ResponseWriter) func(*http.Response, error) {
+	return func(response *http.Response, err error) {
+		if err != nil {
+			respondError(w, err)
+			return
+		}
+		w.WriteHeader(response.StatusCode)
+	}
+}
+
+// Passes on errors from a cluster as they are, and reports failing to reach it
+// as a bad gateway.
+func respondError(w http.ResponseWriter, err error) {
+	if apiErr, ok := err.(connect.APIError); ok {
+		writeError(w, apiErr.Code, apiErr.Message)
+		return
+	}
+	writeError(w, http.StatusBadGateway, err.Error())
+}
+
+// Writes an error in the shape of Connect's own.
+func writeError(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, code, struct {
+		Code    int    `json:"error_code"`
+		Message string `json:"message"`
+	}{code, message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
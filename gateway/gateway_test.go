@@ -0,0 +1,185 @@
+package gateway_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	"github.com/go-kafka/connect"
+	. "github.com/go-kafka/connect/gateway"
+)
+
+var _ = Describe("Gateway", func() {
+	var prod, dev *ghttp.Server
+	var handler http.Handler
+	var audit []AuditRecord
+
+	call := func(method, path, key, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		if key != "" {
+			r.Header.Set("X-API-Key", key)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	BeforeEach(func() {
+		prod, dev = ghttp.NewServer(), ghttp.NewServer()
+		audit = nil
+		gw := &Gateway{
+			Clusters: map[string]*connect.Client{
+				"prod": connect.NewClient(prod.URL()),
+				"dev":  connect.NewClient(dev.URL()),
+			},
+			Auth: &Auth{Users: []User{
+				{Name: "portal", Key: "portal-key", Roles: map[string]Role{"prod": RoleRead, "dev": RoleWrite}},
+				{Name: "dev-team", Key: "dev-key", Roles: map[string]Role{"dev": RoleRead}},
+			}},
+			Audit: func(r AuditRecord) { audit = append(audit, r) },
+		}
+		handler = gw.Handler()
+	})
+
+	AfterEach(func() {
+		prod.Close()
+		dev.Close()
+	})
+
+	It("requires an API key", func() {
+		w := call("GET", "/clusters", "", "")
+		Expect(w.Code).To(Equal(http.StatusUnauthorized))
+		Expect(w.Body.String()).To(MatchJSON(`{"error_code":401,"message":"an API key is required, as X-API-Key or a bearer token"}`))
+	})
+
+	It("lists the clusters a user may read", func() {
+		w := call("GET", "/clusters", "dev-key", "")
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.String()).To(MatchJSON(`[{"id":"dev","host":"` + dev.URL() + `"}]`))
+	})
+
+	It("proxies Connect's API with its status codes", func() {
+		prod.AppendHandlers(ghttp.CombineHandlers(
+			ghttp.VerifyRequest("GET", "/connectors/orders/status"),
+			ghttp.RespondWith(http.StatusOK, `{"name":"orders","connector":{"state":"RUNNING","worker_id":"w1"},"tasks":[]}`),
+		))
+		w := call("GET", "/clusters/prod/connectors/orders/status", "portal-key", "")
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.String()).To(MatchJSON(`{"name":"orders","connector":{"state":"RUNNING","worker_id":"w1"},"tasks":[]}`))
+
+		dev.AppendHandlers(ghttp.CombineHandlers(
+			ghttp.VerifyRequest("POST", "/connectors"),
+			ghttp.VerifyJSON(`{"name":"orders","config":{"tasks.max":"1"}}`),
+			ghttp.RespondWith(http.StatusCreated, `{"name":"orders","config":{"tasks.max":"1"},"tasks":[{"connector":"orders","task":0}]}`),
+		))
+		w = call("POST", "/clusters/dev/connectors", "portal-key", `{"name":"orders","config":{"tasks.max":"1"}}`)
+		Expect(w.Code).To(Equal(http.StatusCreated))
+		Expect(w.Body.String()).To(MatchJSON(`{"name":"orders","config":{"tasks.max":"1"},"tasks":[{"connector":"orders","task":0}]}`))
+
+		dev.AppendHandlers(ghttp.CombineHandlers(
+			ghttp.VerifyRequest("PUT", "/connectors/orders/pause"),
+			ghttp.RespondWith(http.StatusAccepted, nil),
+		))
+		Expect(call("PUT", "/clusters/dev/connectors/orders/pause", "portal-key", "").Code).To(Equal(http.StatusAccepted))
+	})
+
+	It("passes on errors from the cluster", func() {
+		prod.AppendHandlers(ghttp.RespondWith(http.StatusNotFound, `{"error_code":404,"message":"Connector nope not found"}`))
+		w := call("GET", "/clusters/prod/connectors/nope", "portal-key", "")
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+		Expect(w.Body.String()).To(MatchJSON(`{"error_code":404,"message":"Connector nope not found"}`))
+	})
+
+	It("reports unreachable clusters as a bad gateway", func() {
+		prod.Close()
+		Expect(call("GET", "/clusters/prod/connectors", "portal-key", "").Code).To(Equal(http.StatusBadGateway))
+	})
+
+	It("enforces roles per cluster", func() {
+		w := call("DELETE", "/clusters/prod/connectors/orders", "portal-key", "")
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+		Expect(w.Body.String()).To(ContainSubstring("portal may not make changes on prod"))
+
+		Expect(call("GET", "/clusters/prod/connectors", "dev-key", "").Code).To(Equal(http.StatusNotFound))
+		Expect(call("GET", "/clusters/staging/connectors", "portal-key", "").Code).To(Equal(http.StatusNotFound))
+		Expect(prod.ReceivedRequests()).To(BeEmpty())
+	})
+
+	It("refuses connector names that would change the upstream path", func() {
+		w := call("DELETE", "/clusters/dev/connectors/..%2Fconnector-plugins", "portal-key", "")
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		Expect(dev.ReceivedRequests()).To(BeEmpty())
+	})
+
+	It("tells which methods a path allows", func() {
+		w := call("POST", "/clusters/dev/connectors/orders/status", "portal-key", "")
+		Expect(w.Code).To(Equal(http.StatusMethodNotAllowed))
+		Expect(w.Header().Get("Allow")).To(Equal("GET"))
+
+		Expect(call("GET", "/clusters/dev/connectors/orders/nope", "portal-key", "").Code).To(Equal(http.StatusNotFound))
+		Expect(dev.ReceivedRequests()).To(BeEmpty())
+	})
+
+	It("audits calls that change something, allowed or not", func() {
+		dev.AppendHandlers(ghttp.RespondWith(http.StatusNoContent, nil), ghttp.RespondWith(http.StatusOK, `[]`))
+		call("POST", "/clusters/dev/connectors/orders/restart?includeTasks=true", "portal-key", "")
+		call("DELETE", "/clusters/prod/connectors/orders", "portal-key", "")
+		call("DELETE", "/clusters/prod/connectors/orders", "wrong-key", "")
+		call("GET", "/clusters/dev/connectors", "portal-key", "")
+
+		Expect(audit).To(HaveLen(3))
+		Expect(audit[0].Time).NotTo(BeZero())
+		Expect(audit[0].Query).To(Equal("includeTasks=true"))
+		var calls []string
+		for _, r := range audit {
+			calls = append(calls, fmt.Sprintf("%v %v %v %v %d", r.User, r.Cluster, r.Method, r.Path, r.Status))
+		}
+		Expect(calls).To(Equal([]string{
+			"portal dev POST /clusters/dev/connectors/orders/restart 204",
+			"portal prod DELETE /clusters/prod/connectors/orders 403",
+			" prod DELETE /clusters/prod/connectors/orders 401",
+		}))
+	})
+
+	Describe("aggregates", func() {
+		BeforeEach(func() {
+			prod.RouteToHandler("GET", "/connectors", ghttp.RespondWith(http.StatusOK, `["orders"]`))
+			prod.RouteToHandler("GET", "/connectors/orders/status", ghttp.RespondWith(http.StatusOK,
+				`{"name":"orders","connector":{"state":"RUNNING","worker_id":"w1"},"tasks":[{"id":0,"state":"FAILED","worker_id":"w1"}]}`))
+			dev.RouteToHandler("GET", "/connectors", ghttp.RespondWith(http.StatusOK, `["scratch"]`))
+			dev.RouteToHandler("GET", "/connectors/scratch/status", ghttp.RespondWith(http.StatusOK,
+				`{"name":"scratch","connector":{"state":"PAUSED","worker_id":"w9"},"tasks":[]}`))
+		})
+
+		It("lists connectors across clusters", func() {
+			w := call("GET", "/connectors?state=FAILED", "portal-key", "")
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(MatchJSON(`{"connectors":[{"cluster":"prod","name":"orders",
+				"connector":{"state":"RUNNING","worker_id":"w1"},"tasks":[{"id":0,"state":"FAILED","worker_id":"w1"}]}]}`))
+		})
+
+		It("reports clusters that can't be reached", func() {
+			dev.Close()
+			w := call("GET", "/connectors?name=scratch", "portal-key", "")
+			Expect(w.Body.String()).To(ContainSubstring(`"connectors":[]`))
+			Expect(w.Body.String()).To(ContainSubstring(`"errors":{"dev":`))
+		})
+
+		It("summarizes states by cluster", func() {
+			w := call("GET", "/summary", "portal-key", "")
+			Expect(w.Body.String()).To(MatchJSON(`[
+				{"cluster":"dev","up":true,"connectors":{"PAUSED":1},"tasks":{}},
+				{"cluster":"prod","up":true,"connectors":{"RUNNING":1},"tasks":{"FAILED":1}}]`))
+		})
+
+		It("only includes clusters the user may read", func() {
+			w := call("GET", "/summary", "dev-key", "")
+			Expect(w.Body.String()).To(MatchJSON(`[{"cluster":"dev","up":true,"connectors":{"PAUSED":1},"tasks":{}}]`))
+		})
+	})
+})
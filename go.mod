@@ -17,4 +17,5 @@ require (
 	github.com/stretchr/testify v1.2.2 // indirect
 	golang.org/x/lint v0.0.0-20190409202823-959b441ac422
 	gopkg.in/alecthomas/kingpin.v2 v2.2.2
+	gopkg.in/yaml.v2 v2.2.1
 )
@@ -0,0 +1,282 @@
+// Package heal restarts failed tasks automatically, for the transient failures
+// that a restart clears up. Each task is restarted with exponential backoff,
+// and after too many attempts the healer gives up on it and escalates, so that
+// a persistent failure gets a person's attention rather than endless restarts.
+package heal
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"time"
+
+	"github.com/go-kafka/connect"
+	"github.com/go-kafka/connect/trace"
+)
+
+// Defaults for a Healer's settings left zero.
+const (
+	DefaultInterval    = 30 * time.Second
+	DefaultBackoff     = 30 * time.Second
+	DefaultMaxBackoff  = 10 * time.Minute
+	DefaultMaxAttempts = 5
+	DefaultResetAfter  = 10 * time.Minute
+)
+
+// Actions a Healer takes or would take, as recorded in Events.
+const (
+	// A failed task was restarted, or in a dry run would have been.
+	ActionRestart = "restart"
+
+	// Restarting a failed task was attempted but the request failed.
+	ActionRestartFailed = "restart-failed"
+
+	// A failed task is left alone because an exclusion matches it.
+	ActionExcluded = "excluded"
+
+	// The healer gave up on a task after MaxAttempts restarts.
+	ActionGiveUp = "give-up"
+
+	// A task the healer restarted or gave up on has been running for
+	// ResetAfter, so its attempts start over.
+	ActionRecovered = "recovered"
+
+	// Statuses could not be fetched.
+	ActionPollFailed = "poll-failed"
+)
+
+// An Event records something a Healer did.
+type Event struct {
+	Time   time.Time      `json:"time"`
+	Action string         `json:"action"`
+	Task   connect.TaskID `json:"task"`
+
+	// Restarts of the task so far, including this one.
+	Attempt int `json:"attempt,omitempty"`
+
+	// Root cause of the task's failure.
+	Cause string `json:"cause,omitempty"`
+
+	DryRun bool   `json:"dry_run,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+func (e Event) String() string {
+	var s string
+	switch e.Action {
+	case ActionPollFailed:
+		return fmt.Sprintf("could not get statuses: %v", e.Error)
+	case ActionRestart:
+		s = fmt.Sprintf("restarted task %v/%d, attempt %d", e.Task.ConnectorName, e.Task.ID, e.Attempt)
+		if e.DryRun {
+			s = "would have " + s
+		}
+	case ActionRestartFailed:
+		s = fmt.Sprintf("failed to restart task %v/%d: %v", e.Task.ConnectorName, e.Task.ID, e.Error)
+	case ActionExcluded:
+		s = fmt.Sprintf("not restarting excluded task %v/%d", e.Task.ConnectorName, e.Task.ID)
+	case ActionGiveUp:
+		s = fmt.Sprintf("gave up on task %v/%d after %d attempts", e.Task.ConnectorName, e.Task.ID, e.Attempt)
+	case ActionRecovered:
+		return fmt.Sprintf("task %v/%d recovered", e.Task.ConnectorName, e.Task.ID)
+	default:
+		s = fmt.Sprintf("%v task %v/%d", e.Action, e.Task.ConnectorName, e.Task.ID)
+	}
+	if e.Cause != "" {
+		s += ": " + e.Cause
+	}
+	return s
+}
+
+// A Healer watches connector statuses and restarts failed tasks.
+type Healer struct {
+	Client *connect.Client
+
+	// How often to check statuses.
+	Interval time.Duration
+
+	// How long to wait before the second restart of a task, doubling for each
+	// restart after that up to MaxBackoff. The first restart is immediate.
+	Backoff, MaxBackoff time.Duration
+
+	// Restarts of a task before giving up on it and escalating.
+	MaxAttempts int
+
+	// How long a task must keep running after a restart for its attempts to
+	// start over.
+	ResetAfter time.Duration
+
+	// Glob patterns of connector names, and regular expressions matched
+	// against the root cause of failures, for tasks to leave alone.
+	ExcludeNames  []string
+	ExcludeCauses []*regexp.Regexp
+
+	// Only log what would be done.
+	DryRun bool
+
+	// Called with every event. Escalate is also called when giving up on a
+	// task, unless in a dry run.
+	Log      func(Event)
+	Escalate func(Event)
+
+	tasks map[connect.TaskID]*taskState
+}
+
+// What the healer knows about a task it has acted on.
+type taskState struct {
+	attempts     int
+	nextAttempt  time.Time
+	gaveUp       bool
+	excluded     bool
+	runningSince time.Time
+}
+
+// Run checks statuses every Interval, acting on failed tasks, until stop is
+// closed.
+func (h *Healer) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(orDefault(h.Interval, DefaultInterval))
+	defer ticker.Stop()
+	for {
+		if err := h.Tick(time.Now()); err != nil {
+			h.log(Event{Time: time.Now(), Action: ActionPollFailed, Error: err.Error()})
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick checks statuses once, as of now, and acts on them.
+func (h *Healer) Tick(now time.Time) error {
+	statuses, err := h.Client.ListConnectorStatuses()
+	if err != nil {
+		return err
+	}
+	if h.tasks == nil {
+		h.tasks = make(map[connect.TaskID]*taskState)
+	}
+
+	seen := make(map[connect.TaskID]bool)
+	for _, status := range statuses {
+		for _, task := range status.Tasks {
+			id := connect.TaskID{ConnectorName: status.Name, ID: task.ID}
+			seen[id] = true
+			if task.State == "FAILED" {
+				h.failed(now, id, task.Trace)
+			} else if task.State == "RUNNING" {
+				h.running(now, id)
+			}
+		}
+	}
+
+	// Forget tasks that are gone, e.g. after scaling down or deletion
+	for id := range h.tasks {
+		if !seen[id] {
+			delete(h.tasks, id)
+		}
+	}
+	return nil
+}
+
+func (h *Healer) failed(now time.Time, id connect.TaskID, stackTrace string) {
+	st := h.tasks[id]
+	if st == nil {
+		st = &taskState{}
+		h.tasks[id] = st
+	}
+	st.runningSince = time.Time{}
+	if st.gaveUp || now.Before(st.nextAttempt) {
+		return
+	}
+
+	event := Event{Time: now, Task: id, Cause: trace.Summarize(stackTrace), DryRun: h.DryRun}
+	if h.isExcluded(id.ConnectorName, event.Cause) {
+		if !st.excluded {
+			st.excluded = true
+			event.Action = ActionExcluded
+			h.log(event)
+		}
+		return
+	}
+
+	maxAttempts := h.MaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	if st.attempts >= maxAttempts {
+		st.gaveUp = true
+		event.Action, event.Attempt = ActionGiveUp, st.attempts
+		h.log(event)
+		if h.Escalate != nil && !h.DryRun {
+			h.Escalate(event)
+		}
+		return
+	}
+
+	st.attempts++
+	st.nextAttempt = now.Add(h.backoff(st.attempts))
+	event.Action, event.Attempt = ActionRestart, st.attempts
+	if !h.DryRun {
+		if _, err := h.Client.RestartTask(id.ConnectorName, id.ID); err != nil {
+			event.Action, event.Error = ActionRestartFailed, err.Error()
+		}
+	}
+	h.log(event)
+}
+
+func (h *Healer) running(now time.Time, id connect.TaskID) {
+	st := h.tasks[id]
+	if st == nil {
+		return
+	}
+	if st.runningSince.IsZero() {
+		st.runningSince = now
+	}
+	if now.Sub(st.runningSince) >= orDefault(h.ResetAfter, DefaultResetAfter) {
+		delete(h.tasks, id)
+		if st.attempts > 0 || st.gaveUp {
+			h.log(Event{Time: now, Action: ActionRecovered, Task: id, Attempt: st.attempts, DryRun: h.DryRun})
+		}
+	}
+}
+
+// Wait before the restart after the given one.
+func (h *Healer) backoff(attempt int) time.Duration {
+	backoff, max := orDefault(h.Backoff, DefaultBackoff), orDefault(h.MaxBackoff, DefaultMaxBackoff)
+	for i := 1; i < attempt && backoff < max; i++ {
+		backoff *= 2
+	}
+	if backoff > max {
+		backoff = max
+	}
+	return backoff
+}
+
+func (h *Healer) isExcluded(name, cause string) bool {
+	for _, pattern := range h.ExcludeNames {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	for _, re := range h.ExcludeCauses {
+		if re.MatchString(cause) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *Healer) log(e Event) {
+	if h.Log != nil {
+		h.Log(e)
+	}
+}
+
+func orDefault(d, def time.Duration) time.Duration {
+	if d == 0 {
+		return def
+	}
+	return d
+}
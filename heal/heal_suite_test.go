@@ -0,0 +1,13 @@
+package heal_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestHeal(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "go-kafka/connect Heal Suite")
+}
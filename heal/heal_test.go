@@ -0,0 +1,151 @@
+package heal_test
+
+import (
+	"net/http"
+	"regexp"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	"github.com/go-kafka/connect"
+	. "github.com/go-kafka/connect/heal"
+)
+
+var _ = Describe("Healer", func() {
+	var server *ghttp.Server
+	var healer *Healer
+	var events, escalations []Event
+	var start time.Time
+
+	failed := `{"name":"a","connector":{"state":"RUNNING","worker_id":"w1"},"tasks":[
+		{"id":0,"state":"FAILED","worker_id":"w1","trace":"java.net.ConnectException: Connection refused"},
+		{"id":1,"state":"RUNNING","worker_id":"w1"}]}`
+	running := `{"name":"a","connector":{"state":"RUNNING","worker_id":"w1"},"tasks":[
+		{"id":0,"state":"RUNNING","worker_id":"w1"},{"id":1,"state":"RUNNING","worker_id":"w1"}]}`
+
+	setStatus := func(status string) {
+		server.RouteToHandler("GET", "/connectors/a/status", ghttp.RespondWith(http.StatusOK, status))
+	}
+
+	restarts := func() int {
+		n := 0
+		for _, r := range server.ReceivedRequests() {
+			if r.Method == "POST" {
+				n++
+			}
+		}
+		return n
+	}
+
+	actions := func() []string {
+		var a []string
+		for _, e := range events {
+			a = append(a, e.Action)
+		}
+		return a
+	}
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		server.RouteToHandler("GET", "/connectors", ghttp.RespondWith(http.StatusOK, `["a"]`))
+		server.RouteToHandler("POST", "/connectors/a/tasks/0/restart", ghttp.RespondWith(http.StatusNoContent, nil))
+		setStatus(failed)
+
+		events, escalations = nil, nil
+		start = time.Date(2026, 10, 18, 3, 0, 0, 0, time.UTC)
+		healer = &Healer{
+			Client:      connect.NewClient(server.URL()),
+			Backoff:     time.Minute,
+			MaxBackoff:  3 * time.Minute,
+			MaxAttempts: 3,
+			ResetAfter:  10 * time.Minute,
+			Log:         func(e Event) { events = append(events, e) },
+			Escalate:    func(e Event) { escalations = append(escalations, e) },
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("restarts failed tasks with exponential backoff", func() {
+		Expect(healer.Tick(start)).To(Succeed())
+		Expect(restarts()).To(Equal(1))
+		Expect(events[0]).To(Equal(Event{
+			Time:    start,
+			Action:  ActionRestart,
+			Task:    connect.TaskID{ConnectorName: "a", ID: 0},
+			Attempt: 1,
+			Cause:   "java.net.ConnectException: Connection refused",
+		}))
+
+		Expect(healer.Tick(start.Add(59 * time.Second))).To(Succeed())
+		Expect(restarts()).To(Equal(1), "still backing off")
+		Expect(healer.Tick(start.Add(time.Minute))).To(Succeed())
+		Expect(restarts()).To(Equal(2))
+
+		Expect(healer.Tick(start.Add(2 * time.Minute))).To(Succeed())
+		Expect(restarts()).To(Equal(2), "backoff doubled")
+		Expect(healer.Tick(start.Add(3 * time.Minute))).To(Succeed())
+		Expect(restarts()).To(Equal(3))
+	})
+
+	It("gives up and escalates after the maximum attempts", func() {
+		for minutes := 0; minutes <= 20; minutes++ {
+			Expect(healer.Tick(start.Add(time.Duration(minutes) * time.Minute))).To(Succeed())
+		}
+		Expect(restarts()).To(Equal(3))
+		Expect(actions()).To(Equal([]string{ActionRestart, ActionRestart, ActionRestart, ActionGiveUp}))
+		Expect(escalations).To(HaveLen(1))
+		Expect(escalations[0].Attempt).To(Equal(3))
+		Expect(escalations[0].String()).To(Equal(
+			"gave up on task a/0 after 3 attempts: java.net.ConnectException: Connection refused"))
+	})
+
+	It("starts over once a task has kept running", func() {
+		Expect(healer.Tick(start)).To(Succeed())
+		setStatus(running)
+		Expect(healer.Tick(start.Add(time.Minute))).To(Succeed())
+		Expect(healer.Tick(start.Add(11 * time.Minute))).To(Succeed())
+		Expect(actions()).To(Equal([]string{ActionRestart, ActionRecovered}))
+
+		setStatus(failed)
+		Expect(healer.Tick(start.Add(12 * time.Minute))).To(Succeed())
+		Expect(events[2].Attempt).To(Equal(1))
+	})
+
+	It("leaves excluded tasks alone, logging once", func() {
+		healer.ExcludeCauses = []*regexp.Regexp{regexp.MustCompile(`ConnectException`)}
+		Expect(healer.Tick(start)).To(Succeed())
+		Expect(healer.Tick(start.Add(time.Hour))).To(Succeed())
+		Expect(restarts()).To(Equal(0))
+		Expect(actions()).To(Equal([]string{ActionExcluded}))
+
+		events = nil
+		healer = &Healer{Client: healer.Client, ExcludeNames: []string{"a*"}, Log: healer.Log}
+		Expect(healer.Tick(start)).To(Succeed())
+		Expect(actions()).To(Equal([]string{ActionExcluded}))
+	})
+
+	It("only logs in a dry run", func() {
+		healer.DryRun = true
+		for minutes := 0; minutes <= 20; minutes++ {
+			Expect(healer.Tick(start.Add(time.Duration(minutes) * time.Minute))).To(Succeed())
+		}
+		Expect(restarts()).To(Equal(0))
+		Expect(events[0].String()).To(Equal(
+			"would have restarted task a/0, attempt 1: java.net.ConnectException: Connection refused"))
+		Expect(actions()).To(Equal([]string{ActionRestart, ActionRestart, ActionRestart, ActionGiveUp}))
+		Expect(escalations).To(BeEmpty())
+	})
+
+	It("logs restarts that fail", func() {
+		server.RouteToHandler("POST", "/connectors/a/tasks/0/restart", ghttp.RespondWith(http.StatusConflict,
+			`{"error_code":409,"message":"rebalance in progress"}`))
+		Expect(healer.Tick(start)).To(Succeed())
+		Expect(events[0].Action).To(Equal(ActionRestartFailed))
+		Expect(events[0].Error).To(ContainSubstring("rebalance in progress"))
+	})
+})
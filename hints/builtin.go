@@ -0,0 +1,88 @@
+package hints
+
+const (
+	convertersLink     = "https://www.confluent.io/blog/kafka-connect-deep-dive-converters-serialization-explained/"
+	errorHandlingLink  = "https://www.confluent.io/blog/kafka-connect-deep-dive-error-handling-dead-letter-queues/"
+	authorizationLink  = "https://kafka.apache.org/documentation/#security_authz"
+	connectConfigsLink = "https://kafka.apache.org/documentation/#connectconfigs"
+)
+
+// Ordered roughly from most to least specific, since the first match wins.
+var builtinRules = []Rule{
+	{
+		Name:    "schema-registry-magic-byte",
+		Message: `Unknown magic byte`,
+		Hint: "Records weren't written in the Schema Registry wire format the converter expects. " +
+			"Check key.converter and value.converter against how the topic is produced.",
+		Link: convertersLink,
+	},
+	{
+		Name:    "json-schemas-enable",
+		Class:   `DataException`,
+		Message: `JsonConverter with schemas.enable requires`,
+		Hint: "The JSON records have no schema envelope. Set value.converter.schemas.enable=false " +
+			"(or key.converter.schemas.enable=false), or produce records with schemas.",
+		Link: convertersLink,
+	},
+	{
+		Name:    "converter-mismatch",
+		Class:   `DataException|SerializationException`,
+		Message: `(?i)convert|deserializ|serializ`,
+		Hint: "A record couldn't be converted. Check the converters match the topic's data; to skip bad " +
+			"records, set errors.tolerance=all with errors.deadletterqueue.topic.name.",
+		Link: convertersLink,
+	},
+	{
+		Name:  "authorization",
+		Class: `.*AuthorizationException`,
+		Hint: "The worker's principal lacks ACLs for a topic, group or cluster operation. Grant them, " +
+			"remembering consumer.override.* and producer.override.* principals if set.",
+		Link: authorizationLink,
+	},
+	{
+		Name:  "authentication",
+		Class: `.*AuthenticationException`,
+		Hint:  "Credentials were rejected. Check the connector's or worker's SASL/SSL settings and that the secrets are current.",
+	},
+	{
+		Name:    "missing-table",
+		Message: `(?i)relation "[^"]+" does not exist|table .* (doesn't|does not) exist|ORA-00942|Invalid object name`,
+		Hint:    "The sink's target table doesn't exist. Create it, or set auto.create=true for the JDBC sink.",
+	},
+	{
+		Name:  "record-too-large",
+		Class: `RecordTooLargeException`,
+		Hint: "A record is larger than the producer allows, e.g. writing to a dead letter queue. Raise " +
+			"producer.override.max.request.size, and the topic's max.message.bytes to match.",
+		Link: errorHandlingLink,
+	},
+	{
+		Name:  "unknown-topic",
+		Class: `UnknownTopicOrPartitionException`,
+		Hint: "A topic the connector uses doesn't exist, possibly its dead letter queue. Create it, " +
+			"or allow topic creation for the connector.",
+	},
+	{
+		Name:  "connection-refused",
+		Class: `java\.net\.(ConnectException|UnknownHostException|NoRouteToHostException)`,
+		Hint:  "An external system can't be reached. Check it's up and reachable from every worker, then restart the failed tasks.",
+	},
+	{
+		Name:  "kafka-timeout",
+		Class: `org\.apache\.kafka\.common\.errors\.TimeoutException`,
+		Hint:  "Kafka didn't respond in time. Check the brokers are healthy and reachable from the workers.",
+	},
+	{
+		Name:  "bad-config",
+		Class: `org\.apache\.kafka\.common\.config\.ConfigException`,
+		Hint:  "The connector config is invalid. Check it against the settings the plugin documents.",
+		Link:  connectConfigsLink,
+	},
+	{
+		Name:    "tolerance-exceeded",
+		Message: `Tolerance exceeded in error handler`,
+		Hint: "A record failed and errors.tolerance is none. Fix the cause given in the full trace, or set " +
+			"errors.tolerance=all with a dead letter queue to route bad records aside.",
+		Link: errorHandlingLink,
+	},
+}
@@ -0,0 +1,177 @@
+// Package hints matches failures in Java stack traces against a catalog of
+// known errors, to suggest what to try first when a connector or task fails.
+//
+// A rule matches an exception by class, by message, or both. Rules may be
+// loaded from YAML in addition to the built-in ones:
+//
+//	rules:
+//	- name: orders-db-down
+//	  class: java\.net\.ConnectException
+//	  message: orders-db
+//	  hint: The orders database is down, see the runbook.
+//	  link: https://wiki.example.com/runbooks/orders-db
+package hints
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/go-kafka/connect/trace"
+)
+
+// A Rule maps exceptions to a remediation hint.
+type Rule struct {
+	Name string `yaml:"name" json:"name"`
+
+	// Regular expression matching the whole exception class, either fully
+	// qualified or its simple name.
+	Class string `yaml:"class,omitempty" json:"class,omitempty"`
+
+	// Regular expression matching anywhere in the exception message.
+	Message string `yaml:"message,omitempty" json:"message,omitempty"`
+
+	Hint string `yaml:"hint" json:"hint"`
+
+	// Optional documentation about the error.
+	Link string `yaml:"link,omitempty" json:"link,omitempty"`
+
+	class, message *regexp.Regexp
+}
+
+// compile checks a rule and compiles its patterns.
+func (r *Rule) compile() error {
+	if r.Name == "" {
+		return fmt.Errorf("rule without a name")
+	}
+	if r.Hint == "" {
+		return fmt.Errorf("rule %v has no hint", r.Name)
+	}
+	if r.Class == "" && r.Message == "" {
+		return fmt.Errorf("rule %v needs a class or message to match", r.Name)
+	}
+
+	var err error
+	if r.Class != "" {
+		if r.class, err = regexp.Compile(`^(?:` + r.Class + `)$`); err != nil {
+			return fmt.Errorf("rule %v has an invalid class pattern: %v", r.Name, err)
+		}
+	}
+	if r.Message != "" {
+		if r.message, err = regexp.Compile(r.Message); err != nil {
+			return fmt.Errorf("rule %v has an invalid message pattern: %v", r.Name, err)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether the rule matches a single exception, not looking
+// at its causes.
+func (r *Rule) Matches(e *trace.Exception) bool {
+	if r.class != nil && !r.class.MatchString(e.Class) && !r.class.MatchString(simpleName(e.Class)) {
+		return false
+	}
+	return r.message == nil || r.message.MatchString(e.Message)
+}
+
+func simpleName(class string) string {
+	for i := len(class) - 1; i >= 0; i-- {
+		if class[i] == '.' || class[i] == '$' {
+			return class[i+1:]
+		}
+	}
+	return class
+}
+
+// A Catalog is an ordered set of rules. Earlier rules take precedence.
+type Catalog struct {
+	rules []*Rule
+}
+
+// New returns a catalog of the given rules, failing if any is invalid.
+func New(rules ...Rule) (*Catalog, error) {
+	c := &Catalog{}
+	return c, c.Add(rules...)
+}
+
+// Builtin returns a catalog of the built-in rules for common Kafka Connect
+// failures.
+func Builtin() *Catalog {
+	c, err := New(builtinRules...)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Add appends rules to the catalog, after those it has.
+func (c *Catalog) Add(rules ...Rule) error {
+	for i := range rules {
+		r := rules[i]
+		if err := r.compile(); err != nil {
+			return err
+		}
+		c.rules = append(c.rules, &r)
+	}
+	return nil
+}
+
+// Rules returns the rules of the catalog in order of precedence.
+func (c *Catalog) Rules() []Rule {
+	rules := make([]Rule, len(c.rules))
+	for i, r := range c.rules {
+		rules[i] = *r
+	}
+	return rules
+}
+
+// Load reads rules from a YAML file and returns a catalog of them ahead of the
+// built-in rules, so that they can override them.
+func Load(path string) (*Catalog, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Rules []Rule `yaml:"rules"`
+	}
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("%v: %v", path, err)
+	}
+
+	c, err := New(file.Rules...)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", path, err)
+	}
+	c.rules = append(c.rules, Builtin().rules...)
+	return c, nil
+}
+
+// Match returns the rule for an exception, or nil if none matches. Rules are
+// tried in order of precedence, each against the root cause first and then
+// each exception wrapping it, since the root cause is usually the most
+// specific.
+func (c *Catalog) Match(e *trace.Exception) *Rule {
+	chain := e.Chain()
+	for _, r := range c.rules {
+		for i := len(chain) - 1; i >= 0; i-- {
+			if r.Matches(chain[i]) {
+				return r
+			}
+		}
+	}
+	return nil
+}
+
+// MatchTrace returns the rule for a stack trace, or nil if none matches. Text
+// that isn't a trace is matched as the message of an exception without class.
+func (c *Catalog) MatchTrace(s string) *Rule {
+	e, err := trace.Parse(s)
+	if err != nil {
+		e = &trace.Exception{Message: s}
+	}
+	return c.Match(e)
+}
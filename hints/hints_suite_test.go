@@ -0,0 +1,13 @@
+package hints_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestHints(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "go-kafka/connect Hints Suite")
+}
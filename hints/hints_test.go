@@ -0,0 +1,114 @@
+package hints_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/go-kafka/connect/hints"
+)
+
+const missingTable = `org.apache.kafka.connect.errors.ConnectException: Exiting WorkerSinkTask due to unrecoverable exception.
+	at org.apache.kafka.connect.runtime.WorkerSinkTask.deliverMessages(WorkerSinkTask.java:614)
+Caused by: org.apache.kafka.connect.errors.ConnectException: Tolerance exceeded in error handler
+	at org.apache.kafka.connect.runtime.errors.RetryWithToleranceOperator.execAndHandleError(RetryWithToleranceOperator.java:206)
+	... 1 more
+Caused by: org.postgresql.util.PSQLException: ERROR: relation "orders" does not exist
+	at org.postgresql.core.v3.QueryExecutorImpl.receiveErrorResponse(QueryExecutorImpl.java:2553)
+	... 2 more
+`
+
+var _ = Describe("Catalog", func() {
+	It("matches the root cause ahead of exceptions wrapping it", func() {
+		rule := Builtin().MatchTrace(missingTable)
+		Expect(rule).NotTo(BeNil())
+		Expect(rule.Name).To(Equal("missing-table"))
+	})
+
+	It("falls back to wrapping exceptions", func() {
+		rule := Builtin().MatchTrace("org.apache.kafka.connect.errors.ConnectException: Tolerance exceeded in error handler\n" +
+			"Caused by: com.example.WeirdException: no idea")
+		Expect(rule).NotTo(BeNil())
+		Expect(rule.Name).To(Equal("tolerance-exceeded"))
+	})
+
+	It("matches classes by simple name", func() {
+		rule := Builtin().MatchTrace("org.apache.kafka.common.errors.TopicAuthorizationException: Not authorized to access topics: [orders]")
+		Expect(rule).NotTo(BeNil())
+		Expect(rule.Name).To(Equal("authorization"))
+	})
+
+	It("matches text that isn't a trace by message", func() {
+		Expect(Builtin().MatchTrace("Unknown magic byte!").Name).To(Equal("schema-registry-magic-byte"))
+	})
+
+	It("returns nil without a match", func() {
+		Expect(Builtin().MatchTrace("java.lang.IllegalStateException: odd")).To(BeNil())
+	})
+
+	It("rejects invalid rules", func() {
+		_, err := New(Rule{Name: "no-pattern", Hint: "nothing to match"})
+		Expect(err).To(MatchError("rule no-pattern needs a class or message to match"))
+		_, err = New(Rule{Name: "bad", Message: "(", Hint: "broken"})
+		Expect(err).To(MatchError(ContainSubstring("rule bad has an invalid message pattern")))
+	})
+
+	Describe("Load", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "hints")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		write := func(content string) string {
+			path := filepath.Join(dir, "hints.yaml")
+			Expect(ioutil.WriteFile(path, []byte(content), 0644)).To(Succeed())
+			return path
+		}
+
+		It("puts loaded rules ahead of the built-in ones", func() {
+			catalog, err := Load(write(`
+rules:
+- name: orders-schema
+  message: relation "orders"
+  hint: Run the orders migrations.
+  link: https://wiki.example.com/orders
+`))
+			Expect(err).NotTo(HaveOccurred())
+			rule := catalog.MatchTrace(missingTable)
+			Expect(rule.Name).To(Equal("orders-schema"))
+			Expect(rule.Link).To(Equal("https://wiki.example.com/orders"))
+			Expect(len(catalog.Rules())).To(BeNumerically(">", 1))
+		})
+
+		It("prefers loaded rules even when a built-in one matches a deeper cause", func() {
+			catalog, err := Load(write(`
+rules:
+- name: sink-exit
+  message: Exiting WorkerSinkTask
+  hint: Check the sink's dead letter queue.
+`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(catalog.MatchTrace(missingTable).Name).To(Equal("sink-exit"))
+		})
+
+		It("reports invalid rules with the file", func() {
+			_, err := Load(write("rules:\n- name: empty\n  class: Foo\n"))
+			Expect(err).To(MatchError(ContainSubstring("hints.yaml: rule empty has no hint")))
+		})
+
+		It("rejects unknown fields", func() {
+			_, err := Load(write("rules:\n- name: typo\n  mesage: x\n  hint: y\n"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,151 @@
+// Package lint checks connector configs offline for common mistakes, before
+// they reach a Connect cluster.
+//
+// A Linter runs a set of Rules over a config. The rules in DefaultRules cover
+// mistakes any connector can make; add your own for house conventions:
+//
+//	linter := lint.New(append(lint.DefaultRules(), lint.Rule{
+//		ID:          "owner-tag",
+//		Severity:    lint.Warning,
+//		Description: "Connectors should name an owning team.",
+//		Check: func(config connect.ConnectorConfig) []lint.Finding {
+//			if config["x.owner"] == "" {
+//				return []lint.Finding{{Key: "x.owner", Message: "x.owner is not set"}}
+//			}
+//			return nil
+//		},
+//	})...)
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-kafka/connect"
+)
+
+// Severity is how serious a finding is.
+type Severity int
+
+// Severities in increasing order. The zero value is none in particular, see
+// Rule.Check.
+const (
+	Info Severity = iota + 1
+	Warning
+	Error
+)
+
+var severityNames = []string{"info", "warning", "error"}
+
+func (s Severity) String() string {
+	if s < Info || s > Error {
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+	return severityNames[s-1]
+}
+
+// ParseSeverity parses the name of a severity: info, warning or error.
+func ParseSeverity(name string) (Severity, error) {
+	for i, n := range severityNames {
+		if strings.EqualFold(name, n) {
+			return Severity(i + 1), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown severity %q, try info, warning or error", name)
+}
+
+// MarshalText encodes a severity by name.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a severity name.
+func (s *Severity) UnmarshalText(text []byte) (err error) {
+	*s, err = ParseSeverity(string(text))
+	return
+}
+
+// A Finding is a problem a rule found in a config.
+type Finding struct {
+	// ID of the rule and severity, filled in by the Linter.
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+
+	// The config key at fault, if there is one.
+	Key     string `json:"key,omitempty"`
+	Message string `json:"message"`
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%v: %v [%v]", f.Severity, f.Message, f.Rule)
+}
+
+// A Rule checks configs for one kind of mistake.
+type Rule struct {
+	ID          string
+	Severity    Severity
+	Description string
+
+	// Check returns a finding for each problem in config. Rule and Severity of
+	// the findings are set from the rule, unless a finding sets a Severity of
+	// its own.
+	Check func(config connect.ConnectorConfig) []Finding
+}
+
+// A Linter checks configs against a set of rules.
+type Linter struct {
+	Rules []Rule
+}
+
+// New returns a Linter with the given rules.
+func New(rules ...Rule) *Linter {
+	return &Linter{Rules: rules}
+}
+
+// Default returns a Linter with the DefaultRules.
+func Default() *Linter {
+	return New(DefaultRules()...)
+}
+
+// Lint checks config against every rule, returning findings most severe first
+// and otherwise by rule and key.
+func (l *Linter) Lint(config connect.ConnectorConfig) []Finding {
+	var findings []Finding
+	for _, rule := range l.Rules {
+		for _, f := range rule.Check(config) {
+			f.Rule = rule.ID
+			if f.Severity == 0 {
+				f.Severity = rule.Severity
+			}
+			findings = append(findings, f)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Severity != b.Severity {
+			return a.Severity > b.Severity
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		return a.Key < b.Key
+	})
+	return findings
+}
+
+// Worst returns the highest severity among findings, and false if there are
+// none.
+func Worst(findings []Finding) (Severity, bool) {
+	if len(findings) == 0 {
+		return 0, false
+	}
+	worst := findings[0].Severity
+	for _, f := range findings[1:] {
+		if f.Severity > worst {
+			worst = f.Severity
+		}
+	}
+	return worst, true
+}
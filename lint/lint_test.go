@@ -0,0 +1,134 @@
+package lint_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-kafka/connect"
+	. "github.com/go-kafka/connect/lint"
+)
+
+var _ = Describe("Linter", func() {
+	lintRule := func(config connect.ConnectorConfig, rule string) []Finding {
+		var found []Finding
+		for _, f := range Default().Lint(config) {
+			if f.Rule == rule {
+				found = append(found, f)
+			}
+		}
+		return found
+	}
+
+	It("finds nothing wrong with a sensible config", func() {
+		Expect(Default().Lint(connect.ConnectorConfig{
+			"connector.class":                   "io.confluent.connect.jdbc.JdbcSinkConnector",
+			"tasks.max":                         "2",
+			"topics":                            "orders",
+			"connection.password":               "${file:/etc/secrets.properties:orders-db}",
+			"errors.tolerance":                  "all",
+			"errors.deadletterqueue.topic.name": "orders-dlq",
+			"value.converter":                   "org.apache.kafka.connect.json.JsonConverter",
+			"value.converter.schemas.enable":    "false",
+			"transforms":                        "route",
+			"transforms.route.type":             "org.apache.kafka.connect.transforms.RegexRouter",
+		})).To(BeEmpty())
+	})
+
+	It("orders findings by severity", func() {
+		findings := Default().Lint(connect.ConnectorConfig{"topics": "a", "topics.regex": "b.*"})
+		Expect(findings).To(HaveLen(2))
+		Expect(findings[0]).To(Equal(Finding{
+			Rule:     "topics-conflict",
+			Severity: Error,
+			Key:      "topics.regex",
+			Message:  "topics and topics.regex are both set, Connect rejects this",
+		}))
+		Expect(findings[1].Rule).To(Equal("tasks-max"))
+		Expect(findings[1].Severity).To(Equal(Warning))
+
+		worst, ok := Worst(findings)
+		Expect(ok).To(BeTrue())
+		Expect(worst).To(Equal(Error))
+	})
+
+	It("runs custom rules", func() {
+		linter := New(Rule{
+			ID:       "owner",
+			Severity: Info,
+			Check: func(config connect.ConnectorConfig) []Finding {
+				return []Finding{{Key: "x.owner", Message: "no owner"}}
+			},
+		})
+		Expect(linter.Lint(connect.ConnectorConfig{})).To(Equal([]Finding{
+			{Rule: "owner", Severity: Info, Key: "x.owner", Message: "no owner"},
+		}))
+	})
+
+	It("flags an invalid tasks.max as an error", func() {
+		findings := lintRule(connect.ConnectorConfig{"tasks.max": "zero"}, "tasks-max")
+		Expect(findings).To(HaveLen(1))
+		Expect(findings[0].Severity).To(Equal(Error))
+	})
+
+	It("flags sinks tolerating all errors without a dead letter queue", func() {
+		Expect(lintRule(connect.ConnectorConfig{"topics": "a", "errors.tolerance": "all"}, "tolerance-without-dlq")).
+			To(HaveLen(1))
+		Expect(lintRule(connect.ConnectorConfig{"errors.tolerance": "all"}, "tolerance-without-dlq")).
+			To(BeEmpty(), "sources have no dead letter queue")
+	})
+
+	It("flags converter settings that don't suit the converter", func() {
+		findings := lintRule(connect.ConnectorConfig{
+			"key.converter":                  "org.apache.kafka.connect.storage.StringConverter",
+			"key.converter.schemas.enable":   "true",
+			"value.converter":                "io.confluent.connect.avro.AvroConverter",
+			"value.converter.schemas.enable": "yes",
+		}, "converter-settings")
+		Expect(findings).To(HaveLen(4))
+		Expect(findings[0].Key).To(Equal("value.converter.schemas.enable"))
+		Expect(findings[0].Severity).To(Equal(Error))
+		Expect(findings[1].Key).To(Equal("key.converter.schemas.enable"))
+		Expect(findings[1].Message).To(ContainSubstring("only applies to org.apache.kafka.connect.json.JsonConverter"))
+		Expect(findings[2].Message).To(Equal("value.converter io.confluent.connect.avro.AvroConverter needs value.converter.schema.registry.url"))
+		Expect(findings[3].Key).To(Equal("value.converter.schemas.enable"))
+	})
+
+	It("flags secrets in plain text", func() {
+		findings := lintRule(connect.ConnectorConfig{
+			"connection.password":                "hunter2",
+			"aws.secret.access.key":              "${env:AWS_SECRET}",
+			"consumer.override.sasl.jaas.config": `org.apache.kafka.common.security.plain.PlainLoginModule required username="u" password="p";`,
+			"password.type":                      "plain",
+		}, "plaintext-secret")
+		Expect(findings).To(HaveLen(2))
+		Expect(findings[0].Key).To(Equal("connection.password"))
+		Expect(findings[0].Message).NotTo(ContainSubstring("hunter2"))
+		Expect(findings[1].Key).To(Equal("consumer.override.sasl.jaas.config"))
+	})
+
+	It("flags transforms and predicates that aren't defined", func() {
+		findings := lintRule(connect.ConnectorConfig{
+			"transforms":                 "route, mask",
+			"transforms.route.type":      "org.apache.kafka.connect.transforms.RegexRouter",
+			"transforms.route.predicate": "isOrders",
+			"transforms.old.type":        "org.apache.kafka.connect.transforms.InsertField$Value",
+			"predicates":                 "",
+		}, "transform-aliases")
+		Expect(findings).To(HaveLen(3))
+		Expect(findings[0].Message).To(Equal("transforms alias mask has no transforms.mask.type"))
+		Expect(findings[1].Message).To(Equal("transform route uses predicate isOrders, which isn't listed in predicates"))
+		Expect(findings[2].Severity).To(Equal(Warning))
+		Expect(findings[2].Message).To(Equal("transforms.old.type configures alias old, which isn't listed in transforms"))
+	})
+})
+
+var _ = Describe("Severity", func() {
+	It("parses and prints names", func() {
+		s, err := ParseSeverity("Warning")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s).To(Equal(Warning))
+		Expect(s.String()).To(Equal("warning"))
+		_, err = ParseSeverity("fatal")
+		Expect(err).To(HaveOccurred())
+	})
+})
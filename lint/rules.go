@@ -0,0 +1,222 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-kafka/connect"
+)
+
+// DefaultRules returns the built-in rules.
+func DefaultRules() []Rule {
+	return []Rule{
+		{
+			ID:          "tasks-max",
+			Severity:    Warning,
+			Description: "tasks.max should be set to a positive number.",
+			Check:       checkTasksMax,
+		},
+		{
+			ID:          "topics-conflict",
+			Severity:    Error,
+			Description: "A sink may set topics or topics.regex, not both.",
+			Check:       checkTopicsConflict,
+		},
+		{
+			ID:          "tolerance-without-dlq",
+			Severity:    Warning,
+			Description: "A sink tolerating all errors should send failed records to a dead letter queue.",
+			Check:       checkToleranceWithoutDLQ,
+		},
+		{
+			ID:          "converter-settings",
+			Severity:    Warning,
+			Description: "Converter settings should suit the converter class.",
+			Check:       checkConverterSettings,
+		},
+		{
+			ID:          "plaintext-secret",
+			Severity:    Error,
+			Description: "Secrets should be config provider references, not plain text.",
+			Check:       checkPlaintextSecrets,
+		},
+		{
+			ID:          "transform-aliases",
+			Severity:    Error,
+			Description: "Transforms and predicates should be listed and configured consistently.",
+			Check:       checkTransformAliases,
+		},
+	}
+}
+
+func isSink(config connect.ConnectorConfig) bool {
+	return config["topics"] != "" || config["topics.regex"] != ""
+}
+
+func checkTasksMax(config connect.ConnectorConfig) []Finding {
+	value, ok := config["tasks.max"]
+	if !ok {
+		return []Finding{{Key: "tasks.max", Message: "tasks.max is not set, so the connector runs a single task"}}
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(value)); err != nil || n < 1 {
+		return []Finding{{Severity: Error, Key: "tasks.max", Message: fmt.Sprintf("tasks.max %q is not a positive number", value)}}
+	}
+	return nil
+}
+
+func checkTopicsConflict(config connect.ConnectorConfig) []Finding {
+	if config["topics"] != "" && config["topics.regex"] != "" {
+		return []Finding{{Key: "topics.regex", Message: "topics and topics.regex are both set, Connect rejects this"}}
+	}
+	return nil
+}
+
+func checkToleranceWithoutDLQ(config connect.ConnectorConfig) []Finding {
+	if !isSink(config) || !strings.EqualFold(config["errors.tolerance"], "all") {
+		return nil
+	}
+	if config["errors.deadletterqueue.topic.name"] == "" {
+		return []Finding{{
+			Key:     "errors.tolerance",
+			Message: "errors.tolerance=all without errors.deadletterqueue.topic.name silently drops records that fail",
+		}}
+	}
+	return nil
+}
+
+const jsonConverter = "org.apache.kafka.connect.json.JsonConverter"
+
+// Converters that need a Schema Registry.
+var registryConverters = map[string]bool{
+	"io.confluent.connect.avro.AvroConverter":         true,
+	"io.confluent.connect.protobuf.ProtobufConverter": true,
+	"io.confluent.connect.json.JsonSchemaConverter":   true,
+}
+
+func checkConverterSettings(config connect.ConnectorConfig) []Finding {
+	var findings []Finding
+	for _, prefix := range []string{"key.converter", "value.converter"} {
+		class := config[prefix]
+		registryKey := prefix + ".schema.registry.url"
+		schemasKey := prefix + ".schemas.enable"
+
+		if schemas, ok := config[schemasKey]; ok {
+			if _, err := strconv.ParseBool(schemas); err != nil {
+				findings = append(findings, Finding{Severity: Error, Key: schemasKey,
+					Message: fmt.Sprintf("%v %q is not true or false", schemasKey, schemas)})
+			}
+		}
+
+		// Without a class the worker's converter applies, which we can't see
+		if class == "" {
+			continue
+		}
+		if registryConverters[class] && config[registryKey] == "" {
+			findings = append(findings, Finding{Key: prefix,
+				Message: fmt.Sprintf("%v %v needs %v", prefix, class, registryKey)})
+		}
+		if !registryConverters[class] && config[registryKey] != "" {
+			findings = append(findings, Finding{Key: registryKey,
+				Message: fmt.Sprintf("%v has no effect with %v %v", registryKey, prefix, class)})
+		}
+		if class != jsonConverter && config[schemasKey] != "" {
+			findings = append(findings, Finding{Key: schemasKey,
+				Message: fmt.Sprintf("%v only applies to %v, not %v", schemasKey, jsonConverter, class)})
+		}
+	}
+	return findings
+}
+
+var (
+	secretKey         = regexp.MustCompile(`(?i)(^|[._-])(password|passwd|secret|token|api[._-]?key|access[._-]?key|private[._-]?key|credentials?)$`)
+	jaasPassword      = regexp.MustCompile(`(?i)password\s*=\s*"[^"$]`)
+	providerReference = regexp.MustCompile(`^\$\{[^}:]+:[^}]*\}$`)
+)
+
+func checkPlaintextSecrets(config connect.ConnectorConfig) []Finding {
+	var findings []Finding
+	for _, key := range sortedKeys(config) {
+		value := strings.TrimSpace(config[key])
+		if value == "" || providerReference.MatchString(value) {
+			continue
+		}
+		if secretKey.MatchString(key) ||
+			(strings.HasSuffix(key, "sasl.jaas.config") && jaasPassword.MatchString(value)) {
+			findings = append(findings, Finding{Key: key,
+				Message: fmt.Sprintf("%v is in plain text, use a config provider reference such as ${file:/path/to/secrets:%v}", key, key)})
+		}
+	}
+	return findings
+}
+
+func checkTransformAliases(config connect.ConnectorConfig) []Finding {
+	findings := checkAliases(config, "transforms")
+	findings = append(findings, checkAliases(config, "predicates")...)
+
+	predicates := listSet(config["predicates"])
+	for _, alias := range listValues(config["transforms"]) {
+		key := "transforms." + alias + ".predicate"
+		if p := config[key]; p != "" && !predicates[p] {
+			findings = append(findings, Finding{Key: key,
+				Message: fmt.Sprintf("transform %v uses predicate %v, which isn't listed in predicates", alias, p)})
+		}
+	}
+	return findings
+}
+
+// Checks that each alias listed in config[kind] has a type, and that no
+// settings are left for aliases that aren't listed, e.g. after a rename.
+func checkAliases(config connect.ConnectorConfig, kind string) []Finding {
+	var findings []Finding
+	listed := listSet(config[kind])
+	for _, alias := range listValues(config[kind]) {
+		if config[kind+"."+alias+".type"] == "" {
+			findings = append(findings, Finding{Key: kind,
+				Message: fmt.Sprintf("%v alias %v has no %v.%v.type", kind, alias, kind, alias)})
+		}
+	}
+
+	reported := make(map[string]bool)
+	for _, key := range sortedKeys(config) {
+		if !strings.HasPrefix(key, kind+".") {
+			continue
+		}
+		alias := strings.SplitN(strings.TrimPrefix(key, kind+"."), ".", 2)[0]
+		if !listed[alias] && !reported[alias] {
+			reported[alias] = true
+			findings = append(findings, Finding{Severity: Warning, Key: key,
+				Message: fmt.Sprintf("%v configures alias %v, which isn't listed in %v", key, alias, kind)})
+		}
+	}
+	return findings
+}
+
+func listValues(list string) []string {
+	var values []string
+	for _, v := range strings.Split(list, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func listSet(list string) map[string]bool {
+	set := make(map[string]bool)
+	for _, v := range listValues(list) {
+		set[v] = true
+	}
+	return set
+}
+
+func sortedKeys(config connect.ConnectorConfig) []string {
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,235 @@
+// Package migrate copies connectors from one Kafka Connect cluster to another,
+// optionally renaming them and rewriting their configuration on the way, and
+// can keep a target cluster continuously in sync with a source.
+package migrate
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-kafka/connect"
+)
+
+// A Rename rule changes the names of connectors matching Pattern, expanding
+// Replacement as regexp.ReplaceAllString does.
+type Rename struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// A Rewrite rule changes the value of config Key wherever it matches Pattern,
+// expanding Replacement as regexp.ReplaceAllString does.
+type Rewrite struct {
+	Key         string
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// ParseRename parses a rename rule given as "PATTERN=REPLACEMENT".
+func ParseRename(rule string) (Rename, error) {
+	i := strings.Index(rule, "=")
+	if i < 0 {
+		return Rename{}, fmt.Errorf("rename rule %q is not in the form PATTERN=REPLACEMENT", rule)
+	}
+	pattern, err := regexp.Compile(rule[:i])
+	if err != nil {
+		return Rename{}, err
+	}
+	return Rename{pattern, rule[i+1:]}, nil
+}
+
+// ParseRewrite parses a rewrite rule given as "KEY:PATTERN=REPLACEMENT".
+func ParseRewrite(rule string) (Rewrite, error) {
+	i := strings.Index(rule, ":")
+	if i < 0 {
+		return Rewrite{}, fmt.Errorf("rewrite rule %q is not in the form KEY:PATTERN=REPLACEMENT", rule)
+	}
+	rename, err := ParseRename(rule[i+1:])
+	if err != nil {
+		return Rewrite{}, fmt.Errorf("rewrite rule %q is not in the form KEY:PATTERN=REPLACEMENT", rule)
+	}
+	return Rewrite{rule[:i], rename.Pattern, rename.Replacement}, nil
+}
+
+// Options controls which connectors are migrated and how.
+type Options struct {
+	// Glob patterns, as for path.Match, selecting connectors by name. If
+	// empty, all connectors are selected.
+	Patterns []string
+
+	// Rules applied in order to connector names and config values.
+	Renames  []Rename
+	Rewrites []Rewrite
+
+	// Config values set on the target regardless of the source config.
+	Set connect.ConnectorConfig
+
+	// How long to wait for a migrated connector and its tasks to be RUNNING
+	// on the target.
+	// If zero, Migrate does not wait, unless DeleteSource is set.
+	WaitTimeout time.Duration
+
+	// Delete connectors from the source once they are running on the target.
+	DeleteSource bool
+}
+
+// Selects reports whether the connector with the given name is covered by
+// o.Patterns.
+func (o *Options) Selects(name string) bool {
+	if len(o.Patterns) == 0 {
+		return true
+	}
+	for _, pattern := range o.Patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// TargetName applies rename rules to a source connector name.
+func (o *Options) TargetName(name string) string {
+	for _, rule := range o.Renames {
+		name = rule.Pattern.ReplaceAllString(name, rule.Replacement)
+	}
+	return name
+}
+
+// TargetConfig applies rewrite rules and overrides to a copy of a source
+// connector's config, for a connector named targetName.
+func (o *Options) TargetConfig(config connect.ConnectorConfig, targetName string) connect.ConnectorConfig {
+	result := make(connect.ConnectorConfig, len(config))
+	for k, v := range config {
+		result[k] = v
+	}
+
+	for _, rule := range o.Rewrites {
+		if value, ok := result[rule.Key]; ok {
+			result[rule.Key] = rule.Pattern.ReplaceAllString(value, rule.Replacement)
+		}
+	}
+	for k, v := range o.Set {
+		result[k] = v
+	}
+
+	// The API rejects a name in config that disagrees with the URL.
+	if _, ok := result["name"]; ok {
+		result["name"] = targetName
+	}
+
+	return result
+}
+
+// Actions reported in a Result.
+const (
+	Created   = "created"
+	Updated   = "updated"
+	Unchanged = "unchanged"
+	Moved     = "moved" // created on target and deleted from source
+	Pruned    = "pruned"
+)
+
+// A Result reports what happened to one connector.
+type Result struct {
+	Name       string // Name on the source cluster
+	TargetName string
+	Action     string
+}
+
+// selected lists connector names on the source cluster chosen by opts, sorted.
+func selected(source *connect.Client, opts *Options) ([]string, error) {
+	names, _, err := source.ListConnectors()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for _, name := range names {
+		if opts.Selects(name) {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// Migrate copies connectors selected by opts from source to target. It is
+// safe to run again after a partial failure: connectors that already exist on
+// the target are updated rather than causing conflicts.
+//
+// Results are returned for the connectors migrated before any error occurred.
+func Migrate(source, target *connect.Client, opts Options) ([]Result, error) {
+	names, err := selected(source, &opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no connectors matched %v", strings.Join(opts.Patterns, ", "))
+	}
+
+	var results []Result
+	for _, name := range names {
+		result, err := migrateOne(source, target, name, &opts)
+		if err != nil {
+			return results, fmt.Errorf("migrating %v: %v", name, err)
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func migrateOne(source, target *connect.Client, name string, opts *Options) (Result, error) {
+	result := Result{Name: name, TargetName: opts.TargetName(name)}
+
+	config, _, err := source.GetConnectorConfig(name)
+	if err != nil {
+		return result, err
+	}
+
+	conn := connect.Connector{
+		Name:   result.TargetName,
+		Config: opts.TargetConfig(config, result.TargetName),
+	}
+	created, _, err := target.UpsertConnector(&conn)
+	if err != nil {
+		return result, err
+	}
+	result.Action = Updated
+	if created {
+		result.Action = Created
+	}
+
+	timeout := opts.WaitTimeout
+	if timeout == 0 && opts.DeleteSource {
+		timeout = 5 * time.Minute
+	}
+	if timeout > 0 {
+		// A connector reports RUNNING before its tasks are started, so unless
+		// it ran without tasks on the source, wait for tasks to show up too.
+		sourceStatus, _, err := source.GetConnectorStatus(name)
+		if err != nil {
+			return result, err
+		}
+		needsTasks := len(sourceStatus.Tasks) > 0
+		running := func(s *connect.ConnectorStatus) bool {
+			return s.IsRunning() && (len(s.Tasks) > 0 || !needsTasks)
+		}
+		if _, err := target.WaitForConnector(result.TargetName, running, timeout, 0); err != nil {
+			return result, fmt.Errorf("not healthy on target: %v", err)
+		}
+	}
+
+	if opts.DeleteSource {
+		if _, err := source.DeleteConnector(name); err != nil {
+			return result, err
+		}
+		result.Action = Moved
+	}
+
+	return result, nil
+}
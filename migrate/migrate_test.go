@@ -0,0 +1,148 @@
+package migrate_test
+
+import (
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	"github.com/go-kafka/connect"
+	. "github.com/go-kafka/connect/migrate"
+)
+
+var _ = Describe("Options", func() {
+	var opts Options
+
+	BeforeEach(func() {
+		rename, err := ParseRename(`^prod-(.*)$=staging-$1`)
+		Expect(err).NotTo(HaveOccurred())
+		rewrite, err := ParseRewrite(`topics:^prod\.=staging.`)
+		Expect(err).NotTo(HaveOccurred())
+
+		opts = Options{
+			Patterns: []string{"prod-*"},
+			Renames:  []Rename{rename},
+			Rewrites: []Rewrite{rewrite},
+			Set:      connect.ConnectorConfig{"tasks.max": "1"},
+		}
+	})
+
+	It("selects connectors by glob", func() {
+		Expect(opts.Selects("prod-sink")).To(BeTrue())
+		Expect(opts.Selects("dev-sink")).To(BeFalse())
+	})
+
+	It("renames connectors", func() {
+		Expect(opts.TargetName("prod-sink")).To(Equal("staging-sink"))
+	})
+
+	It("rewrites and overrides config values, and fixes up name", func() {
+		config := connect.ConnectorConfig{
+			"name":      "prod-sink",
+			"topics":    "prod.orders",
+			"tasks.max": "8",
+		}
+		Expect(opts.TargetConfig(config, "staging-sink")).To(Equal(connect.ConnectorConfig{
+			"name":      "staging-sink",
+			"topics":    "staging.orders",
+			"tasks.max": "1",
+		}))
+		Expect(config["topics"]).To(Equal("prod.orders"))
+	})
+
+	It("rejects malformed rules", func() {
+		_, err := ParseRewrite("topics=foo")
+		Expect(err).To(HaveOccurred())
+		_, err = ParseRename("nope")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Migrate", func() {
+	var source, target *ghttp.Server
+
+	BeforeEach(func() {
+		source = ghttp.NewServer()
+		target = ghttp.NewServer()
+
+		source.AppendHandlers(
+			ghttp.RespondWith(http.StatusOK, `["prod-sink","other"]`),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/connectors/prod-sink/config"),
+				ghttp.RespondWith(http.StatusOK, `{"name":"prod-sink","topics":"prod.orders"}`),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/connectors/prod-sink/status"),
+				ghttp.RespondWith(http.StatusOK,
+					`{"name":"prod-sink","connector":{"state":"RUNNING"},"tasks":[{"id":0,"state":"RUNNING"}]}`),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("DELETE", "/connectors/prod-sink"),
+				ghttp.RespondWith(http.StatusNoContent, nil),
+			),
+		)
+		target.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("PUT", "/connectors/prod-sink/config"),
+				ghttp.VerifyJSON(`{"name":"prod-sink","topics":"prod.orders"}`),
+				ghttp.RespondWith(http.StatusCreated, `{"name":"prod-sink"}`),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/connectors/prod-sink/status"),
+				ghttp.RespondWith(http.StatusOK,
+					`{"name":"prod-sink","connector":{"state":"RUNNING"},"tasks":[{"id":0,"state":"RUNNING"}]}`),
+			),
+		)
+	})
+
+	AfterEach(func() {
+		source.Close()
+		target.Close()
+	})
+
+	It("creates selected connectors on the target and deletes them from the source once running", func() {
+		results, err := Migrate(connect.NewClient(source.URL()), connect.NewClient(target.URL()), Options{
+			Patterns:     []string{"prod-*"},
+			DeleteSource: true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(Equal([]Result{{Name: "prod-sink", TargetName: "prod-sink", Action: Moved}}))
+		Expect(source.ReceivedRequests()).To(HaveLen(4))
+	})
+
+	It("keeps the source connector while the target has no tasks running", func() {
+		target.SetHandler(1, ghttp.RespondWith(http.StatusOK,
+			`{"name":"prod-sink","connector":{"state":"RUNNING"},"tasks":[]}`))
+
+		_, err := Migrate(connect.NewClient(source.URL()), connect.NewClient(target.URL()), Options{
+			Patterns:     []string{"prod-*"},
+			WaitTimeout:  time.Millisecond,
+			DeleteSource: true,
+		})
+		Expect(err).To(MatchError("migrating prod-sink: not healthy on target: " + connect.ErrTimeout.Error()))
+		Expect(source.ReceivedRequests()).To(HaveLen(3))
+	})
+
+	It("moves a connector that runs no tasks on the source either", func() {
+		noTasks := ghttp.RespondWith(http.StatusOK, `{"name":"prod-sink","connector":{"state":"RUNNING"},"tasks":[]}`)
+		source.SetHandler(2, noTasks)
+		target.SetHandler(1, noTasks)
+
+		results, err := Migrate(connect.NewClient(source.URL()), connect.NewClient(target.URL()), Options{
+			Patterns:     []string{"prod-*"},
+			WaitTimeout:  time.Millisecond,
+			DeleteSource: true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(Equal([]Result{{Name: "prod-sink", TargetName: "prod-sink", Action: Moved}}))
+	})
+
+	It("fails when nothing matches", func() {
+		_, err := Migrate(connect.NewClient(source.URL()), connect.NewClient(target.URL()), Options{
+			Patterns: []string{"nope-*"},
+		})
+		Expect(err).To(MatchError("no connectors matched nope-*"))
+	})
+})
@@ -0,0 +1,111 @@
+package migrate
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-kafka/connect"
+)
+
+// A Syncer keeps connectors on a Target cluster in line with those on a
+// Source, applying the same selection, rename and rewrite rules as Migrate.
+// The WaitTimeout and DeleteSource options are ignored.
+type Syncer struct {
+	Source, Target *connect.Client
+	Options        Options
+
+	// Delete connectors from the target that this Syncer created or updated
+	// earlier, but which no longer exist or are no longer selected on the
+	// source. Connectors that the Syncer has never touched are left alone.
+	Prune bool
+
+	managed map[string]bool // Target connector names synced so far
+}
+
+// SyncOnce makes a single pass, creating or updating target connectors whose
+// config differs from what the source dictates.
+func (s *Syncer) SyncOnce() ([]Result, error) {
+	if s.managed == nil {
+		s.managed = make(map[string]bool)
+	}
+
+	names, err := selected(s.Source, &s.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []Result
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		result, err := s.syncOne(name)
+		if err != nil {
+			return results, fmt.Errorf("syncing %v: %v", name, err)
+		}
+		seen[result.TargetName] = true
+		s.managed[result.TargetName] = true
+		results = append(results, result)
+	}
+
+	if s.Prune {
+		for name := range s.managed {
+			if seen[name] {
+				continue
+			}
+			if _, err := s.Target.DeleteConnector(name); err != nil {
+				return results, fmt.Errorf("pruning %v: %v", name, err)
+			}
+			delete(s.managed, name)
+			results = append(results, Result{TargetName: name, Action: Pruned})
+		}
+	}
+
+	return results, nil
+}
+
+func (s *Syncer) syncOne(name string) (Result, error) {
+	result := Result{Name: name, TargetName: s.Options.TargetName(name)}
+
+	config, _, err := s.Source.GetConnectorConfig(name)
+	if err != nil {
+		return result, err
+	}
+	desired := s.Options.TargetConfig(config, result.TargetName)
+
+	current, resp, err := s.Target.GetConnectorConfig(result.TargetName)
+	if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+		return result, err
+	}
+	if err == nil && current.Hash() == desired.Hash() {
+		result.Action = Unchanged
+		return result, nil
+	}
+
+	conn := connect.Connector{Name: result.TargetName, Config: desired}
+	created, _, err := s.Target.UpsertConnector(&conn)
+	if err != nil {
+		return result, err
+	}
+	result.Action = Updated
+	if created {
+		result.Action = Created
+	}
+	return result, nil
+}
+
+// Run calls SyncOnce every interval until stop is closed, passing the outcome
+// of each pass to report. Errors do not end the loop, the next pass retries.
+func (s *Syncer) Run(interval time.Duration, stop <-chan struct{}, report func([]Result, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		report(s.SyncOnce())
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
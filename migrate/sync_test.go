@@ -0,0 +1,87 @@
+package migrate_test
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	"github.com/go-kafka/connect"
+	. "github.com/go-kafka/connect/migrate"
+)
+
+var _ = Describe("Syncer", func() {
+	var source, target *ghttp.Server
+	var syncer *Syncer
+
+	BeforeEach(func() {
+		source = ghttp.NewServer()
+		target = ghttp.NewServer()
+		syncer = &Syncer{
+			Source: connect.NewClient(source.URL()),
+			Target: connect.NewClient(target.URL()),
+			Prune:  true,
+		}
+
+		// First pass: a is unchanged, b is missing on target
+		source.AppendHandlers(
+			ghttp.RespondWith(http.StatusOK, `["a","b"]`),
+			ghttp.RespondWith(http.StatusOK, `{"name":"a","tasks.max":"1"}`),
+			ghttp.RespondWith(http.StatusOK, `{"name":"b","tasks.max":"1"}`),
+		)
+		target.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/connectors/a/config"),
+				ghttp.RespondWith(http.StatusOK, `{"tasks.max":"1","name":"a"}`),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/connectors/b/config"),
+				ghttp.RespondWith(http.StatusNotFound, `{"error_code":404,"message":"not found"}`),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("PUT", "/connectors/b/config"),
+				ghttp.RespondWith(http.StatusCreated, `{"name":"b"}`),
+			),
+		)
+	})
+
+	AfterEach(func() {
+		source.Close()
+		target.Close()
+	})
+
+	It("only writes connectors that differ", func() {
+		results, err := syncer.SyncOnce()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(Equal([]Result{
+			{Name: "a", TargetName: "a", Action: Unchanged},
+			{Name: "b", TargetName: "b", Action: Created},
+		}))
+	})
+
+	Context("when a synced connector disappears from the source", func() {
+		BeforeEach(func() {
+			source.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `["a"]`),
+				ghttp.RespondWith(http.StatusOK, `{"name":"a","tasks.max":"1"}`),
+			)
+			target.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `{"name":"a","tasks.max":"1"}`),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("DELETE", "/connectors/b"),
+					ghttp.RespondWith(http.StatusNoContent, nil),
+				),
+			)
+		})
+
+		It("prunes it from the target", func() {
+			_, err := syncer.SyncOnce()
+			Expect(err).NotTo(HaveOccurred())
+
+			results, err := syncer.SyncOnce()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(ContainElement(Result{TargetName: "b", Action: Pruned}))
+		})
+	})
+})
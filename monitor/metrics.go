@@ -0,0 +1,130 @@
+package monitor
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-kafka/connect"
+)
+
+// States is the states connectors and tasks may be in. Each is reported as a
+// gauge of 1 for the current state and 0 for the others, so that a missing
+// state reads as 0 rather than as no data.
+var States = []string{"RUNNING", "PAUSED", "STOPPED", "FAILED", "UNASSIGNED", "RESTARTING"}
+
+// Handler serves the latest snapshot of p as Prometheus metrics.
+func Handler(p *Poller) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = WriteMetrics(w, p.Snapshot())
+	})
+}
+
+// WriteMetrics writes a snapshot in the Prometheus text exposition format.
+func WriteMetrics(w io.Writer, s Snapshot) error {
+	m := &metricWriter{w: bufio.NewWriter(w)}
+
+	up := 0.0
+	if !s.Time.IsZero() && s.Err == nil {
+		up = 1
+	}
+	m.family("kafka_connect_up", "gauge", "Whether the latest poll of the Connect REST API succeeded.")
+	m.sample("kafka_connect_up", nil, up)
+	m.family("kafka_connect_poll_duration_seconds", "gauge", "How long the latest poll of the REST API took.")
+	m.sample("kafka_connect_poll_duration_seconds", nil, s.Duration.Seconds())
+	m.family("kafka_connect_polls_total", "counter", "Polls of the REST API.")
+	m.sample("kafka_connect_polls_total", nil, float64(s.Polls))
+	m.family("kafka_connect_api_errors_total", "counter", "Polls of the REST API that failed.")
+	m.sample("kafka_connect_api_errors_total", nil, float64(s.Errors))
+	if !s.LastSuccess.IsZero() {
+		m.family("kafka_connect_last_success_timestamp_seconds", "gauge", "When the latest successful poll finished.")
+		m.sample("kafka_connect_last_success_timestamp_seconds", nil, float64(s.LastSuccess.UnixNano())/1e9)
+	}
+
+	m.family("kafka_connect_connectors", "gauge", "Connectors on the cluster.")
+	m.sample("kafka_connect_connectors", nil, float64(len(s.Statuses)))
+
+	m.family("kafka_connect_connector_state", "gauge", "Connector state, 1 for the current state.")
+	for _, status := range s.Statuses {
+		labels := []string{"connector", status.Name, "type", status.Type, "worker", status.Connector.WorkerID}
+		m.states("kafka_connect_connector_state", labels, status.Connector.State)
+	}
+
+	m.family("kafka_connect_task_state", "gauge", "Task state, 1 for the current state.")
+	for _, status := range s.Statuses {
+		for _, task := range status.Tasks {
+			labels := []string{"connector", status.Name, "task", strconv.Itoa(task.ID),
+				"type", status.Type, "worker", task.WorkerID}
+			m.states("kafka_connect_task_state", labels, task.State)
+		}
+	}
+
+	loads := connect.WorkerLoads(s.Statuses)
+	m.family("kafka_connect_worker_connectors", "gauge", "Connector instances assigned to a worker.")
+	for _, load := range loads {
+		m.sample("kafka_connect_worker_connectors", []string{"worker", load.WorkerID}, float64(len(load.Connectors)))
+	}
+	m.family("kafka_connect_worker_tasks", "gauge", "Tasks assigned to a worker.")
+	for _, load := range loads {
+		m.sample("kafka_connect_worker_tasks", []string{"worker", load.WorkerID}, float64(len(load.Tasks)))
+	}
+
+	if m.err != nil {
+		return m.err
+	}
+	return m.w.Flush()
+}
+
+// Writes metrics, keeping the first error so that callers can check once.
+type metricWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (m *metricWriter) printf(format string, args ...interface{}) {
+	if m.err == nil {
+		_, m.err = fmt.Fprintf(m.w, format, args...)
+	}
+}
+
+func (m *metricWriter) family(name, kind, help string) {
+	m.printf("# HELP %v %v\n# TYPE %v %v\n", name, help, name, kind)
+}
+
+// Writes a sample with labels given as name, value pairs.
+func (m *metricWriter) sample(name string, labels []string, value float64) {
+	if len(labels) == 0 {
+		m.printf("%v %v\n", name, formatValue(value))
+		return
+	}
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i < len(labels); i += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%v="%v"`, labels[i], labelEscaper.Replace(labels[i+1])))
+	}
+	m.printf("%v{%v} %v\n", name, strings.Join(pairs, ","), formatValue(value))
+}
+
+func (m *metricWriter) states(name string, labels []string, current string) {
+	known := false
+	for _, state := range States {
+		value := 0.0
+		if state == current {
+			value, known = 1, true
+		}
+		m.sample(name, append(labels[:len(labels):len(labels)], "state", state), value)
+	}
+	if !known && current != "" {
+		m.sample(name, append(labels[:len(labels):len(labels)], "state", current), 1)
+	}
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// Label values escape backslashes, quotes and newlines.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
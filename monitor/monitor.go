@@ -0,0 +1,88 @@
+// Package monitor polls the status of a Connect cluster in the background and
+// keeps the latest snapshot, so that any number of consumers, such as the
+// Prometheus metrics handler, can read it without each hitting the workers.
+package monitor
+
+import (
+	"sync"
+	"time"
+
+	"github.com/go-kafka/connect"
+)
+
+// DefaultInterval is how often a Poller polls if no interval is given.
+const DefaultInterval = 15 * time.Second
+
+// A Snapshot is the outcome of the latest poll of the cluster.
+type Snapshot struct {
+	// Statuses of all connectors, from the latest successful poll.
+	Statuses []connect.ConnectorStatus
+
+	// When the latest poll finished, how long it took and, if it failed, why.
+	// Statuses are kept from the poll before a failed one.
+	Time     time.Time
+	Duration time.Duration
+	Err      error
+
+	// When the latest successful poll finished.
+	LastSuccess time.Time
+
+	// Counts of polls and failed polls since the Poller started.
+	Polls, Errors int
+}
+
+// A Poller polls a cluster's connector statuses periodically.
+type Poller struct {
+	Client *connect.Client
+
+	// How often to poll. If zero, DefaultInterval is used.
+	Interval time.Duration
+
+	mu       sync.RWMutex
+	snapshot Snapshot
+}
+
+// Run polls at once and then every Interval until stop is closed.
+func (p *Poller) Run(stop <-chan struct{}) {
+	interval := p.Interval
+	if interval == 0 {
+		interval = DefaultInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		p.Poll()
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll fetches connector statuses once and updates the snapshot.
+func (p *Poller) Poll() {
+	start := time.Now()
+	statuses, err := p.Client.ListConnectorStatuses()
+	end := time.Now()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := &p.snapshot
+	s.Time, s.Duration, s.Err = end, end.Sub(start), err
+	s.Polls++
+	if err != nil {
+		s.Errors++
+		return
+	}
+	s.Statuses, s.LastSuccess = statuses, end
+}
+
+// Snapshot returns the latest snapshot. Its Time is zero before the first
+// poll has finished.
+func (p *Poller) Snapshot() Snapshot {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.snapshot
+}
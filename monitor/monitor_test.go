@@ -0,0 +1,107 @@
+package monitor_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	"github.com/go-kafka/connect"
+	. "github.com/go-kafka/connect/monitor"
+)
+
+var _ = Describe("Poller", func() {
+	var server *ghttp.Server
+	var poller *Poller
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		server.RouteToHandler("GET", "/connectors", ghttp.RespondWith(http.StatusOK, `["a"]`))
+		server.RouteToHandler("GET", "/connectors/a/status", ghttp.RespondWith(http.StatusOK,
+			`{"name":"a","type":"sink","connector":{"state":"RUNNING","worker_id":"w1:8083"},
+			  "tasks":[{"id":0,"state":"RUNNING","worker_id":"w1:8083"},{"id":1,"state":"FAILED","worker_id":"w2:8083"}]}`))
+		poller = &Poller{Client: connect.NewClient(server.URL()), Interval: time.Millisecond}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("has an empty snapshot before polling", func() {
+		Expect(poller.Snapshot().Time.IsZero()).To(BeTrue())
+	})
+
+	It("keeps the statuses of the latest successful poll", func() {
+		poller.Poll()
+		server.RouteToHandler("GET", "/connectors", ghttp.RespondWith(http.StatusInternalServerError, `{}`))
+		poller.Poll()
+
+		s := poller.Snapshot()
+		Expect(s.Err).To(HaveOccurred())
+		Expect(s.Polls).To(Equal(2))
+		Expect(s.Errors).To(Equal(1))
+		Expect(s.Statuses).To(HaveLen(1))
+		Expect(s.LastSuccess).To(BeTemporally("<", s.Time))
+	})
+
+	It("polls until stopped", func() {
+		stop := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			poller.Run(stop)
+			close(done)
+		}()
+		Eventually(func() int { return poller.Snapshot().Polls }).Should(BeNumerically(">=", 2))
+		close(stop)
+		Eventually(done).Should(BeClosed())
+	})
+
+	Describe("metrics", func() {
+		scrape := func() string {
+			recorder := httptest.NewRecorder()
+			Handler(poller).ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+			Expect(recorder.Header().Get("Content-Type")).To(HavePrefix("text/plain; version=0.0.4"))
+			return recorder.Body.String()
+		}
+
+		It("reports down before the first poll", func() {
+			Expect(scrape()).To(ContainSubstring("\nkafka_connect_up 0\n"))
+		})
+
+		It("serves state gauges, worker counts and poll metrics", func() {
+			poller.Poll()
+			metrics := scrape()
+			Expect(metrics).To(ContainSubstring("# TYPE kafka_connect_up gauge\nkafka_connect_up 1\n"))
+			Expect(metrics).To(ContainSubstring("kafka_connect_polls_total 1\n"))
+			Expect(metrics).To(ContainSubstring("kafka_connect_api_errors_total 0\n"))
+			Expect(metrics).To(MatchRegexp(`kafka_connect_poll_duration_seconds [0-9.e-]+\n`))
+			Expect(metrics).To(ContainSubstring(
+				`kafka_connect_connector_state{connector="a",type="sink",worker="w1:8083",state="RUNNING"} 1` + "\n"))
+			Expect(metrics).To(ContainSubstring(
+				`kafka_connect_connector_state{connector="a",type="sink",worker="w1:8083",state="FAILED"} 0` + "\n"))
+			Expect(metrics).To(ContainSubstring(
+				`kafka_connect_task_state{connector="a",task="1",type="sink",worker="w2:8083",state="FAILED"} 1` + "\n"))
+			Expect(metrics).To(ContainSubstring(`kafka_connect_worker_tasks{worker="w1:8083"} 1` + "\n"))
+			Expect(metrics).To(ContainSubstring(`kafka_connect_worker_connectors{worker="w2:8083"} 0` + "\n"))
+		})
+	})
+})
+
+var _ = Describe("WriteMetrics", func() {
+	It("escapes label values and reports unknown states", func() {
+		var buf bytes.Buffer
+		Expect(WriteMetrics(&buf, Snapshot{
+			Time: time.Now(),
+			Statuses: []connect.ConnectorStatus{{
+				Name:      `odd "name"\`,
+				Connector: connect.ConnectorState{State: "DEGRADED"},
+			}},
+		})).To(Succeed())
+		Expect(buf.String()).To(ContainSubstring(
+			`kafka_connect_connector_state{connector="odd \"name\"\\",type="",worker="",state="DEGRADED"} 1`))
+	})
+})
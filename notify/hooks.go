@@ -0,0 +1,152 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"text/template"
+	"time"
+)
+
+// Defaults for a Webhook's settings left zero.
+const (
+	DefaultBackoff = time.Second
+	DefaultTimeout = 10 * time.Second
+)
+
+// NewTemplate parses a template for webhook bodies. Besides the usual
+// functions, json quotes a value as JSON, for building JSON bodies safely:
+//
+//	{"text": {{printf "%v: %v" .Subject .Type | json}}}
+func NewTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+	}).Parse(text)
+}
+
+// A Webhook POSTs events as JSON to a URL.
+type Webhook struct {
+	URL string
+
+	// Renders the request body from an Event. Without one, the body is the
+	// event itself as JSON.
+	Template *template.Template
+
+	// Extra request headers, such as for authentication.
+	Header http.Header
+
+	// Times to retry after a network error, a 429 or a 5xx response, waiting
+	// Backoff before the first retry and twice as long before each after it.
+	Retries int
+	Backoff time.Duration
+
+	// Used for requests if set, otherwise a client with DefaultTimeout.
+	Client *http.Client
+}
+
+// Notify sends an event, retrying as needed.
+func (w *Webhook) Notify(e Event) error {
+	var body bytes.Buffer
+	if w.Template != nil {
+		if err := w.Template.Execute(&body, e); err != nil {
+			return fmt.Errorf("rendering webhook body: %v", err)
+		}
+	} else if err := json.NewEncoder(&body).Encode(e); err != nil {
+		return err
+	}
+
+	client := w.Client
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+	backoff := w.Backoff
+	if backoff == 0 {
+		backoff = DefaultBackoff
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		var retry bool
+		if retry, err = w.post(client, body.Bytes()); err == nil || !retry || attempt >= w.Retries {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// Makes one attempt, reporting whether a failure is worth retrying.
+func (w *Webhook) post(client *http.Client, body []byte) (retry bool, err error) {
+	req, err := http.NewRequest("POST", w.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	for key, values := range w.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	response, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, response.Body)
+
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return false, nil
+	}
+	err = fmt.Errorf("webhook %v responded %v", w.URL, response.Status)
+	return response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500, err
+}
+
+// A Command runs a shell command for each event, with the event as JSON on
+// standard input and its fields in KAFKA_CONNECT_EVENT_* environment variables:
+// TYPE, CONNECTOR, TASK, FROM, TO, WORKER, CAUSE, MESSAGE, TIME and SUPPRESSED.
+type Command struct {
+	Command string
+
+	// Where the command's output goes, discarded if nil.
+	Output io.Writer
+}
+
+// Notify runs the command, failing if it exits with an error.
+func (c *Command) Notify(e Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	var task string
+	if e.Task != nil {
+		task = strconv.Itoa(*e.Task)
+	}
+	cmd := exec.Command("/bin/sh", "-c", c.Command)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout, cmd.Stderr = c.Output, c.Output
+	cmd.Env = append(os.Environ(),
+		"KAFKA_CONNECT_EVENT_TYPE="+e.Type,
+		"KAFKA_CONNECT_EVENT_CONNECTOR="+e.Connector,
+		"KAFKA_CONNECT_EVENT_TASK="+task,
+		"KAFKA_CONNECT_EVENT_FROM="+e.From,
+		"KAFKA_CONNECT_EVENT_TO="+e.To,
+		"KAFKA_CONNECT_EVENT_WORKER="+e.Worker,
+		"KAFKA_CONNECT_EVENT_CAUSE="+e.Cause,
+		"KAFKA_CONNECT_EVENT_MESSAGE="+e.Message,
+		"KAFKA_CONNECT_EVENT_TIME="+e.Time.Format(time.RFC3339),
+		"KAFKA_CONNECT_EVENT_SUPPRESSED="+strconv.Itoa(e.Suppressed),
+	)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("notify command: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package notify_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/go-kafka/connect/notify"
+)
+
+var _ = Describe("Webhook", func() {
+	var server *ghttp.Server
+	e := Event{
+		Time:      time.Date(2026, 10, 18, 3, 0, 0, 0, time.UTC),
+		Type:      "failed",
+		Connector: "a",
+		Task:      task(0),
+		To:        "FAILED",
+		Message:   `task a/0 is FAILED: relation "orders" does not exist`,
+	}
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("posts the event as JSON", func() {
+		server.AppendHandlers(ghttp.CombineHandlers(
+			ghttp.VerifyRequest("POST", "/hook"),
+			ghttp.VerifyHeaderKV("Authorization", "Bearer s3cret"),
+			ghttp.VerifyJSON(`{"time":"2026-10-18T03:00:00Z","type":"failed","connector":"a","task":0,"to":"FAILED",
+				"message":"task a/0 is FAILED: relation \"orders\" does not exist"}`),
+			ghttp.RespondWith(http.StatusOK, nil),
+		))
+
+		hook := &Webhook{URL: server.URL() + "/hook", Header: http.Header{"Authorization": {"Bearer s3cret"}}}
+		Expect(hook.Notify(e)).To(Succeed())
+		Expect(server.ReceivedRequests()).To(HaveLen(1))
+	})
+
+	It("renders a template", func() {
+		server.AppendHandlers(ghttp.CombineHandlers(
+			ghttp.VerifyJSON(`{"text":"task a/0: task a/0 is FAILED: relation \"orders\" does not exist"}`),
+			ghttp.RespondWith(http.StatusOK, nil),
+		))
+
+		tmpl, err := NewTemplate("slack", `{"text": {{printf "%v: %v" .Subject .Message | json}}}`)
+		Expect(err).NotTo(HaveOccurred())
+		hook := &Webhook{URL: server.URL(), Template: tmpl}
+		Expect(hook.Notify(e)).To(Succeed())
+	})
+
+	It("retries server errors with backoff", func() {
+		server.AppendHandlers(
+			ghttp.RespondWith(http.StatusServiceUnavailable, nil),
+			ghttp.RespondWith(http.StatusTooManyRequests, nil),
+			ghttp.RespondWith(http.StatusNoContent, nil),
+		)
+
+		hook := &Webhook{URL: server.URL(), Retries: 2, Backoff: time.Millisecond}
+		Expect(hook.Notify(e)).To(Succeed())
+		Expect(server.ReceivedRequests()).To(HaveLen(3))
+	})
+
+	It("gives up after its retries", func() {
+		server.AllowUnhandledRequests = true
+		server.UnhandledRequestStatusCode = http.StatusBadGateway
+
+		hook := &Webhook{URL: server.URL(), Retries: 1, Backoff: time.Millisecond}
+		Expect(hook.Notify(e)).To(MatchError(ContainSubstring("responded 502 Bad Gateway")))
+		Expect(server.ReceivedRequests()).To(HaveLen(2))
+	})
+
+	It("doesn't retry client errors", func() {
+		server.AppendHandlers(ghttp.RespondWith(http.StatusBadRequest, nil))
+
+		hook := &Webhook{URL: server.URL(), Retries: 3, Backoff: time.Millisecond}
+		Expect(hook.Notify(e)).To(MatchError(ContainSubstring("responded 400 Bad Request")))
+		Expect(server.ReceivedRequests()).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("Command", func() {
+	var tmpdir string
+
+	BeforeEach(func() {
+		tmpdir, _ = ioutil.TempDir("", "notify")
+	})
+
+	AfterEach(func() {
+		_ = os.RemoveAll(tmpdir)
+	})
+
+	It("passes the event in the environment and on stdin", func() {
+		out := filepath.Join(tmpdir, "out")
+		cmd := &Command{Command: `echo "$KAFKA_CONNECT_EVENT_TYPE $KAFKA_CONNECT_EVENT_CONNECTOR/$KAFKA_CONNECT_EVENT_TASK" > ` + out + `; cat >> ` + out}
+		Expect(cmd.Notify(Event{Type: "failed", Connector: "a", Task: task(3), Message: "boom"})).To(Succeed())
+
+		data, err := ioutil.ReadFile(out)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(HavePrefix("failed a/3\n{"))
+		Expect(string(data)).To(ContainSubstring(`"message":"boom"`))
+	})
+
+	It("fails when the command does", func() {
+		Expect((&Command{Command: "exit 3"}).Notify(Event{})).To(MatchError("notify command: exit status 3"))
+	})
+})
@@ -0,0 +1,261 @@
+// Package notify tells other systems about connector state changes, such as a
+// chat relay or a paging service. Events come from comparing successive status
+// snapshots with Diff, or from a heal.Healer with FromHeal. A Dispatcher sends
+// them to webhooks or local commands, filtering them and holding back repeats
+// so that a flapping task doesn't flood a channel.
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kafka/connect"
+	"github.com/go-kafka/connect/heal"
+	"github.com/go-kafka/connect/trace"
+)
+
+// Event types that aren't a state. Other types are the new state of a connector
+// or task in lower case, such as failed or running, or a heal action.
+const (
+	// A connector that was seen before is gone.
+	TypeDeleted = "deleted"
+)
+
+// An Event is a change worth telling someone about.
+type Event struct {
+	Time time.Time `json:"time"`
+	Type string    `json:"type"`
+
+	// The connector, and the task if the event is about one.
+	Connector string `json:"connector,omitempty"`
+	Task      *int   `json:"task,omitempty"`
+
+	// States before and after a state change. From is empty for a connector
+	// or task not seen before.
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+
+	Worker string `json:"worker,omitempty"`
+
+	// Root cause of a failure.
+	Cause string `json:"cause,omitempty"`
+
+	// A sentence describing the event for people.
+	Message string `json:"message"`
+
+	// Events about the same connector held back since the last one sent.
+	Suppressed int `json:"suppressed,omitempty"`
+}
+
+func (e Event) String() string {
+	return e.Message
+}
+
+// Subject names what the event is about, e.g. "connector orders" or "task
+// orders/0".
+func (e Event) Subject() string {
+	if e.Task != nil {
+		return fmt.Sprintf("task %v/%d", e.Connector, *e.Task)
+	}
+	return "connector " + e.Connector
+}
+
+// Diff returns events for each connector and task whose state differs between
+// two snapshots of statuses, and for connectors that are gone.
+func Diff(previous, current []connect.ConnectorStatus, now time.Time) []Event {
+	before := make(map[string]*connect.ConnectorStatus, len(previous))
+	for i := range previous {
+		before[previous[i].Name] = &previous[i]
+	}
+
+	var events []Event
+	for _, status := range current {
+		old := before[status.Name]
+		delete(before, status.Name)
+
+		var from string
+		if old != nil {
+			from = old.Connector.State
+		}
+		if state := status.Connector; state.State != from {
+			events = append(events, stateChange(now, status.Name, nil, from, state.State, state.WorkerID, state.Trace))
+		}
+
+		oldTasks := make(map[int]string)
+		if old != nil {
+			for _, task := range old.Tasks {
+				oldTasks[task.ID] = task.State
+			}
+		}
+		for _, task := range status.Tasks {
+			if from := oldTasks[task.ID]; task.State != from {
+				id := task.ID
+				events = append(events, stateChange(now, status.Name, &id, from, task.State, task.WorkerID, task.Trace))
+			}
+		}
+	}
+
+	// Whatever is left was deleted, reported in a stable order
+	for _, status := range previous {
+		if before[status.Name] != nil {
+			events = append(events, Event{
+				Time:      now,
+				Type:      TypeDeleted,
+				Connector: status.Name,
+				From:      status.Connector.State,
+				Message:   fmt.Sprintf("connector %v was deleted", status.Name),
+			})
+		}
+	}
+	return events
+}
+
+func stateChange(now time.Time, name string, task *int, from, to, worker, stackTrace string) Event {
+	e := Event{Time: now, Type: strings.ToLower(to), Connector: name, Task: task, From: from, To: to, Worker: worker}
+	if to == "FAILED" && stackTrace != "" {
+		e.Cause = trace.Summarize(stackTrace)
+	}
+
+	e.Message = fmt.Sprintf("%v is %v", e.Subject(), to)
+	if from != "" {
+		e.Message += fmt.Sprintf(" (was %v)", from)
+	}
+	if e.Cause != "" {
+		e.Message += ": " + e.Cause
+	}
+	return e
+}
+
+// FromHeal converts an event of a heal.Healer, taking its action as the type.
+func FromHeal(e heal.Event) Event {
+	n := Event{Time: e.Time, Type: e.Action, Cause: e.Cause, Message: e.String()}
+	if e.Action != heal.ActionPollFailed {
+		id := e.Task.ID
+		n.Connector, n.Task = e.Task.ConnectorName, &id
+	}
+	return n
+}
+
+// A Notifier delivers events somewhere.
+type Notifier interface {
+	Notify(Event) error
+}
+
+// A Filter picks the events worth sending. The zero Filter passes all events.
+type Filter struct {
+	// Only name criteria apply, as events don't carry configs. Events about
+	// no connector in particular, such as failed polls, always pass.
+	Connectors connect.Selector
+
+	// Event types to pass, such as failed or give-up.
+	Types []string
+}
+
+// Matches reports whether an event passes the filter.
+func (f Filter) Matches(e Event) bool {
+	if e.Connector != "" && !f.Connectors.MatchesName(e.Connector) {
+		return false
+	}
+	if len(f.Types) == 0 {
+		return true
+	}
+	for _, t := range f.Types {
+		if strings.EqualFold(t, e.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// A Dispatcher sends events that pass its filter to each of its notifiers,
+// holding back repeats. It is safe for concurrent use.
+type Dispatcher struct {
+	Notifiers []Notifier
+	Filter    Filter
+
+	// Events of the same type about the same connector or task with the same
+	// cause are sent once per Dedupe, if set.
+	Dedupe time.Duration
+
+	// At most RateLimit events about a connector are sent per RatePeriod, if
+	// set. Events held back are counted in the next one sent.
+	RateLimit  int
+	RatePeriod time.Duration
+
+	mu         sync.Mutex
+	lastSent   map[string]time.Time
+	windows    map[string]*rateWindow
+	suppressed map[string]int
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// Send delivers an event unless it is filtered or held back, as of the event's
+// time. Every notifier is tried, and their errors are returned together.
+func (d *Dispatcher) Send(e Event) error {
+	if !d.Filter.Matches(e) || !d.admit(&e) {
+		return nil
+	}
+
+	var failures []string
+	for _, n := range d.Notifiers {
+		if err := n.Notify(e); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("notifying of %v: %v", e.Subject(), strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// Decides whether to send an event, counting it as held back if not and
+// otherwise setting the count of those held back before it.
+func (d *Dispatcher) admit(e *Event) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.lastSent == nil {
+		d.lastSent = make(map[string]time.Time)
+		d.windows = make(map[string]*rateWindow)
+		d.suppressed = make(map[string]int)
+	}
+
+	var key string
+	if d.Dedupe > 0 {
+		for k, sent := range d.lastSent {
+			if e.Time.Sub(sent) >= d.Dedupe {
+				delete(d.lastSent, k)
+			}
+		}
+		key = fmt.Sprintf("%v\x00%v\x00%v", e.Type, e.Subject(), trace.Normalize(e.Cause))
+		if _, ok := d.lastSent[key]; ok {
+			d.suppressed[e.Connector]++
+			return false
+		}
+	}
+
+	if d.RateLimit > 0 {
+		w := d.windows[e.Connector]
+		if w == nil || e.Time.Sub(w.start) >= d.RatePeriod {
+			w = &rateWindow{start: e.Time}
+			d.windows[e.Connector] = w
+		}
+		if w.count >= d.RateLimit {
+			d.suppressed[e.Connector]++
+			return false
+		}
+		w.count++
+	}
+
+	if key != "" {
+		d.lastSent[key] = e.Time
+	}
+	e.Suppressed = d.suppressed[e.Connector]
+	delete(d.suppressed, e.Connector)
+	return true
+}
@@ -0,0 +1,177 @@
+package notify_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-kafka/connect"
+	"github.com/go-kafka/connect/heal"
+	. "github.com/go-kafka/connect/notify"
+)
+
+func task(id int) *int {
+	return &id
+}
+
+var _ = Describe("Diff", func() {
+	now := time.Date(2026, 10, 18, 3, 0, 0, 0, time.UTC)
+
+	previous := []connect.ConnectorStatus{
+		{
+			Name:      "a",
+			Connector: connect.ConnectorState{State: "RUNNING", WorkerID: "w1"},
+			Tasks: []connect.TaskState{
+				{ID: 0, State: "RUNNING", WorkerID: "w1"},
+				{ID: 1, State: "RUNNING", WorkerID: "w2"},
+			},
+		},
+		{Name: "b", Connector: connect.ConnectorState{State: "PAUSED", WorkerID: "w2"}},
+	}
+
+	It("reports nothing without changes", func() {
+		Expect(Diff(previous, previous, now)).To(BeEmpty())
+	})
+
+	It("reports state changes, new connectors and deletions", func() {
+		current := []connect.ConnectorStatus{
+			{
+				Name:      "a",
+				Connector: connect.ConnectorState{State: "RUNNING", WorkerID: "w1"},
+				Tasks: []connect.TaskState{
+					{ID: 0, State: "RUNNING", WorkerID: "w1"},
+					{ID: 1, State: "FAILED", WorkerID: "w2",
+						Trace: "org.apache.kafka.connect.errors.ConnectException: boom\nCaused by: java.net.ConnectException: Connection refused"},
+				},
+			},
+			{Name: "c", Connector: connect.ConnectorState{State: "RUNNING", WorkerID: "w1"}},
+		}
+
+		Expect(Diff(previous, current, now)).To(Equal([]Event{
+			{
+				Time: now, Type: "failed", Connector: "a", Task: task(1), From: "RUNNING", To: "FAILED", Worker: "w2",
+				Cause:   "java.net.ConnectException: Connection refused",
+				Message: "task a/1 is FAILED (was RUNNING): java.net.ConnectException: Connection refused",
+			},
+			{
+				Time: now, Type: "running", Connector: "c", To: "RUNNING", Worker: "w1",
+				Message: "connector c is RUNNING",
+			},
+			{
+				Time: now, Type: TypeDeleted, Connector: "b", From: "PAUSED",
+				Message: "connector b was deleted",
+			},
+		}))
+	})
+})
+
+var _ = Describe("FromHeal", func() {
+	It("takes the action as the type", func() {
+		e := FromHeal(heal.Event{
+			Action:  heal.ActionGiveUp,
+			Task:    connect.TaskID{ConnectorName: "a", ID: 2},
+			Attempt: 3,
+			Cause:   "java.lang.OutOfMemoryError",
+		})
+		Expect(e.Type).To(Equal("give-up"))
+		Expect(e.Subject()).To(Equal("task a/2"))
+		Expect(e.Message).To(Equal("gave up on task a/2 after 3 attempts: java.lang.OutOfMemoryError"))
+	})
+
+	It("has no connector for failed polls", func() {
+		e := FromHeal(heal.Event{Action: heal.ActionPollFailed, Error: "connection refused"})
+		Expect(e.Connector).To(BeEmpty())
+		Expect(e.Task).To(BeNil())
+	})
+})
+
+var _ = Describe("Filter", func() {
+	e := Event{Type: "failed", Connector: "orders-sink", Task: task(0)}
+
+	It("passes everything when empty", func() {
+		Expect(Filter{}.Matches(e)).To(BeTrue())
+	})
+
+	It("matches connector names and types", func() {
+		Expect(Filter{Connectors: connect.Selector{Names: []string{"orders-*"}}}.Matches(e)).To(BeTrue())
+		Expect(Filter{Connectors: connect.Selector{Names: []string{"payments-*"}}}.Matches(e)).To(BeFalse())
+		Expect(Filter{Types: []string{"running", "FAILED"}}.Matches(e)).To(BeTrue())
+		Expect(Filter{Types: []string{"give-up"}}.Matches(e)).To(BeFalse())
+	})
+
+	It("passes events without a connector through name criteria", func() {
+		pollFailed := Event{Type: "poll-failed"}
+		Expect(Filter{Connectors: connect.Selector{Names: []string{"orders-*"}}}.Matches(pollFailed)).To(BeTrue())
+	})
+})
+
+type recorder struct {
+	events []Event
+	err    error
+}
+
+func (r *recorder) Notify(e Event) error {
+	r.events = append(r.events, e)
+	return r.err
+}
+
+var _ = Describe("Dispatcher", func() {
+	var rec *recorder
+	var d *Dispatcher
+	start := time.Date(2026, 10, 18, 3, 0, 0, 0, time.UTC)
+
+	event := func(after time.Duration, connector, typ string) Event {
+		return Event{Time: start.Add(after), Type: typ, Connector: connector, Task: task(0), Cause: "offset 12 out of range"}
+	}
+
+	BeforeEach(func() {
+		rec = &recorder{}
+		d = &Dispatcher{Notifiers: []Notifier{rec}}
+	})
+
+	It("drops filtered events", func() {
+		d.Filter.Types = []string{"failed"}
+		Expect(d.Send(event(0, "a", "running"))).To(Succeed())
+		Expect(rec.events).To(BeEmpty())
+	})
+
+	It("holds back repeats within the dedupe window", func() {
+		d.Dedupe = 10 * time.Minute
+		Expect(d.Send(event(0, "a", "failed"))).To(Succeed())
+		Expect(d.Send(event(time.Minute, "a", "running"))).To(Succeed())
+
+		repeat := event(2*time.Minute, "a", "failed")
+		repeat.Cause = "offset 47 out of range"
+		Expect(d.Send(repeat)).To(Succeed())
+		Expect(d.Send(event(3*time.Minute, "b", "failed"))).To(Succeed())
+		Expect(rec.events).To(HaveLen(3))
+
+		Expect(d.Send(event(11*time.Minute, "a", "failed"))).To(Succeed())
+		Expect(rec.events).To(HaveLen(4))
+		Expect(rec.events[3].Suppressed).To(Equal(1))
+	})
+
+	It("limits the rate of events per connector", func() {
+		d.RateLimit, d.RatePeriod = 2, time.Hour
+		for i, typ := range []string{"failed", "running", "failed", "running"} {
+			Expect(d.Send(event(time.Duration(i)*time.Minute, "a", typ))).To(Succeed())
+		}
+		Expect(d.Send(event(5*time.Minute, "b", "failed"))).To(Succeed())
+		Expect(rec.events).To(HaveLen(3))
+
+		Expect(d.Send(event(time.Hour, "a", "failed"))).To(Succeed())
+		Expect(rec.events).To(HaveLen(4))
+		Expect(rec.events[3].Suppressed).To(Equal(2))
+	})
+
+	It("tries every notifier and reports failures", func() {
+		other := &recorder{}
+		rec.err = errors.New("relay down")
+		d.Notifiers = append(d.Notifiers, other)
+
+		Expect(d.Send(event(0, "a", "failed"))).To(MatchError("notifying of task a/0: relay down"))
+		Expect(other.events).To(HaveLen(1))
+	})
+})
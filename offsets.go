@@ -0,0 +1,41 @@
+package connect
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// ConnectorOffsets holds the offsets a connector has committed, one entry per
+// source partition or, for sink connectors, per Kafka topic partition.
+type ConnectorOffsets struct {
+	Offsets []ConnectorOffset `json:"offsets"`
+}
+
+// A ConnectorOffset pairs a partition with the offset committed for it. Their
+// shape is defined by the connector plugin for source connectors, and has
+// kafka_topic, kafka_partition and kafka_offset keys for sink connectors.
+type ConnectorOffset struct {
+	Partition map[string]interface{} `json:"partition"`
+	Offset    map[string]interface{} `json:"offset"`
+}
+
+// GetConnectorOffsets retrieves the committed offsets of a connector. Requires
+// Kafka 3.5 or later.
+//
+// See: https://docs.confluent.io/current/connect/references/restapi.html#get--connectors-(string-name)-offsets
+func (c *Client) GetConnectorOffsets(name string) (*ConnectorOffsets, *http.Response, error) {
+	path := fmt.Sprintf("connectors/%v/offsets", name)
+	offsets := new(ConnectorOffsets)
+	response, err := c.get(path, offsets)
+	return offsets, response, err
+}
+
+// AlterConnectorOffsets sets committed offsets of a connector for the given
+// partitions, leaving others as they are. The connector must be STOPPED.
+// Requires Kafka 3.6 or later.
+//
+// See: https://docs.confluent.io/current/connect/references/restapi.html#patch--connectors-(string-name)-offsets
+func (c *Client) AlterConnectorOffsets(name string, offsets *ConnectorOffsets) (*http.Response, error) {
+	path := fmt.Sprintf("connectors/%v/offsets", name)
+	return c.doRequest("PATCH", path, offsets, nil)
+}
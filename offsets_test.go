@@ -0,0 +1,68 @@
+package connect_test
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/go-kafka/connect"
+)
+
+var _ = Describe("Connector Offsets", func() {
+	sinkOffsets := &ConnectorOffsets{
+		Offsets: []ConnectorOffset{
+			{
+				Partition: map[string]interface{}{"kafka_topic": "orders", "kafka_partition": float64(0)},
+				Offset:    map[string]interface{}{"kafka_offset": float64(42)},
+			},
+		},
+	}
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		client = NewClient(server.URL())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("GetConnectorOffsets", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/connectors/orders-sink/offsets"),
+					ghttp.VerifyHeader(jsonAcceptHeader),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, sinkOffsets),
+				),
+			)
+		})
+
+		It("returns committed offsets", func() {
+			offsets, _, err := client.GetConnectorOffsets("orders-sink")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(offsets).To(Equal(sinkOffsets))
+		})
+	})
+
+	Describe("AlterConnectorOffsets", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PATCH", "/connectors/orders-sink/offsets"),
+					ghttp.VerifyHeader(jsonContentHeader),
+					ghttp.VerifyJSONRepresenting(sinkOffsets),
+					ghttp.RespondWith(http.StatusOK, `{"message":"The offsets for this connector have been altered successfully"}`),
+				),
+			)
+		})
+
+		It("sends offsets to the connector", func() {
+			resp, err := client.AlterConnectorOffsets("orders-sink", sinkOffsets)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		})
+	})
+})
@@ -0,0 +1,71 @@
+package connect
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// A ConfigValidation is the result of validating a connector configuration
+// against its plugin's definition of valid settings.
+type ConfigValidation struct {
+	Name       string       `json:"name"`
+	ErrorCount int          `json:"error_count"`
+	Groups     []string     `json:"groups"`
+	Configs    []ConfigInfo `json:"configs"`
+}
+
+// ConfigInfo pairs the definition of a config setting with its validated value.
+type ConfigInfo struct {
+	Definition ConfigDefinition `json:"definition"`
+	Value      ConfigValue      `json:"value"`
+}
+
+// ConfigDefinition describes a config setting that a plugin accepts.
+type ConfigDefinition struct {
+	Name          string   `json:"name"`
+	Type          string   `json:"type"`
+	Required      bool     `json:"required"`
+	DefaultValue  string   `json:"default_value"`
+	Importance    string   `json:"importance"`
+	Documentation string   `json:"documentation"`
+	Group         string   `json:"group"`
+	Width         string   `json:"width"`
+	DisplayName   string   `json:"display_name"`
+	Dependents    []string `json:"dependents"`
+	Order         int      `json:"order"`
+}
+
+// ConfigValue is the outcome of validating one config setting.
+type ConfigValue struct {
+	Name              string   `json:"name"`
+	Value             string   `json:"value"`
+	RecommendedValues []string `json:"recommended_values"`
+	Errors            []string `json:"errors"`
+	Visible           bool     `json:"visible"`
+}
+
+// Errors returns validation error messages keyed by config setting name, for
+// settings that have any.
+func (v *ConfigValidation) Errors() map[string][]string {
+	errors := make(map[string][]string)
+	for _, config := range v.Configs {
+		if len(config.Value.Errors) > 0 {
+			errors[config.Value.Name] = config.Value.Errors
+		}
+	}
+	return errors
+}
+
+// ValidateConnectorConfig validates config against the definition of the
+// connector plugin with the given class name, e.g. FileStreamSinkConnector or
+// its fully-qualified name. A successful response may still report errors in
+// the config, see ConfigValidation.ErrorCount.
+//
+// See: https://docs.confluent.io/current/connect/references/restapi.html#put--connector-plugins-(string-name)-config-validate
+func (c *Client) ValidateConnectorConfig(class string, config ConnectorConfig) (*ConfigValidation, *http.Response, error) {
+	path := fmt.Sprintf("connector-plugins/%v/config/validate", url.PathEscape(class))
+	validation := new(ConfigValidation)
+	response, err := c.doRequest("PUT", path, config, validation)
+	return validation, response, err
+}
@@ -0,0 +1,62 @@
+package connect_test
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/go-kafka/connect"
+)
+
+var _ = Describe("Connector Plugins", func() {
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		client = NewClient(server.URL())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("ValidateConnectorConfig", func() {
+		config := ConnectorConfig{
+			"connector.class": "FileStreamSinkConnector",
+			"tasks.max":       "1",
+		}
+
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/connector-plugins/FileStreamSinkConnector/config/validate"),
+					ghttp.VerifyHeader(jsonContentHeader),
+					ghttp.VerifyJSONRepresenting(config),
+					ghttp.RespondWith(http.StatusOK, `{
+						"name": "FileStreamSinkConnector",
+						"error_count": 1,
+						"groups": ["Common"],
+						"configs": [
+							{"definition": {"name": "tasks.max", "type": "INT", "required": false},
+							 "value": {"name": "tasks.max", "value": "1", "errors": [], "visible": true}},
+							{"definition": {"name": "topics", "type": "LIST", "required": true},
+							 "value": {"name": "topics", "value": null,
+							           "errors": ["Must configure one of topics or topics.regex"], "visible": true}}
+						]
+					}`),
+				),
+			)
+		})
+
+		It("returns validation results", func() {
+			validation, _, err := client.ValidateConnectorConfig("FileStreamSinkConnector", config)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(validation.ErrorCount).To(Equal(1))
+			Expect(validation.Configs).To(HaveLen(2))
+			Expect(validation.Configs[1].Definition.Required).To(BeTrue())
+			Expect(validation.Errors()).To(Equal(map[string][]string{
+				"topics": {"Must configure one of topics or topics.regex"},
+			}))
+		})
+	})
+})
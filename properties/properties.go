@@ -0,0 +1,228 @@
+// Package properties converts between connector configurations and the Java
+// .properties format, as used for connectors in Kafka Connect standalone mode.
+//
+// Parsing follows java.util.Properties.load: comments start with # or !, keys
+// are separated from values by =, : or whitespace, backslash escapes and \uXXXX
+// sequences are decoded, and a line ending in an odd number of backslashes
+// continues on the next line.
+//
+// See: https://docs.oracle.com/javase/8/docs/api/java/util/Properties.html#load-java.io.Reader-
+package properties
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/go-kafka/connect"
+)
+
+// Parse reads properties text from r into a connector config. When a key is
+// repeated the last value wins, as in Java.
+func Parse(r io.Reader) (connect.ConnectorConfig, error) {
+	config := make(connect.ConnectorConfig)
+	scanner := bufio.NewScanner(r)
+
+	var logical strings.Builder
+	lineno, start := 0, 0
+	for scanner.Scan() {
+		lineno++
+		line := scanner.Text()
+		if logical.Len() == 0 {
+			start = lineno
+			line = strings.TrimLeft(line, " \t\f")
+			if line == "" || line[0] == '#' || line[0] == '!' {
+				continue
+			}
+		} else {
+			// Leading whitespace of continuation lines is discarded
+			line = strings.TrimLeft(line, " \t\f")
+		}
+
+		if continues(line) {
+			logical.WriteString(line[:len(line)-1])
+			continue
+		}
+		logical.WriteString(line)
+
+		key, value, err := parseLine(logical.String())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", start, err)
+		}
+		config[key] = value
+		logical.Reset()
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// A continuation at end of input is tolerated, as Java does
+	if logical.Len() > 0 {
+		key, value, err := parseLine(logical.String())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", start, err)
+		}
+		config[key] = value
+	}
+
+	return config, nil
+}
+
+// Unmarshal parses properties text into a connector config.
+func Unmarshal(data []byte) (connect.ConnectorConfig, error) {
+	return Parse(bytes.NewReader(data))
+}
+
+// Reports whether a line ends with an unescaped backslash.
+func continues(line string) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+func parseLine(line string) (key, value string, err error) {
+	// Find the end of the key: the first unescaped separator
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		if c == '\\' {
+			i++
+			continue
+		}
+		if c == '=' || c == ':' || c == ' ' || c == '\t' || c == '\f' {
+			end = i
+			break
+		}
+	}
+
+	// Skip whitespace around the separator, and at most one = or :
+	rest := strings.TrimLeft(line[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+
+	if key, err = unescape(line[:end]); err != nil {
+		return
+	}
+	value, err = unescape(rest)
+	return
+}
+
+func unescape(s string) (string, error) {
+	if !strings.Contains(s, "\\") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	var units []uint16 // Pending UTF-16 code units from \u escapes
+	flush := func() {
+		if len(units) > 0 {
+			b.WriteString(string(utf16.Decode(units)))
+			units = units[:0]
+		}
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			flush()
+			b.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch c := s[i]; c {
+		case 'u':
+			if i+5 > len(s) {
+				return "", fmt.Errorf("malformed \\uxxxx encoding in %q", s)
+			}
+			n, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("malformed \\uxxxx encoding in %q", s)
+			}
+			units = append(units, uint16(n))
+			i += 4
+		case 't':
+			flush()
+			b.WriteByte('\t')
+		case 'n':
+			flush()
+			b.WriteByte('\n')
+		case 'r':
+			flush()
+			b.WriteByte('\r')
+		case 'f':
+			flush()
+			b.WriteByte('\f')
+		default:
+			// Any other escaped character stands for itself
+			flush()
+			b.WriteByte(c)
+		}
+	}
+	flush()
+
+	return b.String(), nil
+}
+
+// Format writes config to w as properties text, one key per line sorted by key.
+// Characters outside printable ASCII are written as \uXXXX escapes, so the
+// output is safe to load as ISO-8859-1, as Java does by default.
+func Format(w io.Writer, config connect.ConnectorConfig) error {
+	keys := make([]string, 0, len(config))
+	for k := range config {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if _, err := fmt.Fprintf(w, "%s=%s\n", escape(k, true), escape(config[k], false)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Marshal returns config as properties text.
+func Marshal(config connect.ConnectorConfig) ([]byte, error) {
+	var buf bytes.Buffer
+	err := Format(&buf, config)
+	return buf.Bytes(), err
+}
+
+func escape(s string, isKey bool) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\f':
+			b.WriteString(`\f`)
+		case r == ' ' && (isKey || i == 0):
+			// Spaces end keys, and leading spaces of values are trimmed
+			b.WriteString(`\ `)
+		case isKey && (r == '=' || r == ':' || ((r == '#' || r == '!') && i == 0)):
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			for _, unit := range utf16.Encode([]rune{r}) {
+				fmt.Fprintf(&b, `\u%04X`, unit)
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,84 @@
+package properties_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-kafka/connect"
+	. "github.com/go-kafka/connect/properties"
+)
+
+var _ = Describe("Unmarshal", func() {
+	It("parses a standalone connector file", func() {
+		config, err := Unmarshal([]byte(`# A comment
+! Another comment
+name=local-file-source
+connector.class = FileStreamSource
+tasks.max: 1
+file /tmp/test.txt
+
+topic=connect-test
+`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config).To(Equal(connect.ConnectorConfig{
+			"name":            "local-file-source",
+			"connector.class": "FileStreamSource",
+			"tasks.max":       "1",
+			"file":            "/tmp/test.txt",
+			"topic":           "connect-test",
+		}))
+	})
+
+	It("joins continuation lines", func() {
+		config, err := Unmarshal([]byte("transforms=a,\\\n    b,\\\n    c\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config["transforms"]).To(Equal("a,b,c"))
+	})
+
+	It("does not treat an escaped trailing backslash as a continuation", func() {
+		config, err := Unmarshal([]byte("path=C:\\\\\nnext=1\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config).To(Equal(connect.ConnectorConfig{"path": `C:\`, "next": "1"}))
+	})
+
+	It("decodes escapes", func() {
+		config, err := Unmarshal([]byte(`key\ with\=odd\:chars=tab\there caf\u00e9 \uD83D\uDE00` + "\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config).To(Equal(connect.ConnectorConfig{"key with=odd:chars": "tab\there café 😀"}))
+	})
+
+	It("rejects malformed unicode escapes", func() {
+		_, err := Unmarshal([]byte("a=\\u12\n"))
+		Expect(err).To(MatchError(ContainSubstring("line 1: malformed")))
+	})
+
+	It("allows empty values", func() {
+		config, err := Unmarshal([]byte("empty=\nbare\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config).To(Equal(connect.ConnectorConfig{"empty": "", "bare": ""}))
+	})
+})
+
+var _ = Describe("Marshal", func() {
+	It("writes sorted properties", func() {
+		data, err := Marshal(connect.ConnectorConfig{"topic": "test", "name": "a"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal("name=a\ntopic=test\n"))
+	})
+
+	It("round-trips awkward keys and values", func() {
+		config := connect.ConnectorConfig{
+			"key with=odd:chars": "  leading spaces",
+			"#not-a-comment":     "multi\nline\\value",
+			"url":                "jdbc:mysql://db:3306/x?a=b",
+			"unicode":            "café 😀",
+		}
+		data, err := Marshal(config)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring("url=jdbc:mysql://db:3306/x?a=b\n"))
+
+		parsed, err := Unmarshal(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parsed).To(Equal(config))
+	})
+})
@@ -0,0 +1,211 @@
+package connect
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DefaultRenameTimeout is how long RenameConnector waits for each state change
+// when no timeout is given.
+const DefaultRenameTimeout = time.Minute
+
+// RenameOptions controls how RenameConnector moves a connector to a new name.
+type RenameOptions struct {
+	// Config values to set on the new connector, overriding the original's.
+	Config ConnectorConfig
+
+	// Don't try to copy offsets, e.g. for workers that have the offsets API
+	// but can't create connectors in the STOPPED state, which needs Kafka 3.7.
+	SkipOffsets bool
+
+	// How long to wait for each state change, such as the new connector
+	// running. If zero, DefaultRenameTimeout is used.
+	Timeout time.Duration
+
+	// How often to poll status while waiting. If zero, DefaultPollInterval is
+	// used.
+	Interval time.Duration
+}
+
+// A RenameResult describes the connector created by a rename.
+type RenameResult struct {
+	Connector *Connector
+
+	// Whether offsets were carried over. If not, the new connector starts from
+	// scratch as a brand new connector would.
+	OffsetsCopied bool
+}
+
+// CloneConnector creates a connector named dst with the config of connector
+// src, with any values in overrides set on top. A "name" key in the config is
+// changed to match dst. The clone starts without any of the original's offsets.
+func (c *Client) CloneConnector(src, dst string, overrides ConnectorConfig) (*Connector, *http.Response, error) {
+	config, response, err := c.GetConnectorConfig(src)
+	if err != nil {
+		return nil, response, err
+	}
+
+	conn := &Connector{Name: dst, Config: cloneConfig(config, dst, overrides)}
+	response, err = c.CreateConnector(conn)
+	return conn, response, err
+}
+
+func cloneConfig(config ConnectorConfig, name string, overrides ConnectorConfig) ConnectorConfig {
+	cloned := config.Patch(overrides, nil)
+	if _, ok := cloned["name"]; ok {
+		cloned["name"] = name
+	}
+	return cloned
+}
+
+// RenameConnector moves a connector to a new name, which Kafka Connect has no
+// API for. The original is stopped, its offsets are copied when the worker
+// supports the offsets API, the new connector is created and brought to the
+// original's state, and then the original is deleted.
+//
+// If any step fails, the steps taken so far are undone: the new connector is
+// deleted and the original is returned to its prior state. Workers before
+// Kafka 3.5 cannot stop connectors, so the original is paused instead and
+// offsets are not copied.
+func (c *Client) RenameConnector(oldName, newName string, opts RenameOptions) (result *RenameResult, err error) {
+	if opts.Timeout == 0 {
+		opts.Timeout = DefaultRenameTimeout
+	}
+
+	config, _, err := c.GetConnectorConfig(oldName)
+	if err != nil {
+		return nil, err
+	}
+	status, _, err := c.GetConnectorStatus(oldName)
+	if err != nil {
+		return nil, err
+	}
+	priorState := status.Connector.State
+
+	_, response, err := c.GetConnector(newName)
+	if err == nil {
+		return nil, fmt.Errorf("connector %v already exists", newName)
+	}
+	if response == nil || response.StatusCode != http.StatusNotFound {
+		return nil, err
+	}
+
+	var undo []func() error
+	defer func() {
+		if err == nil {
+			return
+		}
+		for i := len(undo) - 1; i >= 0; i-- {
+			if undoErr := undo[i](); undoErr != nil {
+				err = fmt.Errorf("%v; rolling back failed: %v", err, undoErr)
+				return
+			}
+		}
+	}()
+
+	undo = append(undo, func() error {
+		return c.setConnectorState(oldName, priorState, opts)
+	})
+	stopped, err := c.haltConnector(oldName, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var offsets *ConnectorOffsets
+	if stopped && !opts.SkipOffsets {
+		offsets, response, err = c.GetConnectorOffsets(oldName)
+		if err != nil && !isUnsupported(response) {
+			return nil, err
+		}
+		if err != nil {
+			offsets = nil
+		}
+	}
+
+	conn := &Connector{Name: newName, Config: cloneConfig(config, newName, opts.Config)}
+	if offsets != nil {
+		_, err = c.createStoppedConnector(conn)
+	} else {
+		_, err = c.CreateConnector(conn)
+	}
+	if err != nil {
+		return nil, err
+	}
+	undo = append(undo, func() error {
+		_, err := c.DeleteConnector(newName)
+		return err
+	})
+
+	if offsets != nil && len(offsets.Offsets) > 0 {
+		if _, err = c.AlterConnectorOffsets(newName, offsets); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = c.setConnectorState(newName, priorState, opts); err != nil {
+		return nil, err
+	}
+
+	if _, err = c.DeleteConnector(oldName); err != nil {
+		return nil, err
+	}
+
+	return &RenameResult{Connector: conn, OffsetsCopied: offsets != nil}, nil
+}
+
+// Stops a connector, or pauses it on workers without the stop API, and waits
+// until it has done so. Reports whether the connector was stopped.
+func (c *Client) haltConnector(name string, opts RenameOptions) (stopped bool, err error) {
+	state := "STOPPED"
+	response, err := c.StopConnector(name)
+	if err != nil && isUnsupported(response) {
+		state = "PAUSED"
+		_, err = c.PauseConnector(name)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	_, err = c.WaitForConnector(name, inState(state), opts.Timeout, opts.Interval)
+	return state == "STOPPED", err
+}
+
+// Brings a connector to the given state, resuming it for any state other than
+// PAUSED or STOPPED, and waits until it gets there.
+func (c *Client) setConnectorState(name, state string, opts RenameOptions) error {
+	if _, err := c.SetConnectorState(name, state); err != nil {
+		return err
+	}
+
+	cond := inState(state)
+	if state != "PAUSED" && state != "STOPPED" {
+		cond = (*ConnectorStatus).IsRunning
+	}
+
+	_, err := c.WaitForConnector(name, cond, opts.Timeout, opts.Interval)
+	return err
+}
+
+// Creates a connector that doesn't start until resumed, so that its offsets
+// can be set first. Requires Kafka 3.7 or later.
+func (c *Client) createStoppedConnector(conn *Connector) (*http.Response, error) {
+	request := struct {
+		*Connector
+		InitialState string `json:"initial_state"`
+	}{conn, "STOPPED"}
+	return c.doRequest("POST", "connectors", request, conn)
+}
+
+func inState(state string) func(*ConnectorStatus) bool {
+	return func(s *ConnectorStatus) bool {
+		return s.Connector.State == state
+	}
+}
+
+// Workers answer requests for API endpoints they are too old to have with a
+// 404 or 405.
+func isUnsupported(response *http.Response) bool {
+	return response != nil &&
+		(response.StatusCode == http.StatusNotFound || response.StatusCode == http.StatusMethodNotAllowed)
+}
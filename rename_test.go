@@ -0,0 +1,205 @@
+package connect_test
+
+import (
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/go-kafka/connect"
+)
+
+var _ = Describe("Cloning and renaming", func() {
+	config := ConnectorConfig{
+		"name":            "orders-sink",
+		"connector.class": "JdbcSinkConnector",
+		"tasks.max":       "2",
+		"topics":          "orders",
+	}
+	renamedConfig := ConnectorConfig{
+		"name":            "orders-jdbc-sink",
+		"connector.class": "JdbcSinkConnector",
+		"tasks.max":       "2",
+		"topics":          "orders",
+	}
+	offsets := &ConnectorOffsets{
+		Offsets: []ConnectorOffset{
+			{
+				Partition: map[string]interface{}{"kafka_topic": "orders", "kafka_partition": float64(0)},
+				Offset:    map[string]interface{}{"kafka_offset": float64(42)},
+			},
+		},
+	}
+	// Poll once per wait, so that each status check is a single handler
+	opts := RenameOptions{Timeout: time.Millisecond, Interval: time.Millisecond}
+
+	status := func(name, state string) http.HandlerFunc {
+		return ghttp.CombineHandlers(
+			ghttp.VerifyRequest("GET", "/connectors/"+name+"/status"),
+			ghttp.RespondWithJSONEncoded(http.StatusOK, ConnectorStatus{
+				Name:      name,
+				Connector: ConnectorState{State: state},
+			}),
+		)
+	}
+	respond := func(method, path string, code int) http.HandlerFunc {
+		return ghttp.CombineHandlers(
+			ghttp.VerifyRequest(method, path),
+			ghttp.RespondWith(code, nil),
+		)
+	}
+	notFound := func(method, path string) http.HandlerFunc {
+		return ghttp.CombineHandlers(
+			ghttp.VerifyRequest(method, path),
+			ghttp.RespondWith(http.StatusNotFound, `{"error_code":404,"message":"HTTP 404 Not Found"}`),
+		)
+	}
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		client = NewClient(server.URL())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("CloneConnector", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.RespondWithJSONEncoded(http.StatusOK, config),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/connectors"),
+					ghttp.VerifyJSONRepresenting(Connector{
+						Name:   "orders-jdbc-sink",
+						Config: renamedConfig.Patch(ConnectorConfig{"tasks.max": "4"}, nil),
+					}),
+					ghttp.RespondWithJSONEncoded(http.StatusCreated, Connector{Name: "orders-jdbc-sink"}),
+				),
+			)
+		})
+
+		It("creates a connector with the original's config and overrides", func() {
+			conn, _, err := client.CloneConnector("orders-sink", "orders-jdbc-sink", ConnectorConfig{"tasks.max": "4"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(conn.Name).To(Equal("orders-jdbc-sink"))
+			Expect(server.ReceivedRequests()).To(HaveLen(2))
+		})
+	})
+
+	Describe("RenameConnector", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.RespondWithJSONEncoded(http.StatusOK, config),
+				status("orders-sink", "RUNNING"),
+				notFound("GET", "/connectors/orders-jdbc-sink"),
+			)
+		})
+
+		Context("when the worker supports offsets", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(
+					respond("PUT", "/connectors/orders-sink/stop", http.StatusAccepted),
+					status("orders-sink", "STOPPED"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, offsets),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("POST", "/connectors"),
+						ghttp.VerifyJSON(`{
+							"name": "orders-jdbc-sink",
+							"config": {
+								"name": "orders-jdbc-sink",
+								"connector.class": "JdbcSinkConnector",
+								"tasks.max": "2",
+								"topics": "orders"
+							},
+							"initial_state": "STOPPED"
+						}`),
+						ghttp.RespondWithJSONEncoded(http.StatusCreated, Connector{Name: "orders-jdbc-sink", Config: renamedConfig}),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PATCH", "/connectors/orders-jdbc-sink/offsets"),
+						ghttp.VerifyJSONRepresenting(offsets),
+					),
+					respond("PUT", "/connectors/orders-jdbc-sink/resume", http.StatusAccepted),
+				)
+			})
+
+			Context("and the new connector runs", func() {
+				BeforeEach(func() {
+					server.AppendHandlers(
+						status("orders-jdbc-sink", "RUNNING"),
+						respond("DELETE", "/connectors/orders-sink", http.StatusNoContent),
+					)
+				})
+
+				It("moves the connector with its offsets", func() {
+					result, err := client.RenameConnector("orders-sink", "orders-jdbc-sink", opts)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result.OffsetsCopied).To(BeTrue())
+					Expect(result.Connector.Name).To(Equal("orders-jdbc-sink"))
+					Expect(server.ReceivedRequests()).To(HaveLen(11))
+				})
+			})
+
+			Context("and the new connector fails", func() {
+				BeforeEach(func() {
+					server.AppendHandlers(
+						status("orders-jdbc-sink", "FAILED"),
+						respond("DELETE", "/connectors/orders-jdbc-sink", http.StatusNoContent),
+						respond("PUT", "/connectors/orders-sink/resume", http.StatusAccepted),
+						status("orders-sink", "RUNNING"),
+					)
+				})
+
+				It("deletes it and resumes the original", func() {
+					_, err := client.RenameConnector("orders-sink", "orders-jdbc-sink", opts)
+					Expect(err).To(Equal(ErrTimeout))
+					Expect(server.ReceivedRequests()).To(HaveLen(13))
+				})
+			})
+		})
+
+		Context("when the worker cannot stop connectors", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(
+					notFound("PUT", "/connectors/orders-sink/stop"),
+					respond("PUT", "/connectors/orders-sink/pause", http.StatusAccepted),
+					status("orders-sink", "PAUSED"),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("POST", "/connectors"),
+						ghttp.VerifyJSONRepresenting(Connector{Name: "orders-jdbc-sink", Config: renamedConfig}),
+						ghttp.RespondWithJSONEncoded(http.StatusCreated, Connector{Name: "orders-jdbc-sink", Config: renamedConfig}),
+					),
+					respond("PUT", "/connectors/orders-jdbc-sink/resume", http.StatusAccepted),
+					status("orders-jdbc-sink", "RUNNING"),
+					respond("DELETE", "/connectors/orders-sink", http.StatusNoContent),
+				)
+			})
+
+			It("pauses the original and moves the connector without offsets", func() {
+				result, err := client.RenameConnector("orders-sink", "orders-jdbc-sink", opts)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.OffsetsCopied).To(BeFalse())
+				Expect(server.ReceivedRequests()).To(HaveLen(10))
+			})
+		})
+	})
+
+	Context("when the new name is taken", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.RespondWithJSONEncoded(http.StatusOK, config),
+				status("orders-sink", "RUNNING"),
+				ghttp.RespondWithJSONEncoded(http.StatusOK, Connector{Name: "orders-jdbc-sink"}),
+			)
+		})
+
+		It("returns an error without changing anything", func() {
+			_, err := client.RenameConnector("orders-sink", "orders-jdbc-sink", opts)
+			Expect(err).To(MatchError("connector orders-jdbc-sink already exists"))
+			Expect(server.ReceivedRequests()).To(HaveLen(3))
+		})
+	})
+})
@@ -0,0 +1,206 @@
+// Package rollout restarts many connectors gradually, in batches gated on the
+// health of the previous batch, so that a cluster-wide restart doesn't swamp
+// downstream systems. Progress can be saved so that an interrupted rollout
+// resumes where it left off.
+package rollout
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-kafka/connect"
+)
+
+// ErrTooManyFailures is returned when a rollout stops because the share of
+// connectors failing to come back healthy exceeded the allowed rate.
+var ErrTooManyFailures = errors.New("rollout stopped, too many connectors failed to recover")
+
+// Marks connectors left alone because they are paused or stopped.
+var errSkipped = errors.New("skipped")
+
+// Progress records which connectors a rollout covers and how far it has got.
+type Progress struct {
+	Connectors []string `json:"connectors"`
+	Restarted  []string `json:"restarted"`
+	Failed     []string `json:"failed"`
+	Skipped    []string `json:"skipped,omitempty"`
+}
+
+// NewProgress starts tracking a rollout over the named connectors.
+func NewProgress(names []string) *Progress {
+	return &Progress{Connectors: names}
+}
+
+// Remaining returns the connectors that are yet to be restarted successfully,
+// including those that failed, but not those skipped.
+func (p *Progress) Remaining() []string {
+	done := make(map[string]bool, len(p.Restarted)+len(p.Skipped))
+	for _, name := range p.Restarted {
+		done[name] = true
+	}
+	for _, name := range p.Skipped {
+		done[name] = true
+	}
+
+	var remaining []string
+	for _, name := range p.Connectors {
+		if !done[name] {
+			remaining = append(remaining, name)
+		}
+	}
+	return remaining
+}
+
+// LoadProgress reads progress saved by Save.
+func LoadProgress(path string) (*Progress, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	progress := new(Progress)
+	if err := json.Unmarshal(data, progress); err != nil {
+		return nil, err
+	}
+	return progress, nil
+}
+
+// Save writes progress to path, replacing the file atomically so that it is
+// intact even if the process is killed while writing.
+func (p *Progress) Save(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// A Result is the outcome of restarting one connector. Err is set if the
+// restart failed or the connector didn't become healthy in time, and Skipped
+// if the connector was paused or stopped and so was not restarted.
+type Result struct {
+	Name    string
+	Err     error
+	Skipped bool
+}
+
+// A Restarter restarts connectors and their tasks in batches, waiting after
+// each batch until its connectors are RUNNING before starting the next.
+// Paused and stopped connectors are skipped, as restarting them would not
+// bring them back to RUNNING.
+type Restarter struct {
+	Client *connect.Client
+
+	// How many connectors to restart at once, at least 1.
+	BatchSize int
+
+	// How long to wait for a batch to be RUNNING before counting the
+	// stragglers as failed, and how often to check. If Interval is zero,
+	// connect.DefaultPollInterval is used.
+	Timeout, Interval time.Duration
+
+	// The share of connectors, from 0 to 1, that may fail before the rollout
+	// stops. Zero stops at the first failure.
+	MaxFailureRate float64
+
+	// Called after each batch with its results, and with the progress so far,
+	// e.g. to save it. An error from Checkpoint stops the rollout.
+	Report     func([]Result)
+	Checkpoint func(*Progress) error
+}
+
+// Run restarts the remaining connectors in progress, updating it as batches
+// complete. Connectors that failed in an earlier, interrupted run are retried.
+// Returns ErrTooManyFailures if the failure rate goes above MaxFailureRate.
+func (r *Restarter) Run(progress *Progress) error {
+	batchSize := r.BatchSize
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	remaining := progress.Remaining()
+	progress.Failed = nil
+
+	for len(remaining) > 0 {
+		n := batchSize
+		if n > len(remaining) {
+			n = len(remaining)
+		}
+		batch := remaining[:n]
+		remaining = remaining[n:]
+
+		results := r.restartBatch(batch)
+		for _, result := range results {
+			if result.Skipped {
+				progress.Skipped = append(progress.Skipped, result.Name)
+			} else if result.Err != nil {
+				progress.Failed = append(progress.Failed, result.Name)
+			} else {
+				progress.Restarted = append(progress.Restarted, result.Name)
+			}
+		}
+
+		if r.Report != nil {
+			r.Report(results)
+		}
+		if r.Checkpoint != nil {
+			if err := r.Checkpoint(progress); err != nil {
+				return err
+			}
+		}
+
+		attempted := float64(len(progress.Failed) + len(progress.Restarted))
+		if len(progress.Failed) > 0 && float64(len(progress.Failed))/attempted > r.MaxFailureRate {
+			return ErrTooManyFailures
+		}
+	}
+
+	return nil
+}
+
+func (r *Restarter) restartBatch(batch []string) []Result {
+	restart := func(name string) (*http.Response, error) {
+		status, response, err := r.Client.GetConnectorStatus(name)
+		if err != nil {
+			return response, err
+		}
+		if state := status.Connector.State; state == "PAUSED" || state == "STOPPED" {
+			return nil, errSkipped
+		}
+
+		if response, err := r.Client.RestartConnectorAndTasks(name, false); err != nil {
+			return response, err
+		}
+		_, err = r.Client.WaitForConnector(name, (*connect.ConnectorStatus).IsRunning, r.Timeout, r.Interval)
+		return nil, err
+	}
+
+	bulk := connect.ForEachConnector(batch, len(batch), restart)
+	results := make([]Result, len(bulk))
+	for i, result := range bulk {
+		if result.Err == errSkipped {
+			results[i] = Result{Name: result.Name, Skipped: true}
+		} else {
+			results[i] = Result{Name: result.Name, Err: result.Err}
+		}
+	}
+	return results
+}
@@ -0,0 +1,126 @@
+package rollout_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	"github.com/go-kafka/connect"
+	. "github.com/go-kafka/connect/rollout"
+)
+
+var _ = Describe("Progress", func() {
+	It("lists connectors not yet restarted, including failures", func() {
+		progress := &Progress{
+			Connectors: []string{"a", "b", "c", "d"},
+			Restarted:  []string{"a", "c"},
+			Failed:     []string{"b"},
+		}
+		Expect(progress.Remaining()).To(Equal([]string{"b", "d"}))
+	})
+
+	It("saves and loads", func() {
+		dir, err := ioutil.TempDir("", "rollout")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "progress.json")
+		progress := &Progress{Connectors: []string{"a", "b"}, Restarted: []string{"a"}}
+		Expect(progress.Save(path)).To(Succeed())
+
+		loaded, err := LoadProgress(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded).To(Equal(progress))
+	})
+})
+
+var _ = Describe("Restarter", func() {
+	var server *ghttp.Server
+	var restarter *Restarter
+	var checkpoints [][]string
+
+	running := `{"name":"x","connector":{"state":"RUNNING"},"tasks":[{"id":0,"state":"RUNNING"}]}`
+	failed := `{"name":"x","connector":{"state":"RUNNING"},"tasks":[{"id":0,"state":"FAILED"}]}`
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		checkpoints = nil
+		restarter = &Restarter{
+			Client:    connect.NewClient(server.URL()),
+			BatchSize: 2,
+			Timeout:   time.Millisecond,
+			Interval:  time.Millisecond,
+			Checkpoint: func(p *Progress) error {
+				checkpoints = append(checkpoints, append([]string(nil), p.Restarted...))
+				return nil
+			},
+		}
+		for _, name := range []string{"a", "b", "c"} {
+			server.RouteToHandler("POST", "/connectors/"+name+"/restart", ghttp.CombineHandlers(
+				ghttp.VerifyRequest("POST", "/connectors/"+name+"/restart", "includeTasks=true&onlyFailed=false"),
+				ghttp.RespondWith(http.StatusAccepted, nil),
+			))
+			server.RouteToHandler("GET", "/connectors/"+name+"/status", ghttp.RespondWith(http.StatusOK, running))
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("restarts in batches, checkpointing after each", func() {
+		progress := NewProgress([]string{"a", "b", "c"})
+		Expect(restarter.Run(progress)).To(Succeed())
+		Expect(progress.Restarted).To(ConsistOf("a", "b", "c"))
+		Expect(checkpoints).To(HaveLen(2))
+		Expect(checkpoints[0]).To(ConsistOf("a", "b"))
+	})
+
+	It("skips connectors restarted by an earlier run", func() {
+		progress := &Progress{Connectors: []string{"a", "b", "c"}, Restarted: []string{"a", "b"}}
+		Expect(restarter.Run(progress)).To(Succeed())
+		Expect(server.ReceivedRequests()).To(HaveLen(3))
+	})
+
+	It("skips paused and stopped connectors", func() {
+		server.RouteToHandler("GET", "/connectors/b/status",
+			ghttp.RespondWith(http.StatusOK, `{"name":"b","connector":{"state":"PAUSED"},"tasks":[{"id":0,"state":"PAUSED"}]}`))
+		server.RouteToHandler("GET", "/connectors/c/status",
+			ghttp.RespondWith(http.StatusOK, `{"name":"c","connector":{"state":"STOPPED"},"tasks":[]}`))
+
+		progress := NewProgress([]string{"a", "b", "c"})
+		Expect(restarter.Run(progress)).To(Succeed())
+		Expect(progress.Restarted).To(Equal([]string{"a"}))
+		Expect(progress.Skipped).To(Equal([]string{"b", "c"}))
+		Expect(progress.Failed).To(BeEmpty())
+		Expect(progress.Remaining()).To(BeEmpty())
+	})
+
+	Context("when connectors don't recover", func() {
+		BeforeEach(func() {
+			restarter.BatchSize = 1
+			server.RouteToHandler("GET", "/connectors/a/status", ghttp.RespondWith(http.StatusOK, failed))
+		})
+
+		It("stops once failures exceed the allowed rate", func() {
+			progress := NewProgress([]string{"a", "b", "c"})
+			Expect(restarter.Run(progress)).To(Equal(ErrTooManyFailures))
+			Expect(progress.Failed).To(Equal([]string{"a"}))
+			Expect(progress.Remaining()).To(Equal([]string{"a", "b", "c"}))
+		})
+
+		It("carries on while failures are within the allowed rate", func() {
+			restarter.MaxFailureRate = 0.5
+			progress := NewProgress([]string{"b", "a", "c"})
+			Expect(restarter.Run(progress)).To(Succeed())
+			Expect(progress.Failed).To(Equal([]string{"a"}))
+			Expect(progress.Restarted).To(Equal([]string{"b", "c"}))
+		})
+	})
+})
@@ -0,0 +1,62 @@
+package connect
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// GetConnectorTopics retrieves the names of topics a connector has produced to
+// or consumed from since it was created or its topics were last reset.
+// Requires Kafka 2.5 or later.
+//
+// See: https://docs.confluent.io/current/connect/references/restapi.html#get--connectors-(string-name)-topics
+func (c *Client) GetConnectorTopics(name string) ([]string, *http.Response, error) {
+	path := fmt.Sprintf("connectors/%v/topics", name)
+	var result map[string]struct {
+		Topics []string `json:"topics"`
+	}
+	response, err := c.get(path, &result)
+	return result[name].Topics, response, err
+}
+
+// WaitForTasks polls the tasks of a connector with the given name until cond
+// returns true for them, or timeout has elapsed, in which case the last tasks
+// seen are returned along with ErrTimeout. Otherwise it behaves as
+// WaitForConnector.
+func (c *Client) WaitForTasks(name string, cond func([]Task) bool, timeout, interval time.Duration) ([]Task, error) {
+	if interval == 0 {
+		interval = DefaultPollInterval
+	}
+	deadline := time.Now().Add(timeout)
+
+	for {
+		tasks, _, err := c.GetConnectorTasks(name)
+		if err == nil && cond(tasks) {
+			return tasks, nil
+		}
+		if time.Now().Add(interval).After(deadline) {
+			if err != nil {
+				return tasks, err
+			}
+			return tasks, ErrTimeout
+		}
+		time.Sleep(interval)
+	}
+}
+
+// ScaleConnector sets tasks.max of a connector and waits until it has that
+// many tasks. A connector may run fewer tasks than its maximum, for instance a
+// source with fewer tables than tasks, in which case the tasks it does have
+// are returned along with ErrTimeout.
+func (c *Client) ScaleConnector(name string, tasks int, timeout, interval time.Duration) ([]Task, error) {
+	set := ConnectorConfig{"tasks.max": strconv.Itoa(tasks)}
+	if _, _, err := c.PatchConnectorConfig(name, set, nil); err != nil {
+		return nil, err
+	}
+
+	return c.WaitForTasks(name, func(current []Task) bool {
+		return len(current) == tasks
+	}, timeout, interval)
+}
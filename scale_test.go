@@ -0,0 +1,79 @@
+package connect_test
+
+import (
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/go-kafka/connect"
+)
+
+var _ = Describe("Scaling", func() {
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		client = NewClient(server.URL())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("GetConnectorTopics", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/connectors/orders-sink/topics"),
+					ghttp.RespondWith(http.StatusOK, `{"orders-sink":{"topics":["orders","refunds"]}}`),
+				),
+			)
+		})
+
+		It("returns active topics", func() {
+			topics, _, err := client.GetConnectorTopics("orders-sink")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(topics).To(Equal([]string{"orders", "refunds"}))
+		})
+	})
+
+	Describe("ScaleConnector", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusOK, `{"connector.class":"JdbcSinkConnector","tasks.max":"1"}`),
+				ghttp.RespondWith(http.StatusOK, `{"connector.class":"JdbcSinkConnector","tasks.max":"1"}`),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/connectors/orders-sink/config"),
+					ghttp.VerifyJSON(`{"connector.class":"JdbcSinkConnector","tasks.max":"2"}`),
+					ghttp.RespondWith(http.StatusOK, `{"name":"orders-sink"}`),
+				),
+				ghttp.RespondWith(http.StatusOK, `[{"id":{"connector":"orders-sink","task":0}}]`),
+			)
+		})
+
+		Context("when the new tasks show up", func() {
+			BeforeEach(func() {
+				server.AppendHandlers(ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/connectors/orders-sink/tasks"),
+					ghttp.RespondWith(http.StatusOK,
+						`[{"id":{"connector":"orders-sink","task":0}},{"id":{"connector":"orders-sink","task":1}}]`),
+				))
+			})
+
+			It("returns them", func() {
+				tasks, err := client.ScaleConnector("orders-sink", 2, time.Second, time.Millisecond)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(tasks).To(HaveLen(2))
+			})
+		})
+
+		Context("when the connector runs fewer tasks", func() {
+			It("times out with the tasks it has", func() {
+				tasks, err := client.ScaleConnector("orders-sink", 2, time.Millisecond, time.Millisecond)
+				Expect(err).To(Equal(ErrTimeout))
+				Expect(tasks).To(HaveLen(1))
+			})
+		})
+	})
+})
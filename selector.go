@@ -0,0 +1,174 @@
+package connect
+
+import (
+	"net/http"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// A Selector picks connectors by name, class, config or state, for acting on
+// many at once. A connector must match every criterion that is set, so the
+// zero Selector matches all connectors.
+type Selector struct {
+	// Glob patterns, as for path.Match, of which a name must match one.
+	Names []string
+
+	// A regular expression that names must match.
+	Regexp *regexp.Regexp
+
+	// The connector.class, either fully qualified or just the class name.
+	Class string
+
+	// Config values that must all be set as given.
+	Config map[string]string
+
+	// States such as FAILED, of which the connector or any of its tasks must
+	// be in one.
+	States []string
+}
+
+// IsEmpty reports whether the selector has no criteria, and so matches all
+// connectors.
+func (s Selector) IsEmpty() bool {
+	return len(s.Names) == 0 && s.Regexp == nil && s.Class == "" &&
+		len(s.Config) == 0 && len(s.States) == 0
+}
+
+// MatchesName reports whether a connector name passes the name criteria.
+func (s Selector) MatchesName(name string) bool {
+	if s.Regexp != nil && !s.Regexp.MatchString(name) {
+		return false
+	}
+	if len(s.Names) == 0 {
+		return true
+	}
+	for _, pattern := range s.Names {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches reports whether a connector passes all criteria. The config and
+// status may be nil if the selector has no criteria needing them.
+func (s Selector) Matches(name string, config ConnectorConfig, status *ConnectorStatus) bool {
+	if !s.MatchesName(name) {
+		return false
+	}
+
+	if s.Class != "" {
+		class := config["connector.class"]
+		if class != s.Class && class[strings.LastIndex(class, ".")+1:] != s.Class {
+			return false
+		}
+	}
+	for key, value := range s.Config {
+		if actual, ok := config[key]; !ok || actual != value {
+			return false
+		}
+	}
+
+	if len(s.States) == 0 {
+		return true
+	}
+	if status == nil {
+		return false
+	}
+	for _, state := range s.States {
+		if strings.EqualFold(status.Connector.State, state) {
+			return true
+		}
+		for _, task := range status.Tasks {
+			if strings.EqualFold(task.State, state) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SelectConnectors returns the names of active connectors matching sel, sorted.
+// Configs and statuses are only fetched if the selector needs them, and
+// connectors deleted while selecting are skipped.
+func (c *Client) SelectConnectors(sel Selector) ([]string, error) {
+	names, _, err := c.ListConnectors()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	var selected []string
+	for _, name := range names {
+		if !sel.MatchesName(name) {
+			continue
+		}
+
+		var config ConnectorConfig
+		var status *ConnectorStatus
+		var response *http.Response
+
+		if sel.Class != "" || len(sel.Config) > 0 {
+			config, response, err = c.GetConnectorConfig(name)
+			if response != nil && response.StatusCode == http.StatusNotFound {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		if len(sel.States) > 0 {
+			status, response, err = c.GetConnectorStatus(name)
+			if response != nil && response.StatusCode == http.StatusNotFound {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if sel.Matches(name, config, status) {
+			selected = append(selected, name)
+		}
+	}
+
+	return selected, nil
+}
+
+// A BulkResult is the outcome of an action on one of many connectors.
+type BulkResult struct {
+	Name string
+	Err  error
+}
+
+// ForEachConnector applies an action such as Client.PauseConnector to each
+// named connector, running at most concurrency actions at once. Results are in
+// the same order as names.
+func ForEachConnector(names []string, concurrency int, action func(name string) (*http.Response, error)) []BulkResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]BulkResult, len(names))
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, name := range names {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, name string) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			_, err := action(name)
+			results[i] = BulkResult{name, err}
+		}(i, name)
+	}
+
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,117 @@
+package connect_test
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/go-kafka/connect"
+)
+
+var _ = Describe("Selecting connectors", func() {
+	jdbcConfig := ConnectorConfig{
+		"connector.class": "io.confluent.connect.jdbc.JdbcSinkConnector",
+		"connection.url":  "jdbc:postgresql://db/orders",
+	}
+	failed := &ConnectorStatus{
+		Name:      "orders-sink",
+		Connector: ConnectorState{State: "RUNNING"},
+		Tasks:     []TaskState{{ID: 0, State: "RUNNING"}, {ID: 1, State: "FAILED"}},
+	}
+
+	Describe("Selector", func() {
+		It("matches everything when empty", func() {
+			sel := Selector{}
+			Expect(sel.IsEmpty()).To(BeTrue())
+			Expect(sel.Matches("anything", nil, nil)).To(BeTrue())
+		})
+
+		It("matches names by glob or regular expression", func() {
+			sel := Selector{Names: []string{"orders-*", "payments"}}
+			Expect(sel.MatchesName("orders-sink")).To(BeTrue())
+			Expect(sel.MatchesName("payments")).To(BeTrue())
+			Expect(sel.MatchesName("payments-sink")).To(BeFalse())
+
+			sel = Selector{Regexp: regexp.MustCompile(`-sink$`)}
+			Expect(sel.MatchesName("orders-sink")).To(BeTrue())
+			Expect(sel.MatchesName("orders-source")).To(BeFalse())
+		})
+
+		It("matches class by qualified or simple name", func() {
+			Expect((&Selector{Class: "JdbcSinkConnector"}).Matches("a", jdbcConfig, nil)).To(BeTrue())
+			Expect((&Selector{Class: "io.confluent.connect.jdbc.JdbcSinkConnector"}).Matches("a", jdbcConfig, nil)).To(BeTrue())
+			Expect((&Selector{Class: "SinkConnector"}).Matches("a", jdbcConfig, nil)).To(BeFalse())
+		})
+
+		It("requires all config values to match", func() {
+			sel := Selector{Config: map[string]string{"connection.url": "jdbc:postgresql://db/orders"}}
+			Expect(sel.Matches("a", jdbcConfig, nil)).To(BeTrue())
+			sel.Config["tasks.max"] = "1"
+			Expect(sel.Matches("a", jdbcConfig, nil)).To(BeFalse())
+		})
+
+		It("matches the state of the connector or any task", func() {
+			Expect((&Selector{States: []string{"failed"}}).Matches("orders-sink", nil, failed)).To(BeTrue())
+			Expect((&Selector{States: []string{"PAUSED"}}).Matches("orders-sink", nil, failed)).To(BeFalse())
+		})
+	})
+
+	Describe("SelectConnectors", func() {
+		BeforeEach(func() {
+			server = ghttp.NewServer()
+			client = NewClient(server.URL())
+
+			server.RouteToHandler("GET", "/connectors",
+				ghttp.RespondWithJSONEncoded(http.StatusOK, []string{"payments-sink", "orders-sink", "orders-source"}))
+			server.RouteToHandler("GET", "/connectors/orders-sink/status",
+				ghttp.RespondWithJSONEncoded(http.StatusOK, failed))
+			server.RouteToHandler("GET", "/connectors/orders-source/status",
+				ghttp.RespondWith(http.StatusNotFound, `{"error_code":404,"message":"not found"}`))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("fetches statuses only for connectors matching by name", func() {
+			names, err := client.SelectConnectors(Selector{Names: []string{"orders-*"}, States: []string{"FAILED"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).To(Equal([]string{"orders-sink"}))
+			Expect(server.ReceivedRequests()).To(HaveLen(3))
+		})
+	})
+
+	Describe("ForEachConnector", func() {
+		It("runs actions concurrently up to a limit, keeping results in order", func() {
+			var running, peak int32
+			action := func(name string) (*http.Response, error) {
+				n := atomic.AddInt32(&running, 1)
+				for {
+					p := atomic.LoadInt32(&peak)
+					if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				if name == "b" {
+					return nil, errors.New("boom")
+				}
+				return nil, nil
+			}
+
+			results := ForEachConnector([]string{"a", "b", "c", "d", "e"}, 2, action)
+			Expect(results).To(HaveLen(5))
+			Expect(results[0]).To(Equal(BulkResult{Name: "a"}))
+			Expect(results[1].Err).To(MatchError("boom"))
+			Expect(results[4].Name).To(Equal("e"))
+			Expect(atomic.LoadInt32(&peak)).To(BeNumerically("<=", 2))
+		})
+	})
+})
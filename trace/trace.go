@@ -0,0 +1,262 @@
+// Package trace parses Java stack traces, as found in the trace field of failed
+// connector and task statuses, into exception chains that can be summarized.
+//
+// Traces are expected in the format of Throwable.printStackTrace: a header line
+// of exception class and message, tab-indented "at" frames, "... n more" for
+// frames in common with the enclosing trace, and nested "Caused by:" and
+// "Suppressed:" sections.
+package trace
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MaxSummaryLength is the length beyond which Summary truncates messages.
+const MaxSummaryLength = 200
+
+// An Exception is one throwable in a trace, with its cause and any exceptions
+// suppressed by it.
+type Exception struct {
+	Class   string  `json:"class"`
+	Message string  `json:"message,omitempty"`
+	Frames  []Frame `json:"frames,omitempty"`
+
+	// Number of frames left out as in common with the enclosing trace.
+	Omitted int `json:"omitted,omitempty"`
+
+	Cause      *Exception   `json:"cause,omitempty"`
+	Suppressed []*Exception `json:"suppressed,omitempty"`
+}
+
+// A Frame is one method call in a stack trace.
+type Frame struct {
+	// Fully qualified method, possibly prefixed by a class loader or module,
+	// e.g. java.base/java.lang.Thread.run.
+	Method string `json:"method"`
+
+	// Source file and line, if known. Otherwise File is what the trace says,
+	// such as "Native Method" or "Unknown Source".
+	File string `json:"file,omitempty"`
+	Line int    `json:"line,omitempty"`
+}
+
+// Parse parses a stack trace. Text that doesn't start with an exception header
+// is an error, but anything unexpected after the header ends the trace.
+func Parse(s string) (*Exception, error) {
+	p := &parser{lines: strings.Split(strings.Replace(s, "\r\n", "\n", -1), "\n")}
+	for p.pos < len(p.lines) && strings.TrimSpace(p.lines[p.pos]) == "" {
+		p.pos++
+	}
+	if p.pos == len(p.lines) {
+		return nil, fmt.Errorf("empty stack trace")
+	}
+
+	header := strings.TrimSpace(p.lines[p.pos])
+	e, ok := parseHeader(header)
+	if !ok {
+		return nil, fmt.Errorf("not a stack trace: %q", header)
+	}
+	p.pos++
+	p.parseBody(e, 0)
+	return e, nil
+}
+
+// RootCause returns the innermost cause of the exception, which is usually
+// the most telling.
+func (e *Exception) RootCause() *Exception {
+	for e.Cause != nil {
+		e = e.Cause
+	}
+	return e
+}
+
+// Chain returns the exception followed by its causes, outermost first.
+func (e *Exception) Chain() []*Exception {
+	var chain []*Exception
+	for ; e != nil; e = e.Cause {
+		chain = append(chain, e)
+	}
+	return chain
+}
+
+// String returns the class and message as in a trace header.
+func (e *Exception) String() string {
+	if e.Message == "" {
+		return e.Class
+	}
+	return e.Class + ": " + e.Message
+}
+
+// Summary describes the root cause in a line: its class and the first line of
+// its message, truncated to MaxSummaryLength.
+func (e *Exception) Summary() string {
+	root := e.RootCause()
+	message := root.Message
+	if i := strings.IndexByte(message, '\n'); i >= 0 {
+		message = message[:i]
+	}
+	if len(message) > MaxSummaryLength {
+		message = message[:MaxSummaryLength] + "..."
+	}
+
+	if message == "" {
+		return root.Class
+	}
+	return root.Class + ": " + message
+}
+
+// Summarize returns the summary of a trace, or its first line if it can't be
+// parsed.
+func Summarize(s string) string {
+	if e, err := Parse(s); err == nil {
+		return e.Summary()
+	}
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+	return s
+}
+
+// Signature is the summary of the root cause with details that vary between
+// occurrences of the same failure masked by Normalize, so that failures with a
+// common cause can be grouped.
+func (e *Exception) Signature() string {
+	root := e.RootCause()
+	summary := root.Summary()
+	if !strings.HasPrefix(summary, root.Class+": ") {
+		return summary
+	}
+	return root.Class + ": " + Normalize(summary[len(root.Class)+2:])
+}
+
+// Signature returns the signature of a trace, or its normalized first line if
+// it can't be parsed.
+func Signature(s string) string {
+	if e, err := Parse(s); err == nil {
+		return e.Signature()
+	}
+	return Normalize(Summarize(s))
+}
+
+// Masks for Normalize, applied in order.
+var masks = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.-]*://)[^/\s:'"]+(:\d+)?`), "${1}<host>"},
+	{regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`), "<id>"},
+	{regexp.MustCompile(`\b\d{1,3}(\.\d{1,3}){3}(:\d+)?\b`), "<host>"},
+	{regexp.MustCompile(`\b[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?(\.[a-zA-Z0-9-]+)*:\d{2,5}\b`), "<host>"},
+	{regexp.MustCompile(`\b0x[0-9a-fA-F]+\b`), "<id>"},
+	{regexp.MustCompile(`\b\d+\b`), "<n>"},
+	{regexp.MustCompile(`\b[0-9a-fA-F]{8,}\b`), "<id>"},
+}
+
+// Normalize masks the parts of an exception message that tend to differ
+// between occurrences of the same failure: hosts and ports, UUIDs and other
+// hex IDs, and numbers such as offsets, partitions and task IDs.
+func Normalize(message string) string {
+	for _, mask := range masks {
+		message = mask.pattern.ReplaceAllString(message, mask.replacement)
+	}
+	return message
+}
+
+type parser struct {
+	lines []string
+	pos   int
+}
+
+// Parses frames, suppressed exceptions and the cause of e, whose header was
+// indented by depth tabs.
+func (p *parser) parseBody(e *Exception, depth int) {
+	inMessage := true
+
+	for ; p.pos < len(p.lines); p.pos++ {
+		line := p.lines[p.pos]
+		indent := len(line) - len(strings.TrimLeft(line, "\t"))
+		text := strings.TrimSpace(line)
+
+		switch {
+		case text == "":
+			continue
+
+		case strings.HasPrefix(text, "at ") && indent > depth:
+			e.Frames = append(e.Frames, parseFrame(text[len("at "):]))
+			inMessage = false
+
+		case strings.HasPrefix(text, "... ") && strings.HasSuffix(text, " more") && indent > depth:
+			e.Omitted, _ = strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(text, "... "), " more"))
+			inMessage = false
+
+		case strings.HasPrefix(text, "Suppressed: ") && indent == depth+1:
+			suppressed, ok := parseHeader(strings.TrimPrefix(text, "Suppressed: "))
+			if !ok {
+				return
+			}
+			p.pos++
+			p.parseBody(suppressed, depth+1)
+			p.pos--
+			e.Suppressed = append(e.Suppressed, suppressed)
+			inMessage = false
+
+		case strings.HasPrefix(text, "Caused by: ") && indent == depth:
+			cause, ok := parseHeader(strings.TrimPrefix(text, "Caused by: "))
+			if !ok {
+				return
+			}
+			p.pos++
+			p.parseBody(cause, depth)
+			e.Cause = cause
+			return
+
+		case inMessage && indent <= depth:
+			// Messages may span several lines before the first frame
+			e.Message += "\n" + strings.TrimRight(line, " \t")
+
+		default:
+			return
+		}
+	}
+}
+
+// Parses "some.Exception: message" or just "some.Exception".
+func parseHeader(header string) (*Exception, bool) {
+	class, message := header, ""
+	if i := strings.Index(header, ": "); i >= 0 {
+		class, message = header[:i], header[i+2:]
+	} else if strings.HasSuffix(header, ":") {
+		class = header[:len(header)-1]
+	}
+
+	if class == "" || strings.ContainsAny(class, " \t()") {
+		return nil, false
+	}
+	return &Exception{Class: class, Message: message}, true
+}
+
+// Parses "org.Foo.bar(Foo.java:12)" and variations thereof, such as a trailing
+// "~[foo.jar:1.0]" added by Log4j.
+func parseFrame(text string) Frame {
+	open := strings.IndexByte(text, '(')
+	if open < 0 {
+		return Frame{Method: text}
+	}
+	frame := Frame{Method: text[:open]}
+
+	location := text[open+1:]
+	if close := strings.IndexByte(location, ')'); close >= 0 {
+		location = location[:close]
+	}
+	frame.File = location
+	if i := strings.LastIndexByte(location, ':'); i >= 0 {
+		if line, err := strconv.Atoi(location[i+1:]); err == nil {
+			frame.File, frame.Line = location[:i], line
+		}
+	}
+	return frame
+}
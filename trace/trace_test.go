@@ -0,0 +1,127 @@
+package trace_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/go-kafka/connect/trace"
+)
+
+const sinkFailure = `org.apache.kafka.connect.errors.ConnectException: Exiting WorkerSinkTask due to unrecoverable exception.
+	at org.apache.kafka.connect.runtime.WorkerSinkTask.deliverMessages(WorkerSinkTask.java:614)
+	at org.apache.kafka.connect.runtime.WorkerSinkTask.poll(WorkerSinkTask.java:329)
+	at java.base/java.lang.Thread.run(Thread.java:829)
+Caused by: org.apache.kafka.connect.errors.ConnectException: java.sql.SQLException: Exception chain:
+java.sql.BatchUpdateException: Batch entry 0 INSERT INTO "orders" was aborted
+org.postgresql.util.PSQLException: ERROR: relation "orders" does not exist
+
+	at io.confluent.connect.jdbc.sink.JdbcSinkTask.put(JdbcSinkTask.java:122)
+	at org.apache.kafka.connect.runtime.WorkerSinkTask.deliverMessages(WorkerSinkTask.java:586)
+	... 2 more
+	Suppressed: java.lang.IllegalStateException: rollback failed
+		at io.confluent.connect.jdbc.sink.JdbcDbWriter.write(JdbcDbWriter.java:90)
+		... 3 more
+	Caused by: java.io.IOException: socket closed
+		at sun.nio.ch.Net.poll(Native Method)
+Caused by: java.sql.SQLException: Exception chain
+	at io.confluent.connect.jdbc.sink.JdbcSinkTask.getAllMessagesException(JdbcSinkTask.java:150)
+	... 3 more
+`
+
+var _ = Describe("Parse", func() {
+	var e *Exception
+
+	BeforeEach(func() {
+		var err error
+		e, err = Parse(sinkFailure)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("parses the header and frames", func() {
+		Expect(e.Class).To(Equal("org.apache.kafka.connect.errors.ConnectException"))
+		Expect(e.Message).To(Equal("Exiting WorkerSinkTask due to unrecoverable exception."))
+		Expect(e.Frames).To(HaveLen(3))
+		Expect(e.Frames[0]).To(Equal(Frame{
+			Method: "org.apache.kafka.connect.runtime.WorkerSinkTask.deliverMessages",
+			File:   "WorkerSinkTask.java",
+			Line:   614,
+		}))
+		Expect(e.Frames[2].Method).To(Equal("java.base/java.lang.Thread.run"))
+	})
+
+	It("follows the cause chain", func() {
+		chain := e.Chain()
+		Expect(chain).To(HaveLen(3))
+		Expect(chain[1].Message).To(HavePrefix("java.sql.SQLException: Exception chain:\njava.sql.BatchUpdateException"))
+		Expect(chain[1].Message).To(HaveSuffix(`relation "orders" does not exist`))
+		Expect(chain[1].Frames).To(HaveLen(2))
+		Expect(chain[1].Omitted).To(Equal(2))
+		Expect(e.RootCause().Class).To(Equal("java.sql.SQLException"))
+	})
+
+	It("parses suppressed exceptions with their own causes", func() {
+		suppressed := e.Cause.Suppressed
+		Expect(suppressed).To(HaveLen(1))
+		Expect(suppressed[0].Class).To(Equal("java.lang.IllegalStateException"))
+		Expect(suppressed[0].Omitted).To(Equal(3))
+		Expect(suppressed[0].Cause.Class).To(Equal("java.io.IOException"))
+		Expect(suppressed[0].Cause.Frames[0]).To(Equal(Frame{Method: "sun.nio.ch.Net.poll", File: "Native Method"}))
+	})
+
+	It("summarizes the root cause", func() {
+		Expect(e.Summary()).To(Equal("java.sql.SQLException: Exception chain"))
+	})
+
+	It("rejects text that isn't a trace", func() {
+		_, err := Parse("something went wrong (really)")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Summarize", func() {
+	It("summarizes with the first line of a multi-line message", func() {
+		Expect(Summarize("java.lang.RuntimeException: boom\nmore detail\n\tat Foo.bar(Foo.java:1)")).
+			To(Equal("java.lang.RuntimeException: boom"))
+	})
+
+	It("falls back on the first line of text that isn't a trace", func() {
+		Expect(Summarize("Task is being killed (timeout)\nand more")).To(Equal("Task is being killed (timeout)"))
+	})
+})
+
+var _ = Describe("Normalize", func() {
+	It("masks hosts, IDs and numbers", func() {
+		Expect(Normalize("Connection to node 3 (10.0.0.12:9092) could not be established")).
+			To(Equal("Connection to node <n> (<host>) could not be established"))
+		Expect(Normalize("Failed to connect to jdbc:postgresql://db-7.prod:5432/orders")).
+			To(Equal("Failed to connect to jdbc:postgresql://<host>/orders"))
+		Expect(Normalize("broker kafka-1:9092 unreachable")).To(Equal("broker <host> unreachable"))
+		Expect(Normalize("request 5f0c7a1e-9c0b-4a6e-8b2f-1d2e3f4a5b6c for schema 0x1f2e failed")).
+			To(Equal("request <id> for schema <id> failed"))
+		Expect(Normalize("Offset 123456 out of range for orders-3, commit 9f8e7d6c5b")).
+			To(Equal("Offset <n> out of range for orders-<n>, commit <id>"))
+	})
+
+	It("leaves other text alone", func() {
+		Expect(Normalize(`ERROR: relation "orders" does not exist`)).To(Equal(`ERROR: relation "orders" does not exist`))
+	})
+})
+
+var _ = Describe("Signature", func() {
+	It("is the same for traces with the same root cause", func() {
+		a := "org.apache.kafka.connect.errors.ConnectException: task 0 failed\n\tat Foo.bar(Foo.java:1)\n" +
+			"Caused by: java.net.ConnectException: Connection to db-1:5432 refused\n\t... 1 more"
+		b := "org.apache.kafka.connect.errors.ConnectException: task 3 failed\n\tat Foo.bar(Foo.java:1)\n" +
+			"Caused by: java.net.ConnectException: Connection to db-2:5432 refused\n\t... 1 more"
+		Expect(Signature(a)).To(Equal("java.net.ConnectException: Connection to <host> refused"))
+		Expect(Signature(b)).To(Equal(Signature(a)))
+	})
+
+	It("doesn't mask the exception class", func() {
+		Expect(Signature("com.example.V2Exception\n\tat Foo.bar(Foo.java:1)")).To(Equal("com.example.V2Exception"))
+	})
+
+	It("normalizes text that isn't a trace", func() {
+		Expect(Signature("Task 4 is being killed")).To(Equal("Task <n> is being killed"))
+	})
+})
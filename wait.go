@@ -0,0 +1,53 @@
+package connect
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrTimeout is returned when waiting for a connector to reach some state takes
+// longer than allowed.
+var ErrTimeout = errors.New("timed out waiting for connector")
+
+// DefaultPollInterval is how often WaitForConnector checks status when no
+// interval is given.
+const DefaultPollInterval = 2 * time.Second
+
+// IsRunning reports whether the connector and all of its tasks are RUNNING.
+func (s *ConnectorStatus) IsRunning() bool {
+	if s.Connector.State != "RUNNING" {
+		return false
+	}
+	for _, task := range s.Tasks {
+		if task.State != "RUNNING" {
+			return false
+		}
+	}
+	return true
+}
+
+// WaitForConnector polls the status of a connector with the given name until
+// cond returns true for it, or timeout has elapsed, in which case the last
+// status seen is returned along with ErrTimeout. API errors while polling, such
+// as a 404 for a connector that has just been created, are retried until the
+// timeout. If interval is zero, DefaultPollInterval is used.
+func (c *Client) WaitForConnector(name string, cond func(*ConnectorStatus) bool, timeout, interval time.Duration) (*ConnectorStatus, error) {
+	if interval == 0 {
+		interval = DefaultPollInterval
+	}
+	deadline := time.Now().Add(timeout)
+
+	for {
+		status, _, err := c.GetConnectorStatus(name)
+		if err == nil && cond(status) {
+			return status, nil
+		}
+		if time.Now().Add(interval).After(deadline) {
+			if err != nil {
+				return status, err
+			}
+			return status, ErrTimeout
+		}
+		time.Sleep(interval)
+	}
+}
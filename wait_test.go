@@ -0,0 +1,57 @@
+package connect_test
+
+import (
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/go-kafka/connect"
+)
+
+var _ = Describe("WaitForConnector", func() {
+	running := func(s *ConnectorStatus) bool { return s.IsRunning() }
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		client = NewClient(server.URL())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("when the connector becomes healthy", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusNotFound, `{"error_code":404,"message":"not found"}`),
+				ghttp.RespondWith(http.StatusOK,
+					`{"name":"a","connector":{"state":"RUNNING"},"tasks":[{"id":0,"state":"UNASSIGNED"}]}`),
+				ghttp.RespondWith(http.StatusOK,
+					`{"name":"a","connector":{"state":"RUNNING"},"tasks":[{"id":0,"state":"RUNNING"}]}`),
+			)
+		})
+
+		It("returns the healthy status", func() {
+			status, err := client.WaitForConnector("a", running, time.Second, time.Millisecond)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.Tasks[0].State).To(Equal("RUNNING"))
+			Expect(server.ReceivedRequests()).To(HaveLen(3))
+		})
+	})
+
+	Context("when the connector stays unhealthy", func() {
+		BeforeEach(func() {
+			server.RouteToHandler("GET", "/connectors/a/status", ghttp.RespondWith(http.StatusOK,
+				`{"name":"a","connector":{"state":"FAILED"},"tasks":[]}`))
+		})
+
+		It("times out", func() {
+			status, err := client.WaitForConnector("a", running, 20*time.Millisecond, time.Millisecond)
+			Expect(err).To(Equal(ErrTimeout))
+			Expect(status.Connector.State).To(Equal("FAILED"))
+		})
+	})
+})
@@ -0,0 +1,55 @@
+package connect
+
+import "sort"
+
+// A WorkerLoad lists the connector instances and tasks assigned to a worker,
+// as seen in their statuses.
+type WorkerLoad struct {
+	WorkerID   string   `json:"worker_id"`
+	Connectors []string `json:"connectors"`
+	Tasks      []TaskID `json:"tasks"`
+}
+
+// WorkerLoads groups the connectors and tasks in statuses by the worker they
+// are assigned to, sorted by worker ID. Only workers with something assigned
+// appear, since the API has no other way to list workers. Unassigned tasks are
+// left out.
+func WorkerLoads(statuses []ConnectorStatus) []WorkerLoad {
+	byWorker := make(map[string]*WorkerLoad)
+	load := func(worker string) *WorkerLoad {
+		if byWorker[worker] == nil {
+			byWorker[worker] = &WorkerLoad{WorkerID: worker}
+		}
+		return byWorker[worker]
+	}
+
+	for _, status := range statuses {
+		if worker := status.Connector.WorkerID; worker != "" {
+			l := load(worker)
+			l.Connectors = append(l.Connectors, status.Name)
+		}
+		for _, task := range status.Tasks {
+			if task.WorkerID != "" {
+				l := load(task.WorkerID)
+				l.Tasks = append(l.Tasks, TaskID{ConnectorName: status.Name, ID: task.ID})
+			}
+		}
+	}
+
+	loads := make([]WorkerLoad, 0, len(byWorker))
+	for _, l := range byWorker {
+		loads = append(loads, *l)
+	}
+	sort.Slice(loads, func(i, j int) bool { return loads[i].WorkerID < loads[j].WorkerID })
+	return loads
+}
+
+// ListWorkerLoads fetches the status of all connectors and groups them by
+// worker, as WorkerLoads does.
+func (c *Client) ListWorkerLoads() ([]WorkerLoad, error) {
+	statuses, err := c.ListConnectorStatuses()
+	if err != nil {
+		return nil, err
+	}
+	return WorkerLoads(statuses), nil
+}
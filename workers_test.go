@@ -0,0 +1,40 @@
+package connect_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "github.com/go-kafka/connect"
+)
+
+var _ = Describe("WorkerLoads", func() {
+	It("groups connectors and tasks by worker", func() {
+		statuses := []ConnectorStatus{
+			{
+				Name:      "a",
+				Connector: ConnectorState{State: "RUNNING", WorkerID: "w2:8083"},
+				Tasks: []TaskState{
+					{ID: 0, State: "RUNNING", WorkerID: "w1:8083"},
+					{ID: 1, State: "UNASSIGNED"},
+				},
+			},
+			{
+				Name:      "b",
+				Connector: ConnectorState{State: "RUNNING", WorkerID: "w1:8083"},
+				Tasks:     []TaskState{{ID: 0, State: "RUNNING", WorkerID: "w1:8083"}},
+			},
+		}
+
+		Expect(WorkerLoads(statuses)).To(Equal([]WorkerLoad{
+			{
+				WorkerID:   "w1:8083",
+				Connectors: []string{"b"},
+				Tasks:      []TaskID{{ConnectorName: "a", ID: 0}, {ConnectorName: "b", ID: 0}},
+			},
+			{
+				WorkerID:   "w2:8083",
+				Connectors: []string{"a"},
+			},
+		}))
+	})
+})